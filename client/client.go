@@ -0,0 +1,232 @@
+// Package client is a Go client of the Employees API, over either its REST or its gRPC transport.
+package client
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	pb "github.com/dilyara4949/employees-api/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+)
+
+const (
+	correlationIDHeader  = "X-Correlation-ID"
+	idempotencyKeyHeader = "Idempotency-Key"
+	apiKeyHeader         = "X-API-Key"
+
+	defaultRetries = 3
+	defaultBackoff = 100 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+)
+
+// transport sends the calls of the client, the correlation ID and the idempotency key of a call are in its context.
+type transport interface {
+	getEmployee(ctx context.Context, id string) (*Employee, error)
+	listEmployees(ctx context.Context) ([]Employee, error)
+	createEmployee(ctx context.Context, employee Employee) (*Employee, error)
+	updateEmployee(ctx context.Context, employee Employee) (*Employee, error)
+	deleteEmployee(ctx context.Context, id string) error
+	searchEmployees(ctx context.Context, query string, limit int) ([]SearchResult, error)
+
+	getPosition(ctx context.Context, id string) (*Position, error)
+	listPositions(ctx context.Context) ([]Position, error)
+	createPosition(ctx context.Context, position Position) (*Position, error)
+	updatePosition(ctx context.Context, position Position) (*Position, error)
+	deletePosition(ctx context.Context, id string) error
+}
+
+// Client calls the Employees API. Failed calls are retried with exponential backoff if the server is
+// unavailable or rate limits the client. Creates are retried safely, they are sent with an idempotency key.
+type Client struct {
+	transport transport
+	retries   int
+	backoff   time.Duration
+	token     string
+	apiKey    string
+}
+
+type Option func(*Client)
+
+// WithToken authenticates the calls with the bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithAPIKey authenticates the calls with the API key instead of a token, for service-to-service callers.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithRetries sets how many times a failed call is retried and the delay before the first retry,
+// which doubles with every retry. Zero retries disables them.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// NewREST returns a client of the REST API at baseURL, e.g. "http://localhost:8080". httpClient may be nil
+// to use http.DefaultClient.
+func NewREST(baseURL string, httpClient *http.Client, opts ...Option) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	c := newClient(opts)
+	c.transport = &restTransport{baseURL: baseURL, client: httpClient, token: c.token, apiKey: c.apiKey}
+	return c
+}
+
+// NewGRPC returns a client of the gRPC API served on conn.
+func NewGRPC(conn grpc.ClientConnInterface, opts ...Option) *Client {
+	c := newClient(opts)
+	c.transport = &grpcTransport{
+		employees: pb.NewEmployeeServiceClient(conn),
+		positions: pb.NewPositionServiceClient(conn),
+		token:     c.token,
+		apiKey:    c.apiKey,
+	}
+	return c
+}
+
+func newClient(opts []Option) *Client {
+	c := &Client{retries: defaultRetries, backoff: defaultBackoff}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type correlationIDKey struct{}
+
+type idempotencyKeyKey struct{}
+
+// WithCorrelationID returns a context whose calls are sent with the correlation ID, so they can be followed
+// in the logs of the server. Calls without one get a new ID, shared by their retries.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of the context, or "" if it has none.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+func idempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+func (c *Client) GetEmployee(ctx context.Context, id string) (*Employee, error) {
+	return call(ctx, c, func(ctx context.Context) (*Employee, error) {
+		return c.transport.getEmployee(ctx, id)
+	})
+}
+
+func (c *Client) ListEmployees(ctx context.Context) ([]Employee, error) {
+	return call(ctx, c, c.transport.listEmployees)
+}
+
+func (c *Client) CreateEmployee(ctx context.Context, employee Employee) (*Employee, error) {
+	ctx = context.WithValue(ctx, idempotencyKeyKey{}, uuid.New().String())
+	return call(ctx, c, func(ctx context.Context) (*Employee, error) {
+		return c.transport.createEmployee(ctx, employee)
+	})
+}
+
+// UpdateEmployee replaces the employee with the ID of employee.
+func (c *Client) UpdateEmployee(ctx context.Context, employee Employee) (*Employee, error) {
+	return call(ctx, c, func(ctx context.Context) (*Employee, error) {
+		return c.transport.updateEmployee(ctx, employee)
+	})
+}
+
+func (c *Client) DeleteEmployee(ctx context.Context, id string) error {
+	_, err := call(ctx, c, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, c.transport.deleteEmployee(ctx, id)
+	})
+	return err
+}
+
+// SearchEmployees returns the employees matching the query by name, best match first. A limit of 0 uses
+// the default limit of the server.
+func (c *Client) SearchEmployees(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return call(ctx, c, func(ctx context.Context) ([]SearchResult, error) {
+		return c.transport.searchEmployees(ctx, query, limit)
+	})
+}
+
+func (c *Client) GetPosition(ctx context.Context, id string) (*Position, error) {
+	return call(ctx, c, func(ctx context.Context) (*Position, error) {
+		return c.transport.getPosition(ctx, id)
+	})
+}
+
+func (c *Client) ListPositions(ctx context.Context) ([]Position, error) {
+	return call(ctx, c, c.transport.listPositions)
+}
+
+func (c *Client) CreatePosition(ctx context.Context, position Position) (*Position, error) {
+	ctx = context.WithValue(ctx, idempotencyKeyKey{}, uuid.New().String())
+	return call(ctx, c, func(ctx context.Context) (*Position, error) {
+		return c.transport.createPosition(ctx, position)
+	})
+}
+
+// UpdatePosition replaces the position with the ID of position.
+func (c *Client) UpdatePosition(ctx context.Context, position Position) (*Position, error) {
+	return call(ctx, c, func(ctx context.Context) (*Position, error) {
+		return c.transport.updatePosition(ctx, position)
+	})
+}
+
+// DeletePosition deletes a position without employees, otherwise the error is ErrConflict.
+func (c *Client) DeletePosition(ctx context.Context, id string) error {
+	_, err := call(ctx, c, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, c.transport.deletePosition(ctx, id)
+	})
+	return err
+}
+
+// call sends the call and retries it while it fails with a retryable error.
+func call[T any](ctx context.Context, c *Client, fn func(ctx context.Context) (T, error)) (T, error) {
+	if CorrelationID(ctx) == "" {
+		ctx = WithCorrelationID(ctx, uuid.New().String())
+	}
+
+	for attempt := 0; ; attempt++ {
+		result, err := fn(ctx)
+		if err == nil || attempt >= c.retries || !retryable(err) || ctx.Err() != nil {
+			return result, err
+		}
+
+		timer := time.NewTimer(c.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns the backoff before the retry following attempt, with jitter so clients don't retry in lockstep.
+func (c *Client) delay(attempt int) time.Duration {
+	if c.backoff <= 0 {
+		return 0
+	}
+
+	backoff := c.backoff << attempt
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
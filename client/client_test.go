@@ -0,0 +1,356 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dilyara4949/employees-api/docs/openapi"
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/auth"
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	conf "github.com/dilyara4949/employees-api/internal/config"
+	"github.com/dilyara4949/employees-api/internal/controller"
+	"github.com/dilyara4949/employees-api/internal/events"
+	grpcserver "github.com/dilyara4949/employees-api/internal/grpc/server"
+	"github.com/dilyara4949/employees-api/internal/idempotency"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	"github.com/dilyara4949/employees-api/internal/route"
+	"github.com/dilyara4949/employees-api/internal/webhook"
+	pb "github.com/dilyara4949/employees-api/proto"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const secret = "secret"
+
+func setUpREST(t *testing.T) *Client {
+	t.Helper()
+
+	srv, _ := setUpRESTServer(t)
+	return NewREST(srv.URL, srv.Client(), WithToken(signedToken(t, jwt.MapClaims{"sub": "ann"})))
+}
+
+// setUpRESTServer serves the REST API, the returned store holds its API keys.
+func setUpRESTServer(t *testing.T) (*httptest.Server, *apikey.Store) {
+	t.Helper()
+
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+	webhooks := webhook.NewStore()
+	apiKeys := apikey.NewStore()
+
+	cache := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { cache.Close() })
+
+	config := conf.Config{
+		JWTTokenSecret: secret,
+		IdempotencyTTL: time.Hour,
+		RateLimit:      ratelimit.Policy{Default: ratelimit.Limit{Requests: 1000, Period: time.Minute}},
+		RedisConfig:    conf.RedisConfig{Ttl: time.Hour},
+	}
+
+	mux := http.NewServeMux()
+	route.SetUpRouter(
+		controller.NewEmployeesController(employees),
+		controller.NewPositionsController(positions, uow, nil),
+		controller.NewBulkController(bulk.NewImporter(employees, positions, uow), bulk.NewExporter(employees, positions), bulk.NewBatcher(uow)),
+		controller.NewEventsController(bus),
+		controller.NewWebhooksController(webhooks, webhook.NewDispatcher(webhooks, bus)),
+		controller.NewDocsController(openapi.Spec, openapi.SwaggerUI, openapi.SwaggerUIAssets()),
+		controller.NewAuthController(auth.NewIssuer(secret, config.Tokens, auth.NewMemoryUserStore(), auth.NewRefreshStore(cache), auth.NewRevocationList(cache))),
+		controller.NewAPIKeysController(apiKeys),
+		config, mux, middleware.NewJWTAuth(middleware.JWTConfig{Secret: secret}, auth.NewRevocationList(cache)), cache, ratelimit.NewMemoryLimiter(), nil,
+	)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, apiKeys
+}
+
+func setUpGRPC(t *testing.T) *Client {
+	t.Helper()
+
+	return NewGRPC(setUpGRPCServer(t), WithToken(signedToken(t, jwt.MapClaims{"sub": "ann"})))
+}
+
+// setUpGRPCServer serves the gRPC services, verifying bearer tokens like the server, and returns a connection to them.
+func setUpGRPCServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+	exporter := bulk.NewExporter(employees, positions)
+	batcher := bulk.NewBatcher(uow)
+	idempotencyStore := idempotency.NewMemoryStore()
+	jwtAuth := middleware.NewJWTAuth(middleware.JWTConfig{Secret: secret}, nil)
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcserver.CorrelationIDInterceptor(),
+			grpcserver.JWTInterceptor(jwtAuth),
+			grpcserver.IdempotencyInterceptor(idempotencyStore, time.Hour),
+		),
+	)
+	pb.RegisterEmployeeServiceServer(srv, grpcserver.NewEmployeeServer(employees, bulk.NewImporter(employees, positions, uow), exporter, batcher, bus))
+	pb.RegisterPositionServiceServer(srv, grpcserver.NewPositionServer(positions, uow, exporter, batcher, bus))
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func signedToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestClient(t *testing.T) {
+	transports := map[string]func(t *testing.T) *Client{
+		"rest": setUpREST,
+		"grpc": setUpGRPC,
+	}
+
+	for name, setUp := range transports {
+		t.Run(name, func(t *testing.T) {
+			c := setUp(t)
+			ctx := context.Background()
+
+			engineer, err := c.CreatePosition(ctx, Position{Name: "Engineer", Salary: Money{Amount: 15000, Currency: "USD"}})
+			if err != nil {
+				t.Fatalf("create position: %v", err)
+			}
+			if engineer.ID == "" || engineer.Name != "Engineer" {
+				t.Fatalf("created position %+v", engineer)
+			}
+
+			ann, err := c.CreateEmployee(ctx, Employee{FirstName: "Ann", LastName: "Lee", PositionID: engineer.ID})
+			if err != nil {
+				t.Fatalf("create employee: %v", err)
+			}
+
+			got, err := c.GetEmployee(ctx, ann.ID)
+			if err != nil {
+				t.Fatalf("get employee: %v", err)
+			}
+			if got.FirstName != "Ann" || got.PositionID != engineer.ID {
+				t.Errorf("got employee %+v", got)
+			}
+
+			got.LastName = "Park"
+			updated, err := c.UpdateEmployee(ctx, *got)
+			if err != nil {
+				t.Fatalf("update employee: %v", err)
+			}
+			if updated.LastName != "Park" {
+				t.Errorf("updated employee %+v", updated)
+			}
+
+			list, err := c.ListEmployees(ctx)
+			if err != nil || len(list) != 1 {
+				t.Errorf("list employees: %v, %+v", err, list)
+			}
+
+			results, err := c.SearchEmployees(ctx, "ann", 0)
+			if err != nil || len(results) != 1 || results[0].Employee.ID != ann.ID {
+				t.Errorf("search employees: %v, %+v", err, results)
+			}
+
+			if err := c.DeletePosition(ctx, engineer.ID); !errors.Is(err, ErrConflict) {
+				t.Errorf("delete position in use: expected ErrConflict, got %v", err)
+			}
+			if _, err := c.CreateEmployee(ctx, Employee{FirstName: "Bob", LastName: "Kim", PositionID: "missing"}); !errors.Is(err, ErrConflict) {
+				t.Errorf("create employee of missing position: expected ErrConflict, got %v", err)
+			}
+
+			if err := c.DeleteEmployee(ctx, ann.ID); err != nil {
+				t.Fatalf("delete employee: %v", err)
+			}
+			if _, err := c.GetEmployee(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("get missing employee: expected ErrNotFound, got %v", err)
+			}
+			if err := c.DeletePosition(ctx, engineer.ID); err != nil {
+				t.Errorf("delete position: %v", err)
+			}
+			if _, err := c.GetPosition(ctx, engineer.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("get deleted position: expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_APIKey(t *testing.T) {
+	srv, apiKeys := setUpRESTServer(t)
+	ctx := context.Background()
+
+	key, err := apiKeys.Create(&apikey.Key{Owner: "payroll", Scopes: []string{"employees:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewREST(srv.URL, srv.Client(), WithAPIKey(key), WithRetries(0, 0))
+
+	if _, err := c.ListEmployees(ctx); err != nil {
+		t.Errorf("expected the key to list employees, got %v", err)
+	}
+	if _, err := c.ListPositions(ctx); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden out of the scope of the key, got %v", err)
+	}
+
+	unknown := NewREST(srv.URL, srv.Client(), WithAPIKey("eak_unknown_secret"), WithRetries(0, 0))
+	if _, err := unknown.ListEmployees(ctx); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestClient_GRPCToken(t *testing.T) {
+	conn := setUpGRPCServer(t)
+	ctx := context.Background()
+
+	engineer, err := NewGRPC(conn, WithToken(signedToken(t, jwt.MapClaims{"sub": "ann"}))).CreatePosition(ctx, Position{Name: "Engineer", Salary: Money{Amount: 15000, Currency: "USD"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	override := Employee{FirstName: "Ann", LastName: "Lee", PositionID: engineer.ID, Salary: &Money{Amount: 90000, Currency: "USD"}, SalaryOverride: true}
+
+	tests := map[string]struct {
+		token    string
+		expected error
+	}{
+		"compensation admin": {
+			token: signedToken(t, jwt.MapClaims{"sub": "ann", "role": "compensation_admin"}),
+		},
+		"without role": {
+			token:    signedToken(t, jwt.MapClaims{"sub": "bob"}),
+			expected: ErrForbidden,
+		},
+		"invalid token": {
+			token:    signedToken(t, jwt.MapClaims{"sub": "ann", "role": "compensation_admin"}) + "x",
+			expected: ErrUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := NewGRPC(conn, WithToken(tc.token), WithRetries(0, 0))
+
+			created, err := c.CreateEmployee(ctx, override)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, err)
+			}
+			if err == nil && !created.SalaryOverride {
+				t.Errorf("expected the salary override, got %+v", created)
+			}
+		})
+	}
+}
+
+func TestClient_GRPCErrorDetails(t *testing.T) {
+	c := setUpGRPC(t)
+	ctx := WithCorrelationID(context.Background(), "request-1")
+
+	_, err := c.CreatePosition(ctx, Position{Name: "Manager", Salary: Money{Amount: 100, Currency: "XXX"}})
+
+	var serverErr *Error
+	if !errors.As(err, &serverErr) || serverErr.Kind != ErrInvalid {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+	if serverErr.CorrelationID != "request-1" {
+		t.Errorf("expected correlation id request-1, got %q", serverErr.CorrelationID)
+	}
+	if len(serverErr.Violations) != 1 || serverErr.Violations[0].Field != "salary" {
+		t.Errorf("expected a violation of salary, got %+v", serverErr.Violations)
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := map[string]struct {
+		statuses      []int
+		retries       int
+		expectedCalls int32
+		expectedErr   error
+	}{
+		"retried until success": {
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			retries:       3,
+			expectedCalls: 3,
+		},
+		"retries exhausted": {
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			retries:       2,
+			expectedCalls: 3,
+			expectedErr:   ErrUnavailable,
+		},
+		"not retryable": {
+			statuses:      []int{http.StatusNotFound, http.StatusOK},
+			retries:       3,
+			expectedCalls: 1,
+			expectedErr:   ErrNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int32
+			correlationIDs := make(map[string]bool)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				correlationIDs[r.Header.Get(correlationIDHeader)] = true
+				status := tc.statuses[calls.Add(1)-1]
+				if status != http.StatusOK {
+					http.Error(w, http.StatusText(status), status)
+					return
+				}
+				w.Write([]byte(`{"id":"1","name":"Engineer","salary":{"amount":15000,"currency":"USD"},"band":null}`))
+			}))
+			defer srv.Close()
+
+			c := NewREST(srv.URL, srv.Client(), WithRetries(tc.retries, time.Millisecond))
+			_, err := c.GetPosition(context.Background(), "1")
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if calls.Load() != tc.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tc.expectedCalls, calls.Load())
+			}
+			if len(correlationIDs) != 1 || correlationIDs[""] {
+				t.Errorf("expected the retries to share a correlation id, got %v", correlationIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The kinds of errors returned by the server, test for them with errors.Is.
+var (
+	ErrInvalid      = errors.New("invalid request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	// ErrConflict is returned when the state of the server doesn't allow the request, like deleting a position
+	// with employees or creating an employee of a position that doesn't exist.
+	ErrConflict    = errors.New("conflict")
+	ErrRateLimited = errors.New("rate limited")
+	ErrUnavailable = errors.New("unavailable")
+	ErrServer      = errors.New("server error")
+)
+
+// Error is an error returned by the server.
+type Error struct {
+	// Kind is one of the Err variables of the package.
+	Kind    error
+	Message string
+	// CorrelationID identifies the request in the logs of the server.
+	CorrelationID string
+	// Violations lists the invalid fields of the request, only the gRPC transport reports them.
+	Violations []FieldViolation
+}
+
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Kind.Error()
+	}
+	return e.Kind.Error() + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// retryable reports whether the request may succeed if it is sent again. Errors not returned by the server,
+// like failed connections, are retryable unless the context is done.
+func retryable(err error) bool {
+	var serverErr *Error
+	if !errors.As(err, &serverErr) {
+		return true
+	}
+	return serverErr.Kind == ErrUnavailable || serverErr.Kind == ErrRateLimited
+}
+
+// httpError converts an error response of the REST API, its body is the error message.
+func httpError(resp *http.Response, body []byte) *Error {
+	err := &Error{
+		Kind:          ErrServer,
+		Message:       strings.TrimSpace(string(body)),
+		CorrelationID: resp.Header.Get(correlationIDHeader),
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		err.Kind = ErrInvalid
+	case http.StatusUnauthorized:
+		err.Kind = ErrUnauthorized
+	case http.StatusForbidden:
+		err.Kind = ErrForbidden
+	case http.StatusNotFound:
+		err.Kind = ErrNotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		err.Kind = ErrConflict
+	case http.StatusTooManyRequests:
+		err.Kind = ErrRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		err.Kind = ErrUnavailable
+	}
+	return err
+}
+
+// grpcError converts an error of a gRPC call, errors without a status are returned as they are.
+func grpcError(callErr error) error {
+	st, ok := status.FromError(callErr)
+	if !ok {
+		return callErr
+	}
+
+	err := &Error{Kind: ErrServer, Message: st.Message()}
+	switch st.Code() {
+	case codes.Canceled, codes.DeadlineExceeded:
+		return callErr
+	case codes.InvalidArgument, codes.OutOfRange:
+		err.Kind = ErrInvalid
+	case codes.Unauthenticated:
+		err.Kind = ErrUnauthorized
+	case codes.PermissionDenied:
+		err.Kind = ErrForbidden
+	case codes.NotFound:
+		err.Kind = ErrNotFound
+	case codes.FailedPrecondition, codes.AlreadyExists, codes.Aborted:
+		err.Kind = ErrConflict
+	case codes.ResourceExhausted:
+		err.Kind = ErrRateLimited
+	case codes.Unavailable:
+		err.Kind = ErrUnavailable
+	}
+
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.RequestInfo:
+			err.CorrelationID = detail.GetRequestId()
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				err.Violations = append(err.Violations, FieldViolation{Field: violation.GetField(), Description: violation.GetDescription()})
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/dilyara4949/employees-api/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcTransport calls the gRPC services.
+type grpcTransport struct {
+	employees pb.EmployeeServiceClient
+	positions pb.PositionServiceClient
+	token     string
+	apiKey    string
+}
+
+func (t *grpcTransport) getEmployee(ctx context.Context, id string) (*Employee, error) {
+	employee, err := t.employees.Get(t.outgoing(ctx), &pb.Id{Value: id})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return employeeFromProto(employee), nil
+}
+
+func (t *grpcTransport) listEmployees(ctx context.Context) ([]Employee, error) {
+	list, err := t.employees.GetAll(t.outgoing(ctx), &pb.Empty{})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	employees := make([]Employee, len(list.GetEmployee()))
+	for i, employee := range list.GetEmployee() {
+		employees[i] = *employeeFromProto(employee)
+	}
+	return employees, nil
+}
+
+func (t *grpcTransport) createEmployee(ctx context.Context, employee Employee) (*Employee, error) {
+	created, err := t.employees.Create(t.outgoing(ctx), employeeToProto(employee))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return employeeFromProto(created), nil
+}
+
+func (t *grpcTransport) updateEmployee(ctx context.Context, employee Employee) (*Employee, error) {
+	updated, err := t.employees.Update(t.outgoing(ctx), employeeToProto(employee))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return employeeFromProto(updated), nil
+}
+
+func (t *grpcTransport) deleteEmployee(ctx context.Context, id string) error {
+	if _, err := t.employees.Delete(t.outgoing(ctx), &pb.Id{Value: id}); err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+func (t *grpcTransport) searchEmployees(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	resp, err := t.employees.Search(t.outgoing(ctx), &pb.SearchRequest{Query: query, Limit: int32(limit)})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	results := make([]SearchResult, len(resp.GetResults()))
+	for i, result := range resp.GetResults() {
+		results[i] = SearchResult{Employee: *employeeFromProto(result.GetEmployee()), Score: result.GetScore()}
+	}
+	return results, nil
+}
+
+func (t *grpcTransport) getPosition(ctx context.Context, id string) (*Position, error) {
+	position, err := t.positions.Get(t.outgoing(ctx), &pb.Id{Value: id})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return positionFromProto(position), nil
+}
+
+func (t *grpcTransport) listPositions(ctx context.Context) ([]Position, error) {
+	list, err := t.positions.GetAll(t.outgoing(ctx), &pb.Empty{})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	positions := make([]Position, len(list.GetPosition()))
+	for i, position := range list.GetPosition() {
+		positions[i] = *positionFromProto(position)
+	}
+	return positions, nil
+}
+
+func (t *grpcTransport) createPosition(ctx context.Context, position Position) (*Position, error) {
+	created, err := t.positions.Create(t.outgoing(ctx), positionToProto(position))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return positionFromProto(created), nil
+}
+
+func (t *grpcTransport) updatePosition(ctx context.Context, position Position) (*Position, error) {
+	updated, err := t.positions.Update(t.outgoing(ctx), positionToProto(position))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return positionFromProto(updated), nil
+}
+
+func (t *grpcTransport) deletePosition(ctx context.Context, id string) error {
+	if _, err := t.positions.Delete(t.outgoing(ctx), &pb.Id{Value: id}); err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+// outgoing adds the token or API key, the correlation ID and the idempotency key of the call to the outgoing metadata.
+func (t *grpcTransport) outgoing(ctx context.Context) context.Context {
+	pairs := make([]string, 0, 8)
+	if t.token != "" {
+		pairs = append(pairs, "authorization", "Bearer "+t.token)
+	}
+	if t.apiKey != "" {
+		pairs = append(pairs, strings.ToLower(apiKeyHeader), t.apiKey)
+	}
+	if id := CorrelationID(ctx); id != "" {
+		pairs = append(pairs, strings.ToLower(correlationIDHeader), id)
+	}
+	if key := idempotencyKey(ctx); key != "" {
+		pairs = append(pairs, strings.ToLower(idempotencyKeyHeader), key)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func employeeFromProto(e *pb.Employee) *Employee {
+	employee := &Employee{
+		ID:             e.GetId(),
+		FirstName:      e.GetFirstname(),
+		LastName:       e.GetLastname(),
+		PositionID:     e.GetPositionId(),
+		SalaryOverride: e.GetSalaryOverride(),
+		CompaRatio:     e.GetCompaRatio(),
+	}
+	if e.GetSalary() != nil {
+		salary := moneyFromProto(e.GetSalary())
+		employee.Salary = &salary
+	}
+	return employee
+}
+
+func employeeToProto(e Employee) *pb.Employee {
+	employee := &pb.Employee{
+		Id:             e.ID,
+		Firstname:      e.FirstName,
+		Lastname:       e.LastName,
+		PositionId:     e.PositionID,
+		SalaryOverride: e.SalaryOverride,
+	}
+	if e.Salary != nil {
+		employee.Salary = moneyToProto(*e.Salary)
+	}
+	return employee
+}
+
+func positionFromProto(p *pb.Position) *Position {
+	position := &Position{ID: p.GetId(), Name: p.GetName(), Salary: moneyFromProto(p.GetSalary())}
+	if band := p.GetBand(); band != nil {
+		position.Band = &SalaryBand{Min: moneyFromProto(band.GetMin()), Mid: moneyFromProto(band.GetMid()), Max: moneyFromProto(band.GetMax())}
+	}
+	return position
+}
+
+func positionToProto(p Position) *pb.Position {
+	position := &pb.Position{Id: p.ID, Name: p.Name, Salary: moneyToProto(p.Salary)}
+	if p.Band != nil {
+		position.Band = &pb.SalaryBand{Min: moneyToProto(p.Band.Min), Mid: moneyToProto(p.Band.Mid), Max: moneyToProto(p.Band.Max)}
+	}
+	return position
+}
+
+func moneyFromProto(m *pb.Money) Money {
+	return Money{Amount: m.GetAmount(), Currency: m.GetCurrency()}
+}
+
+func moneyToProto(m Money) *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// restTransport calls the v2 REST API.
+type restTransport struct {
+	baseURL string
+	client  *http.Client
+	token   string
+	apiKey  string
+}
+
+func (t *restTransport) getEmployee(ctx context.Context, id string) (*Employee, error) {
+	var employee Employee
+	if err := t.do(ctx, http.MethodGet, "/v2/employees/"+url.PathEscape(id), nil, &employee); err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+func (t *restTransport) listEmployees(ctx context.Context) ([]Employee, error) {
+	var employees []Employee
+	if err := t.do(ctx, http.MethodGet, "/v2/employees", nil, &employees); err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+func (t *restTransport) createEmployee(ctx context.Context, employee Employee) (*Employee, error) {
+	var created Employee
+	if err := t.do(ctx, http.MethodPost, "/v2/employees", employee, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (t *restTransport) updateEmployee(ctx context.Context, employee Employee) (*Employee, error) {
+	var updated Employee
+	if err := t.do(ctx, http.MethodPut, "/v2/employees/"+url.PathEscape(employee.ID), employee, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (t *restTransport) deleteEmployee(ctx context.Context, id string) error {
+	return t.do(ctx, http.MethodDelete, "/v2/employees/"+url.PathEscape(id), nil, nil)
+}
+
+func (t *restTransport) searchEmployees(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	params := url.Values{"q": {query}}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	var results []SearchResult
+	if err := t.do(ctx, http.MethodGet, "/v2/employees/search?"+params.Encode(), nil, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (t *restTransport) getPosition(ctx context.Context, id string) (*Position, error) {
+	var position Position
+	if err := t.do(ctx, http.MethodGet, "/v2/positions/"+url.PathEscape(id), nil, &position); err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+func (t *restTransport) listPositions(ctx context.Context) ([]Position, error) {
+	var positions []Position
+	if err := t.do(ctx, http.MethodGet, "/v2/positions", nil, &positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+func (t *restTransport) createPosition(ctx context.Context, position Position) (*Position, error) {
+	var created Position
+	if err := t.do(ctx, http.MethodPost, "/v2/positions", position, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (t *restTransport) updatePosition(ctx context.Context, position Position) (*Position, error) {
+	var updated Position
+	if err := t.do(ctx, http.MethodPut, "/v2/positions/"+url.PathEscape(position.ID), position, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (t *restTransport) deletePosition(ctx context.Context, id string) error {
+	return t.do(ctx, http.MethodDelete, "/v2/positions/"+url.PathEscape(id), nil, nil)
+}
+
+// do sends the request with body encoded as JSON and decodes the response into result, if it isn't nil.
+func (t *restTransport) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("error to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	if t.apiKey != "" {
+		req.Header.Set(apiKeyHeader, t.apiKey)
+	}
+	if id := CorrelationID(ctx); id != "" {
+		req.Header.Set(correlationIDHeader, id)
+	}
+	if key := idempotencyKey(ctx); key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error to read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return httpError(resp, data)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("error to unmarshal response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package client
+
+// Money is an amount in the minor unit of the currency, e.g. cents for USD.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// SalaryBand is the salary range of a position.
+type SalaryBand struct {
+	Min Money `json:"min"`
+	Mid Money `json:"mid"`
+	Max Money `json:"max"`
+}
+
+type Employee struct {
+	ID         string `json:"id,omitempty"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	PositionID string `json:"position_id"`
+	// Salary is the salary of the employee, or nil for the salary of the position.
+	Salary *Money `json:"salary"`
+	// SalaryOverride allows a salary outside of the position band, it requires the compensation_admin role.
+	SalaryOverride bool `json:"salary_override"`
+	// CompaRatio is the salary relative to the midpoint of the position band, it is ignored on writes.
+	CompaRatio float64 `json:"compa_ratio,omitempty"`
+}
+
+type Position struct {
+	ID     string      `json:"id,omitempty"`
+	Name   string      `json:"name"`
+	Salary Money       `json:"salary"`
+	Band   *SalaryBand `json:"band"`
+}
+
+type SearchResult struct {
+	Employee Employee `json:"employee"`
+	Score    float64  `json:"score"`
+}
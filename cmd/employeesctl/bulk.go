@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	pb "github.com/dilyara4949/employees-api/proto"
+)
+
+// importResult is the outcome of an import, with the rows numbered as in the imported file.
+type importResult struct {
+	Total    int             `json:"total"`
+	Imported int             `json:"imported"`
+	DryRun   bool            `json:"dry_run"`
+	IDs      []string        `json:"ids"`
+	Errors   []bulk.RowError `json:"errors"`
+}
+
+// importEmployees streams the employees of a CSV or NDJSON file, "-" reads the standard input.
+func (a *app) importEmployees(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("employees import", flag.ContinueOnError)
+	format := flags.String("format", "", "format of the file: csv or ndjson, by default from the file extension")
+	dryRun := flags.Bool("dry-run", false, "validate the employees without importing them")
+	bestEffort := flags.Bool("best-effort", false, "import the valid employees even if some are invalid")
+	args, err := subcommand(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	rows, rowErrors, err := decodeEmployees(args[0], *format)
+	if err != nil {
+		return err
+	}
+	if len(rowErrors) > 0 && !*bestEffort {
+		return a.printImport(importResult{Total: len(rows) + len(rowErrors), DryRun: *dryRun, Errors: rowErrors})
+	}
+
+	stream, err := a.employees.ImportEmployees(a.outgoing(ctx))
+	if err != nil {
+		return err
+	}
+
+	options := &pb.ImportOptions{DryRun: *dryRun, BestEffort: *bestEffort}
+	if err := stream.Send(&pb.ImportEmployeesRequest{Payload: &pb.ImportEmployeesRequest_Options{Options: options}}); err != nil {
+		return fmt.Errorf("error to send import options: %w", err)
+	}
+	for _, row := range rows {
+		req := &pb.ImportEmployeesRequest{Payload: &pb.ImportEmployeesRequest_Row{Row: employeeRowToProto(row)}}
+		if err := stream.Send(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				// the server failed the call, its error is returned by CloseAndRecv
+				break
+			}
+			return fmt.Errorf("error to send row %d: %w", row.Row, err)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	result := importResult{
+		Total:    len(rows) + len(rowErrors),
+		Imported: int(resp.GetImported()),
+		DryRun:   resp.GetDryRun(),
+		IDs:      resp.GetIds(),
+		Errors:   rowErrors,
+	}
+	// the server numbers the rows in the order they were sent
+	for _, rowErr := range resp.GetErrors() {
+		row := int(rowErr.GetRow())
+		if row >= 1 && row <= len(rows) {
+			row = rows[row-1].Row
+		}
+		result.Errors = append(result.Errors, bulk.RowError{Row: row, Error: rowErr.GetError()})
+	}
+	return a.printImport(result)
+}
+
+func (a *app) printImport(result importResult) error {
+	t := table{header: []string{"TOTAL", "IMPORTED", "DRY RUN", "ERRORS"}}
+	t.rows = append(t.rows, []string{strconv.Itoa(result.Total), strconv.Itoa(result.Imported), strconv.FormatBool(result.DryRun), strconv.Itoa(len(result.Errors))})
+	if err := a.print(result, t); err != nil {
+		return err
+	}
+
+	if a.format == formatTable && len(result.Errors) > 0 {
+		errs := table{header: []string{"ROW", "ERROR"}}
+		for _, rowErr := range result.Errors {
+			errs.rows = append(errs.rows, []string{strconv.Itoa(rowErr.Row), rowErr.Error})
+		}
+		fmt.Fprintln(a.out)
+		return a.print(result.Errors, errs)
+	}
+	return nil
+}
+
+func decodeEmployees(path, format string) ([]bulk.EmployeeRow, []bulk.RowError, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	parsed, err := bulk.ParseFormat(format)
+	if err != nil || parsed == bulk.FormatXLSX {
+		return nil, nil, fmt.Errorf("%w: employees import: unsupported format %q, use -format csv or ndjson", errUsage, format)
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	rows, rowErrors, err := bulk.DecodeEmployees(r, parsed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error to read %s: %w", path, err)
+	}
+	return rows, rowErrors, nil
+}
+
+func employeeRowToProto(row bulk.EmployeeRow) *pb.ImportEmployeeRow {
+	employee := &pb.Employee{
+		Firstname:      row.Employee.FirstName,
+		Lastname:       row.Employee.LastName,
+		PositionId:     row.Employee.PositionID,
+		SalaryOverride: row.Employee.SalaryOverride,
+	}
+	if salary := row.Employee.Salary; salary != nil {
+		employee.Salary = &pb.Money{Amount: salary.Amount, Currency: salary.Currency}
+	}
+	return &pb.ImportEmployeeRow{Employee: employee, PositionName: row.PositionName}
+}
+
+func (a *app) exportEmployees(ctx context.Context, args []string) error {
+	req, file, err := parseExport("employees export", args)
+	if err != nil {
+		return err
+	}
+
+	stream, err := a.employees.Export(a.outgoing(ctx), req)
+	if err != nil {
+		return err
+	}
+	return a.writeExport(stream, file)
+}
+
+func (a *app) exportPositions(ctx context.Context, args []string) error {
+	req, file, err := parseExport("positions export", args)
+	if err != nil {
+		return err
+	}
+
+	stream, err := a.positions.Export(a.outgoing(ctx), req)
+	if err != nil {
+		return err
+	}
+	return a.writeExport(stream, file)
+}
+
+func parseExport(name string, args []string) (*pb.ExportRequest, string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	format := flags.String("format", string(bulk.FormatCSV), "format of the export: csv, ndjson or xlsx")
+	columns := flags.String("columns", "", "comma separated columns of the export, all by default")
+	file := flags.String("file", "", "write the export to the file instead of the standard output")
+	if _, err := subcommand(flags, args, 0, 0); err != nil {
+		return nil, "", err
+	}
+
+	req := &pb.ExportRequest{Format: *format}
+	if *columns != "" {
+		req.Columns = strings.Split(*columns, ",")
+	}
+	return req, *file, nil
+}
+
+// chunkStream is the stream of an export call.
+type chunkStream interface {
+	Recv() (*pb.ExportChunk, error)
+}
+
+// writeExport copies the export to the file, or to the output of the command if file is empty.
+// The export is written as it is, whatever the output format.
+func (a *app) writeExport(stream chunkStream, file string) error {
+	out := a.out
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(chunk.GetData()); err != nil {
+			return fmt.Errorf("error to write export: %w", err)
+		}
+	}
+
+	if f, ok := out.(*os.File); ok && file != "" {
+		return f.Close()
+	}
+	return nil
+}
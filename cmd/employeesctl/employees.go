@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/client"
+)
+
+func (a *app) runEmployees(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: employees: missing subcommand", errUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		return a.listEmployees(ctx, args[1:])
+	case "get":
+		return a.getEmployee(ctx, args[1:])
+	case "create":
+		return a.createEmployee(ctx, args[1:])
+	case "update":
+		return a.updateEmployee(ctx, args[1:])
+	case "delete":
+		return a.deleteEmployees(ctx, args[1:])
+	case "import":
+		return a.importEmployees(ctx, args[1:])
+	case "export":
+		return a.exportEmployees(ctx, args[1:])
+	}
+	return fmt.Errorf("%w: employees: unknown subcommand %q", errUsage, args[0])
+}
+
+// employeeFilter selects the listed employees, zero fields match every employee.
+type employeeFilter struct {
+	query      string
+	positionID string
+	name       string
+	minSalary  int64
+	maxSalary  int64
+	currency   string
+	limit      int
+}
+
+func (f employeeFilter) match(e client.Employee) bool {
+	if f.positionID != "" && e.PositionID != f.positionID {
+		return false
+	}
+	if f.name != "" && !strings.Contains(strings.ToLower(e.FirstName+" "+e.LastName), strings.ToLower(f.name)) {
+		return false
+	}
+
+	if f.minSalary == 0 && f.maxSalary == 0 && f.currency == "" {
+		return true
+	}
+	if e.Salary == nil {
+		return false
+	}
+	if f.currency != "" && e.Salary.Currency != f.currency {
+		return false
+	}
+	if e.Salary.Amount < f.minSalary {
+		return false
+	}
+	return f.maxSalary == 0 || e.Salary.Amount <= f.maxSalary
+}
+
+func (a *app) listEmployees(ctx context.Context, args []string) error {
+	var filter employeeFilter
+	flags := flag.NewFlagSet("employees list", flag.ContinueOnError)
+	flags.StringVar(&filter.query, "q", "", "search the employees by name, best match first")
+	flags.StringVar(&filter.positionID, "position", "", "only employees of the position ID")
+	flags.StringVar(&filter.name, "name", "", "only employees whose name contains the text")
+	flags.Int64Var(&filter.minSalary, "min-salary", 0, "only employees earning at least the amount, in minor units")
+	flags.Int64Var(&filter.maxSalary, "max-salary", 0, "only employees earning at most the amount, in minor units")
+	flags.StringVar(&filter.currency, "currency", "", "only employees paid in the currency")
+	flags.IntVar(&filter.limit, "limit", 0, "list at most this many employees")
+	if _, err := subcommand(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	var (
+		employees []client.Employee
+		err       error
+	)
+	if filter.query != "" {
+		var results []client.SearchResult
+		results, err = a.client.SearchEmployees(ctx, filter.query, 0)
+		for _, result := range results {
+			employees = append(employees, result.Employee)
+		}
+	} else {
+		employees, err = a.client.ListEmployees(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	matched := make([]client.Employee, 0, len(employees))
+	for _, employee := range employees {
+		if filter.limit > 0 && len(matched) == filter.limit {
+			break
+		}
+		if filter.match(employee) {
+			matched = append(matched, employee)
+		}
+	}
+	return a.print(matched, employeesTable(matched))
+}
+
+func (a *app) getEmployee(ctx context.Context, args []string) error {
+	args, err := subcommand(flag.NewFlagSet("employees get", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	employee, err := a.client.GetEmployee(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.print(employee, employeesTable([]client.Employee{*employee}))
+}
+
+// employeeFlags defines the flags setting the fields of an employee.
+func employeeFlags(name string, employee *client.Employee, salary *client.Money) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(&employee.FirstName, "first-name", employee.FirstName, "first name")
+	flags.StringVar(&employee.LastName, "last-name", employee.LastName, "last name")
+	flags.StringVar(&employee.PositionID, "position", employee.PositionID, "position ID")
+	flags.Int64Var(&salary.Amount, "salary", salary.Amount, "salary in minor units, the salary of the position by default")
+	flags.StringVar(&salary.Currency, "currency", salary.Currency, "currency of the salary")
+	flags.BoolVar(&employee.SalaryOverride, "salary-override", employee.SalaryOverride, "allow a salary outside of the position band")
+	return flags
+}
+
+func (a *app) createEmployee(ctx context.Context, args []string) error {
+	var (
+		employee client.Employee
+		salary   client.Money
+	)
+	flags := employeeFlags("employees create", &employee, &salary)
+	if _, err := subcommand(flags, args, 0, 0); err != nil {
+		return err
+	}
+	if isSet(flags, "salary") || isSet(flags, "currency") {
+		employee.Salary = &salary
+	}
+
+	created, err := a.client.CreateEmployee(ctx, employee)
+	if err != nil {
+		return err
+	}
+	return a.print(created, employeesTable([]client.Employee{*created}))
+}
+
+// updateEmployee changes the fields of the employee given as flags, the other fields are kept.
+func (a *app) updateEmployee(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("%w: employees update: missing employee ID", errUsage)
+	}
+
+	employee, err := a.client.GetEmployee(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	var salary client.Money
+	if employee.Salary != nil {
+		salary = *employee.Salary
+	}
+	flags := employeeFlags("employees update", employee, &salary)
+	if _, err := subcommand(flags, args[1:], 0, 0); err != nil {
+		return err
+	}
+	if isSet(flags, "salary") || isSet(flags, "currency") {
+		employee.Salary = &salary
+	}
+
+	updated, err := a.client.UpdateEmployee(ctx, *employee)
+	if err != nil {
+		return err
+	}
+	return a.print(updated, employeesTable([]client.Employee{*updated}))
+}
+
+func (a *app) deleteEmployees(ctx context.Context, args []string) error {
+	ids, err := subcommand(flag.NewFlagSet("employees delete", flag.ContinueOnError), args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := a.client.DeleteEmployee(ctx, id); err != nil {
+			return fmt.Errorf("error to delete employee %s: %w", id, err)
+		}
+		if a.format == formatTable {
+			fmt.Fprintf(a.out, "employee %s deleted\n", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// Command employeesctl manages the employees and positions of the Employees API over gRPC.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dilyara4949/employees-api/client"
+	pb "github.com/dilyara4949/employees-api/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const usage = `usage: employeesctl [flags] <command> [arguments]
+
+commands:
+  employees list|get|create|update|delete|import|export
+  positions list|get|create|update|delete|export
+  token     issue a development JWT signed with JWT_TOKEN_SECRET
+
+flags:
+`
+
+// errUsage is returned for invalid command lines.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	flags := flag.NewFlagSet("employeesctl", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+
+	addr := flags.String("addr", envOr("EMPLOYEESCTL_ADDR", "localhost:50051"), "gRPC address of the server, $EMPLOYEESCTL_ADDR")
+	token := flags.String("token", os.Getenv("EMPLOYEESCTL_TOKEN"), "bearer token of the calls, $EMPLOYEESCTL_TOKEN")
+	output := flags.String("o", string(formatTable), "output format: table, json or yaml")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of the command")
+
+	if err := flags.Parse(os.Args[1:]); errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
+		os.Exit(2)
+	}
+
+	format, err := parseFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "employeesctl:", err)
+		os.Exit(2)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "employeesctl:", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	err = newApp(conn, *token, os.Stdout, format).run(ctx, flags.Args())
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage) && len(flags.Args()) == 0:
+		flags.Usage()
+		os.Exit(2)
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "employeesctl: %v\nrun 'employeesctl -h' for usage\n", err)
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "employeesctl:", err)
+		os.Exit(1)
+	}
+}
+
+// app runs the commands. CRUD calls go through the client package, bulk calls stream with the generated stubs.
+type app struct {
+	client    *client.Client
+	employees pb.EmployeeServiceClient
+	positions pb.PositionServiceClient
+	token     string
+	out       io.Writer
+	format    outputFormat
+}
+
+func newApp(conn grpc.ClientConnInterface, token string, out io.Writer, format outputFormat) *app {
+	return &app{
+		client:    client.NewGRPC(conn, client.WithToken(token)),
+		employees: pb.NewEmployeeServiceClient(conn),
+		positions: pb.NewPositionServiceClient(conn),
+		token:     token,
+		out:       out,
+		format:    format,
+	}
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "employees":
+		return a.runEmployees(ctx, args[1:])
+	case "positions":
+		return a.runPositions(ctx, args[1:])
+	case "token":
+		return a.issueToken(args[1:])
+	}
+	return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+}
+
+// outgoing authenticates the streaming calls, which are sent with the stubs rather than the client.
+func (a *app) outgoing(ctx context.Context) context.Context {
+	if a.token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+a.token)
+}
+
+// subcommand parses the flags of a subcommand and returns its positional arguments, of which there must be
+// between min and max. Invalid flags and -h print the flags of the subcommand.
+func subcommand(flags *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s: %v", errUsage, flags.Name(), err)
+	}
+
+	if flags.NArg() < min || (max >= 0 && flags.NArg() > max) {
+		return nil, fmt.Errorf("%w: %s: wrong number of arguments", errUsage, flags.Name())
+	}
+	return flags.Args(), nil
+}
+
+// isSet reports whether the flag was given on the command line.
+func isSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/client"
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/grpc/server"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	pb "github.com/dilyara4949/employees-api/proto"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setUpConn(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+	exporter := bulk.NewExporter(employees, positions)
+	batcher := bulk.NewBatcher(uow)
+
+	jwtAuth := middleware.NewJWTAuth(middleware.JWTConfig{Secret: "secret"}, nil)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.CorrelationIDInterceptor(), server.TenantInterceptor(), server.JWTInterceptor(jwtAuth)),
+		grpc.ChainStreamInterceptor(server.CorrelationIDStreamInterceptor(), server.TenantStreamInterceptor(), server.JWTStreamInterceptor(jwtAuth)),
+	)
+	pb.RegisterEmployeeServiceServer(srv, server.NewEmployeeServer(employees, bulk.NewImporter(employees, positions, uow), exporter, batcher, bus))
+	pb.RegisterPositionServiceServer(srv, server.NewPositionServer(positions, uow, exporter, batcher, bus))
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// runCommand runs the command line and returns its output.
+func runCommand(t *testing.T, conn *grpc.ClientConn, format outputFormat, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := newApp(conn, "", &out, format).run(context.Background(), args)
+	return out.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	conn := setUpConn(t)
+
+	out, err := runCommand(t, conn, formatJSON, "positions", "create", "-name", "Engineer", "-salary", "15000", "-currency", "USD",
+		"-band-min", "10000", "-band-mid", "15000", "-band-max", "20000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var engineer client.Position
+	if err := json.Unmarshal([]byte(out), &engineer); err != nil {
+		t.Fatal(err)
+	}
+	if engineer.Band == nil || engineer.Band.Max != (client.Money{Amount: 20000, Currency: "USD"}) {
+		t.Fatalf("created position %+v", engineer)
+	}
+
+	if _, err := runCommand(t, conn, formatJSON, "positions", "create", "-name", "Designer", "-salary", "9000", "-currency", "USD"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = runCommand(t, conn, formatJSON, "employees", "create", "-first-name", "Ann", "-last-name", "Lee", "-position", engineer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ann client.Employee
+	if err := json.Unmarshal([]byte(out), &ann); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = runCommand(t, conn, formatTable, "employees", "update", ann.ID, "-last-name", "Park", "-salary", "12000", "-currency", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Park") || !strings.Contains(out, "120.00 USD") {
+		t.Fatalf("updated employee:\n%s", out)
+	}
+
+	tests := map[string]struct {
+		format   outputFormat
+		args     []string
+		expected []string
+		excluded []string
+	}{
+		"list positions filtered by salary": {
+			format:   formatTable,
+			args:     []string{"positions", "list", "-min-salary", "10000"},
+			expected: []string{"NAME", "Engineer", "150.00 USD", "100.00 USD - 200.00 USD"},
+			excluded: []string{"Designer"},
+		},
+		"list positions filtered by name": {
+			format:   formatTable,
+			args:     []string{"positions", "list", "-name", "design"},
+			expected: []string{"Designer", "90.00 USD"},
+			excluded: []string{"Engineer"},
+		},
+		"get employee as yaml": {
+			format:   formatYAML,
+			args:     []string{"employees", "get", ann.ID},
+			expected: []string{"first_name: Ann\n", "last_name: Park\n", "position_id: " + engineer.ID + "\n"},
+		},
+		"list employees filtered by position": {
+			format:   formatTable,
+			args:     []string{"employees", "list", "-position", engineer.ID},
+			expected: []string{ann.ID},
+		},
+		"list employees filtered out": {
+			format:   formatTable,
+			args:     []string{"employees", "list", "-max-salary", "10000"},
+			excluded: []string{ann.ID},
+		},
+		"search employees": {
+			format:   formatJSON,
+			args:     []string{"employees", "list", "-q", "ann"},
+			expected: []string{`"id": "` + ann.ID + `"`},
+		},
+		"export employees": {
+			format:   formatJSON,
+			args:     []string{"employees", "export", "-columns", "firstname,lastname"},
+			expected: []string{"firstname,lastname\n", "Ann,Park\n"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, _ := runCommand(t, conn, tc.format, tc.args...)
+			for _, s := range tc.expected {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected %q in output:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.excluded {
+				if strings.Contains(out, s) {
+					t.Errorf("unexpected %q in output:\n%s", s, out)
+				}
+			}
+		})
+	}
+
+	if _, err := runCommand(t, conn, formatTable, "positions", "delete", engineer.ID); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("expected ErrConflict deleting a position in use, got %v", err)
+	}
+	if _, err := runCommand(t, conn, formatTable, "employees", "get", "missing"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSalaryOverride(t *testing.T) {
+	t.Setenv("JWT_TOKEN_SECRET", "secret")
+	conn := setUpConn(t)
+
+	var out bytes.Buffer
+	if err := newApp(nil, "", &out, formatTable).run(context.Background(), []string{"token", "-sub", "ann", "-roles", "compensation_admin"}); err != nil {
+		t.Fatal(err)
+	}
+	token := strings.TrimSpace(out.String())
+
+	out.Reset()
+	if err := newApp(conn, "", &out, formatJSON).run(context.Background(), []string{"positions", "create", "-name", "Engineer", "-salary", "15000", "-currency", "USD",
+		"-band-min", "10000", "-band-mid", "15000", "-band-max", "20000"}); err != nil {
+		t.Fatal(err)
+	}
+	var engineer client.Position
+	if err := json.Unmarshal(out.Bytes(), &engineer); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		token    string
+		expected error
+	}{
+		"issued token": {
+			token: token,
+		},
+		"without token": {
+			expected: client.ErrForbidden,
+		},
+		"token of another secret": {
+			token:    signedWith(t, "other", "compensation_admin"),
+			expected: client.ErrUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			args := []string{"employees", "create", "-first-name", "Ann", "-last-name", "Lee", "-position", engineer.ID,
+				"-salary", "25000", "-currency", "USD", "-salary-override"}
+			err := newApp(conn, tc.token, &bytes.Buffer{}, formatJSON).run(context.Background(), args)
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func signedWith(t *testing.T, secret, roles string) string {
+	t.Helper()
+
+	token, err := signToken(secret, "ann", roles, time.Now(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestImportEmployees(t *testing.T) {
+	conn := setUpConn(t)
+
+	if _, err := runCommand(t, conn, formatTable, "positions", "create", "-name", "Engineer", "-salary", "15000", "-currency", "USD"); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "employees.csv")
+	csv := "firstname,lastname,position,salary_amount,salary_currency\n" +
+		"Ann,Lee,Engineer,,\n" +
+		"Bob,Kim,Engineer,abc,USD\n" +
+		"Eve,Ray,Manager,,\n"
+	if err := os.WriteFile(file, []byte(csv), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		args             []string
+		expectedImported int
+		expectedErrRows  []int
+	}{
+		"invalid file": {
+			args:            []string{"employees", "import", file},
+			expectedErrRows: []int{3},
+		},
+		"best effort": {
+			args:             []string{"employees", "import", "-best-effort", file},
+			expectedImported: 1,
+			expectedErrRows:  []int{3, 4},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := runCommand(t, conn, formatJSON, tc.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var result importResult
+			if err := json.Unmarshal([]byte(out), &result); err != nil {
+				t.Fatal(err)
+			}
+			if result.Imported != tc.expectedImported {
+				t.Errorf("expected %d imported, got %d", tc.expectedImported, result.Imported)
+			}
+
+			rows := make([]int, len(result.Errors))
+			for i, rowErr := range result.Errors {
+				rows[i] = rowErr.Row
+			}
+			if len(rows) != len(tc.expectedErrRows) {
+				t.Fatalf("expected errors of rows %v, got %+v", tc.expectedErrRows, result.Errors)
+			}
+			for i := range rows {
+				if rows[i] != tc.expectedErrRows[i] {
+					t.Errorf("expected errors of rows %v, got %+v", tc.expectedErrRows, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestIssueToken(t *testing.T) {
+	t.Setenv("JWT_TOKEN_SECRET", "secret")
+
+	var out bytes.Buffer
+	if err := newApp(nil, "", &out, formatTable).run(context.Background(), []string{"token", "-sub", "ann", "-roles", "admin,compensation_admin", "-ttl", "1m"}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.Parse(strings.TrimSpace(out.String()), func(*jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	if claims["sub"] != "ann" {
+		t.Errorf("expected subject ann, got %v", claims["sub"])
+	}
+	if roles, _ := claims["roles"].([]interface{}); len(roles) != 2 || roles[1] != "compensation_admin" {
+		t.Errorf("expected roles admin and compensation_admin, got %v", claims["roles"])
+	}
+	if exp := time.Unix(int64(claims["exp"].(float64)), 0); time.Until(exp) > time.Minute {
+		t.Errorf("expected the token to expire within a minute, expires at %v", exp)
+	}
+
+	t.Setenv("JWT_TOKEN_SECRET", "")
+	if err := newApp(nil, "", &out, formatTable).run(context.Background(), []string{"token"}); !errors.Is(err, errMissingSecret) {
+		t.Errorf("expected errMissingSecret, got %v", err)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	tests := map[string][]string{
+		"no command":           nil,
+		"unknown command":      {"teams"},
+		"unknown subcommand":   {"employees", "rename"},
+		"missing argument":     {"employees", "get"},
+		"unknown flag":         {"positions", "list", "-salary", "1"},
+		"unsupported import":   {"employees", "import", "employees.xlsx"},
+		"update without an id": {"positions", "update", "-name", "Engineer"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := newApp(nil, "", &bytes.Buffer{}, formatTable).run(context.Background(), args)
+			if !errors.Is(err, errUsage) {
+				t.Errorf("expected errUsage, got %v", err)
+			}
+		})
+	}
+}
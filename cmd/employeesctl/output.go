@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dilyara4949/employees-api/client"
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	formatTable outputFormat = "table"
+	formatJSON  outputFormat = "json"
+	formatYAML  outputFormat = "yaml"
+)
+
+func parseFormat(value string) (outputFormat, error) {
+	switch format := outputFormat(value); format {
+	case formatTable, formatJSON, formatYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported output format %q", value)
+}
+
+// table is the table output of a value, the JSON and YAML outputs encode the value itself.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (a *app) print(value any, t table) error {
+	switch a.format {
+	case formatJSON:
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error to encode output: %w", err)
+		}
+		_, err = fmt.Fprintf(a.out, "%s\n", data)
+		return err
+	case formatYAML:
+		data, err := toYAML(value)
+		if err != nil {
+			return fmt.Errorf("error to encode output: %w", err)
+		}
+		_, err = a.out.Write(data)
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// toYAML encodes the value as YAML with the field names and order of its JSON encoding.
+func toYAML(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, decoding it into a node keeps the order of the fields
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// blockStyle clears the flow style and quoting of the decoded JSON, the encoder still quotes the strings
+// that would be read back as another type.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func employeesTable(employees []client.Employee) table {
+	t := table{header: []string{"ID", "FIRST NAME", "LAST NAME", "POSITION", "SALARY", "COMPA RATIO"}}
+	for _, e := range employees {
+		salary := ""
+		if e.Salary != nil {
+			salary = formatMoney(*e.Salary)
+		}
+		compaRatio := ""
+		if e.CompaRatio != 0 {
+			compaRatio = strconv.FormatFloat(e.CompaRatio, 'f', 2, 64)
+		}
+		t.rows = append(t.rows, []string{e.ID, e.FirstName, e.LastName, e.PositionID, salary, compaRatio})
+	}
+	return t
+}
+
+func positionsTable(positions []client.Position) table {
+	t := table{header: []string{"ID", "NAME", "SALARY", "BAND"}}
+	for _, p := range positions {
+		band := ""
+		if p.Band != nil {
+			band = formatMoney(p.Band.Min) + " - " + formatMoney(p.Band.Max)
+		}
+		t.rows = append(t.rows, []string{p.ID, p.Name, formatMoney(p.Salary), band})
+	}
+	return t
+}
+
+// formatMoney formats the amount in major units, e.g. "150.00 USD".
+func formatMoney(m client.Money) string {
+	exp, err := domain.CurrencyExponent(m.Currency)
+	if err != nil || exp == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	unit := int64(1)
+	for i := 0; i < exp; i++ {
+		unit *= 10
+	}
+	return fmt.Sprintf("%d.%0*d %s", m.Amount/unit, exp, m.Amount%unit, m.Currency)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/client"
+)
+
+func (a *app) runPositions(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: positions: missing subcommand", errUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		return a.listPositions(ctx, args[1:])
+	case "get":
+		return a.getPosition(ctx, args[1:])
+	case "create":
+		return a.createPosition(ctx, args[1:])
+	case "update":
+		return a.updatePosition(ctx, args[1:])
+	case "delete":
+		return a.deletePositions(ctx, args[1:])
+	case "export":
+		return a.exportPositions(ctx, args[1:])
+	}
+	return fmt.Errorf("%w: positions: unknown subcommand %q", errUsage, args[0])
+}
+
+// positionFilter selects the listed positions, zero fields match every position.
+type positionFilter struct {
+	name      string
+	minSalary int64
+	maxSalary int64
+	currency  string
+	limit     int
+}
+
+func (f positionFilter) match(p client.Position) bool {
+	if f.name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(f.name)) {
+		return false
+	}
+	if f.currency != "" && p.Salary.Currency != f.currency {
+		return false
+	}
+	if p.Salary.Amount < f.minSalary {
+		return false
+	}
+	return f.maxSalary == 0 || p.Salary.Amount <= f.maxSalary
+}
+
+func (a *app) listPositions(ctx context.Context, args []string) error {
+	var filter positionFilter
+	flags := flag.NewFlagSet("positions list", flag.ContinueOnError)
+	flags.StringVar(&filter.name, "name", "", "only positions whose name contains the text")
+	flags.Int64Var(&filter.minSalary, "min-salary", 0, "only positions paying at least the amount, in minor units")
+	flags.Int64Var(&filter.maxSalary, "max-salary", 0, "only positions paying at most the amount, in minor units")
+	flags.StringVar(&filter.currency, "currency", "", "only positions paid in the currency")
+	flags.IntVar(&filter.limit, "limit", 0, "list at most this many positions")
+	if _, err := subcommand(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	positions, err := a.client.ListPositions(ctx)
+	if err != nil {
+		return err
+	}
+
+	matched := make([]client.Position, 0, len(positions))
+	for _, position := range positions {
+		if filter.limit > 0 && len(matched) == filter.limit {
+			break
+		}
+		if filter.match(position) {
+			matched = append(matched, position)
+		}
+	}
+	return a.print(matched, positionsTable(matched))
+}
+
+func (a *app) getPosition(ctx context.Context, args []string) error {
+	args, err := subcommand(flag.NewFlagSet("positions get", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	position, err := a.client.GetPosition(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return a.print(position, positionsTable([]client.Position{*position}))
+}
+
+// positionFlags defines the flags setting the fields of a position. The band is set only if its
+// flags are given, in the currency of the salary.
+func positionFlags(name string, position *client.Position, band *client.SalaryBand) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(&position.Name, "name", position.Name, "name")
+	flags.Int64Var(&position.Salary.Amount, "salary", position.Salary.Amount, "salary in minor units")
+	flags.StringVar(&position.Salary.Currency, "currency", position.Salary.Currency, "currency of the salary")
+	flags.Int64Var(&band.Min.Amount, "band-min", band.Min.Amount, "minimum of the salary band, in minor units")
+	flags.Int64Var(&band.Mid.Amount, "band-mid", band.Mid.Amount, "midpoint of the salary band, in minor units")
+	flags.Int64Var(&band.Max.Amount, "band-max", band.Max.Amount, "maximum of the salary band, in minor units")
+	return flags
+}
+
+// setBand sets the band of the position if any of its flags was given.
+func setBand(flags *flag.FlagSet, position *client.Position, band client.SalaryBand) {
+	if !isSet(flags, "band-min") && !isSet(flags, "band-mid") && !isSet(flags, "band-max") {
+		return
+	}
+
+	band.Min.Currency = position.Salary.Currency
+	band.Mid.Currency = position.Salary.Currency
+	band.Max.Currency = position.Salary.Currency
+	position.Band = &band
+}
+
+func (a *app) createPosition(ctx context.Context, args []string) error {
+	var (
+		position client.Position
+		band     client.SalaryBand
+	)
+	flags := positionFlags("positions create", &position, &band)
+	if _, err := subcommand(flags, args, 0, 0); err != nil {
+		return err
+	}
+	setBand(flags, &position, band)
+
+	created, err := a.client.CreatePosition(ctx, position)
+	if err != nil {
+		return err
+	}
+	return a.print(created, positionsTable([]client.Position{*created}))
+}
+
+// updatePosition changes the fields of the position given as flags, the other fields are kept.
+func (a *app) updatePosition(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("%w: positions update: missing position ID", errUsage)
+	}
+
+	position, err := a.client.GetPosition(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	var band client.SalaryBand
+	if position.Band != nil {
+		band = *position.Band
+	}
+	flags := positionFlags("positions update", position, &band)
+	if _, err := subcommand(flags, args[1:], 0, 0); err != nil {
+		return err
+	}
+	setBand(flags, position, band)
+
+	updated, err := a.client.UpdatePosition(ctx, *position)
+	if err != nil {
+		return err
+	}
+	return a.print(updated, positionsTable([]client.Position{*updated}))
+}
+
+func (a *app) deletePositions(ctx context.Context, args []string) error {
+	ids, err := subcommand(flag.NewFlagSet("positions delete", flag.ContinueOnError), args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := a.client.DeletePosition(ctx, id); err != nil {
+			return fmt.Errorf("error to delete position %s: %w", id, err)
+		}
+		if a.format == formatTable {
+			fmt.Fprintf(a.out, "position %s deleted\n", id)
+		}
+	}
+	return nil
+}
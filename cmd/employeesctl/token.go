@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+var errMissingSecret = errors.New("JWT_TOKEN_SECRET is empty")
+
+// issueToken prints a JWT signed with JWT_TOKEN_SECRET, for development against a server sharing the secret.
+func (a *app) issueToken(args []string) error {
+	flags := flag.NewFlagSet("token", flag.ContinueOnError)
+	subject := flags.String("sub", "employeesctl", "subject of the token")
+	roles := flags.String("roles", "", "comma separated roles of the token, e.g. compensation_admin")
+	ttl := flags.Duration("ttl", time.Hour, "lifetime of the token")
+	if _, err := subcommand(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	secret := os.Getenv("JWT_TOKEN_SECRET")
+	if secret == "" {
+		return errMissingSecret
+	}
+
+	token, err := signToken(secret, *subject, *roles, time.Now(), *ttl)
+	if err != nil {
+		return err
+	}
+
+	if a.format == formatTable {
+		_, err = fmt.Fprintln(a.out, token)
+		return err
+	}
+	return a.print(struct {
+		Token string `json:"token"`
+	}{token}, table{})
+}
+
+func signToken(secret, subject, roles string, now time.Time, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": subject,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+	if roles != "" {
+		claims["roles"] = strings.Split(roles, ",")
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", fmt.Errorf("error to sign token: %w", err)
+	}
+	return token, nil
+}
@@ -1,56 +1,148 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"github.com/dilyara4949/employees-api/docs/openapi"
 	"github.com/dilyara4949/employees-api/internal/database/redis"
 	"log"
 	"net"
 	"net/http"
 
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/auth"
+	"github.com/dilyara4949/employees-api/internal/bulk"
 	conf "github.com/dilyara4949/employees-api/internal/config"
 	"github.com/dilyara4949/employees-api/internal/controller"
+	"github.com/dilyara4949/employees-api/internal/currency"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/gateway"
 	"github.com/dilyara4949/employees-api/internal/grpc/server"
+	"github.com/dilyara4949/employees-api/internal/idempotency"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/outbox"
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	"github.com/dilyara4949/employees-api/internal/repository"
 	"github.com/dilyara4949/employees-api/internal/repository/employee"
 	"github.com/dilyara4949/employees-api/internal/repository/position"
 	"github.com/dilyara4949/employees-api/internal/route"
+	"github.com/dilyara4949/employees-api/internal/webhook"
 	pb "github.com/dilyara4949/employees-api/proto"
+	"github.com/getkin/kin-openapi/openapi3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
-	positionRepo := position.NewPositionsRepository()
-	employeeRepo := employee.NewEmployeesRepository(positionRepo)
-
 	config, err := conf.NewConfig()
 	if err != nil {
 		log.Fatalf("Error while getting config: %s", err)
 	}
 
+	bus := events.NewBus(config.EventLogSize)
+	eventOutbox := outbox.New()
+	store := repository.NewStore(bus, eventOutbox)
+	positionRepo := position.NewPositionsRepository(store)
+	employeeRepo := employee.NewEmployeesRepository(store, positionRepo)
+	uow := repository.NewUnitOfWork(store, employeeRepo, positionRepo)
+
 	cache, err := redis.ConnectRedis(config.RedisConfig)
 	if err != nil {
 		log.Fatalf("error to connect redis: %v", err)
 	}
 
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if config.RateLimitRedis {
+		limiter = ratelimit.NewRedisLimiter(cache)
+	}
+
+	relay := outbox.NewRelay(eventOutbox, cache, config.OutboxStream)
 	go func() {
-		positionServer := server.NewPositionServer(positionRepo)
-		employeeServer := server.NewEmployeeServer(employeeRepo)
+		if err := relay.Run(context.Background()); err != nil {
+			log.Fatalf("Outbox relay failed: %v", err)
+		}
+	}()
 
-		listen, err := net.Listen("tcp", fmt.Sprintf("%s:%s", config.Address, config.GrpcPort))
+	var rates *currency.Rates
+	if config.ExchangeRatesFile != "" {
+		rates, err = currency.LoadRates(config.ExchangeRatesFile)
 		if err != nil {
-			log.Fatalf("Could not listen on port: %v", err)
+			log.Fatalf("error to load exchange rates: %v", err)
 		}
+	}
 
-		svr := grpc.NewServer(
-			grpc.ChainUnaryInterceptor(
-				server.CorrelationIDInterceptor(),
-				server.LoggingInterceptor,
-			),
-		)
-		pb.RegisterPositionServiceServer(svr, positionServer)
-		pb.RegisterEmployeeServiceServer(svr, employeeServer)
+	importer := bulk.NewImporter(employeeRepo, positionRepo, uow)
+	exporter := bulk.NewExporter(employeeRepo, positionRepo)
+	batcher := bulk.NewBatcher(uow)
 
-		reflection.Register(svr)
+	webhooks := webhook.NewStore()
+	dispatcher := webhook.NewDispatcher(webhooks, bus)
+	go func() {
+		if err := dispatcher.Run(context.Background()); err != nil {
+			log.Printf("Webhook dispatcher stopped: %v", err)
+		}
+	}()
+
+	positionServer := server.NewPositionServer(positionRepo, uow, exporter, batcher, bus)
+	employeeServer := server.NewEmployeeServer(employeeRepo, importer, exporter, batcher, bus)
+
+	apiKeys := apikey.NewStore()
+
+	jwtConfig := middleware.JWTConfig{
+		Secret:                config.JWTTokenSecret,
+		Issuers:               config.JWTIssuers,
+		Audience:              config.JWTAudience,
+		TenantClaim:           config.TenantClaim,
+		DefaultTenantFallback: config.DefaultTenantFallback,
+	}
+	if config.JWKS != "" {
+		keys := auth.NewKeySet(config.JWKS, nil)
+		if err := keys.Refresh(context.Background()); err != nil {
+			log.Fatalf("error to load JWKS: %v", err)
+		}
+		go keys.Run(context.Background(), config.JWKSRefresh)
+		jwtConfig.Keys = keys
+	}
+	jwtAuth := middleware.NewJWTAuth(jwtConfig, auth.NewRevocationList(cache))
+
+	unary := []grpc.UnaryServerInterceptor{
+		server.CorrelationIDInterceptor(),
+		server.TenantInterceptor(),
+		server.JWTInterceptor(jwtAuth),
+		server.APIKeyInterceptor(apiKeys),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		server.CorrelationIDStreamInterceptor(),
+		server.TenantStreamInterceptor(),
+		server.JWTStreamInterceptor(jwtAuth),
+		server.APIKeyStreamInterceptor(apiKeys),
+	}
+	if !config.GrpcAllowAnonymous {
+		unary = append(unary, server.AuthRequiredInterceptor())
+		stream = append(stream, server.AuthRequiredStreamInterceptor())
+	}
+	unary = append(unary,
+		server.RateLimitInterceptor(limiter, config.RateLimit),
+		server.LoggingInterceptor,
+		server.IdempotencyInterceptor(idempotency.NewRedisStore(cache), config.IdempotencyTTL),
+	)
+	stream = append(stream,
+		server.RateLimitStreamInterceptor(limiter, config.RateLimit),
+		server.LoggingStreamInterceptor,
+		server.IdempotencyStreamInterceptor(idempotency.NewRedisStore(cache), config.IdempotencyTTL),
+	)
+
+	svr := grpc.NewServer(grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	pb.RegisterPositionServiceServer(svr, positionServer)
+	pb.RegisterEmployeeServiceServer(svr, employeeServer)
+
+	reflection.Register(svr)
+
+	go func() {
+		listen, err := net.Listen("tcp", fmt.Sprintf("%s:%s", config.Address, config.GrpcPort))
+		if err != nil {
+			log.Fatalf("Could not listen on port: %v", err)
+		}
 
 		if err := svr.Serve(listen); err != nil {
 			log.Fatalf("Failed to serve: %v", err)
@@ -59,12 +151,48 @@ func main() {
 		log.Printf("Hosting server on: %s", listen.Addr().String())
 	}()
 
-	positionController := controller.NewPositionsController(positionRepo)
+	positionController := controller.NewPositionsController(positionRepo, uow, rates)
 	employeeController := controller.NewEmployeesController(employeeRepo)
+	bulkController := controller.NewBulkController(importer, exporter, batcher)
+	eventsController := controller.NewEventsController(bus)
+	webhooksController := controller.NewWebhooksController(webhooks, dispatcher)
+	docsController := controller.NewDocsController(openapi.Spec, openapi.SwaggerUI, openapi.SwaggerUIAssets())
+
+	users := auth.NewMemoryUserStore()
+	if config.AuthUsersFile != "" {
+		if users, err = auth.LoadUsers(config.AuthUsersFile); err != nil {
+			log.Fatalf("error to load users: %v", err)
+		}
+	}
+	issuer := auth.NewIssuer(config.JWTTokenSecret, config.Tokens, users, auth.NewRefreshStore(cache), auth.NewRevocationList(cache))
+	authController := controller.NewAuthController(issuer)
+
+	apiKeysController := controller.NewAPIKeysController(apiKeys)
+
+	var spec *openapi3.T
+	if config.OpenAPIValidation {
+		if spec, err = openapi.Load(); err != nil {
+			log.Fatalf("Failed to load openapi spec: %v", err)
+		}
+	}
 
 	mux := http.NewServeMux()
 
-	route.SetUpRouter(employeeController, positionController, config, mux, cache)
+	route.SetUpRouter(employeeController, positionController, bulkController, eventsController, webhooksController, docsController, authController, apiKeysController, config, mux, jwtAuth, cache, limiter, spec)
+
+	if config.GatewayPrefix != "" {
+		conn, err := gateway.Dial(svr)
+		if err != nil {
+			log.Fatalf("Failed to connect gateway: %v", err)
+		}
+		defer conn.Close()
+
+		gw, err := gateway.New(context.Background(), conn)
+		if err != nil {
+			log.Fatalf("Failed to set up gateway: %v", err)
+		}
+		route.SetUpGateway(gw, config, mux, jwtAuth, apiKeys)
+	}
 
 	log.Printf("Starting server on :%s", config.RestPort)
 
@@ -0,0 +1,90 @@
+//go:build ignore
+
+// fetch_swagger_ui downloads the swagger-ui-dist package of the version from the npm registry and copies the assets
+// served at /docs into the swagger-ui directory. It is run by go generate:
+//
+//	go generate ./docs/openapi
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// assets are the files of the package served at /docs, the license is kept next to them.
+var assets = map[string]bool{
+	"package/swagger-ui.css":       true,
+	"package/swagger-ui-bundle.js": true,
+	"package/LICENSE":              true,
+}
+
+func main() {
+	version := flag.String("version", "", "version of swagger-ui-dist")
+	dir := flag.String("dir", "swagger-ui", "directory the assets are written to")
+	flag.Parse()
+
+	if err := fetch(*version, *dir); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fetch(version, dir string) error {
+	url := fmt.Sprintf("https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-%s.tgz", version)
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("error to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error to download %s: %s", url, resp.Status)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error to read %s: %w", url, err)
+	}
+	archive := tar.NewReader(gz)
+
+	found := 0
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error to read %s: %w", url, err)
+		}
+		if !assets[header.Name] {
+			continue
+		}
+
+		if err := write(filepath.Join(dir, filepath.Base(header.Name)), archive); err != nil {
+			return err
+		}
+		found++
+	}
+
+	if found != len(assets) {
+		return fmt.Errorf("expected %d assets in %s, found %d", len(assets), url, found)
+	}
+	return nil
+}
+
+func write(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error to create %s: %w", path, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("error to write %s: %w", path, err)
+	}
+	return f.Close()
+}
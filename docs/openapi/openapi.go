@@ -0,0 +1,49 @@
+// Package openapi embeds the OpenAPI document of the REST API and the Swagger UI page rendering it.
+package openapi
+
+//go:generate go run fetch_swagger_ui.go -version 5.17.14
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	//go:embed openapi_employees.yaml
+	Spec []byte
+
+	// SwaggerUI is the Swagger UI page rendering the document served at /openapi.yaml. Its assets are served from
+	// /docs, so the page works offline and under a Content-Security-Policy of 'self'.
+	//
+	//go:embed swagger-ui/index.html
+	SwaggerUI []byte
+
+	//go:embed swagger-ui
+	swaggerUI embed.FS
+)
+
+// SwaggerUIAssets returns the swagger-ui-dist files fetched by go generate and the script initializing the page.
+func SwaggerUIAssets() fs.FS {
+	assets, err := fs.Sub(swaggerUI, "swagger-ui")
+	if err != nil {
+		panic(err) // the directory name is valid
+	}
+	return assets
+}
+
+// Load parses and validates the embedded document.
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(Spec)
+	if err != nil {
+		return nil, fmt.Errorf("error to load openapi spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+	return doc, nil
+}
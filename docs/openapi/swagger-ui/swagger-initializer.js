@@ -0,0 +1,7 @@
+// renders the document served at /openapi.yaml, kept out of index.html so the page works without 'unsafe-inline'
+window.onload = () => {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.yaml",
+    dom_id: "#swagger-ui",
+  });
+};
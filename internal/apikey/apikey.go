@@ -0,0 +1,212 @@
+// Package apikey authenticates service-to-service callers, such as batch jobs, with long-lived API keys.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// prefix starts every API key, so leaked keys are easy to find by secret scanners.
+	prefix = "eak_"
+	// RoleAdmin is the JWT role allowed to manage API keys.
+	RoleAdmin = "api_key_admin"
+)
+
+var (
+	ErrKeyNotFound = errors.New("api key not found")
+	ErrInvalidKey  = errors.New("invalid api key")
+	ErrKeyRevoked  = fmt.Errorf("%w: key was revoked", ErrInvalidKey)
+	ErrKeyExpired  = fmt.Errorf("%w: key expired", ErrInvalidKey)
+	// ErrInvalidRequest is returned for keys created without owner or with unknown scopes.
+	ErrInvalidRequest = errors.New("invalid api key request")
+)
+
+// Scopes are the scopes keys can be granted, "read" allows the GET routes and methods of the resource,
+// "write" the others.
+var Scopes = []string{
+	"employees:read",
+	"employees:write",
+	"positions:read",
+	"positions:write",
+	"events:read",
+	"webhooks:read",
+	"webhooks:write",
+}
+
+// Scope returns the scope required to read or write the resource, e.g. "employees:read".
+func Scope(resource string, write bool) string {
+	if write {
+		return resource + ":write"
+	}
+	return resource + ":read"
+}
+
+// Key is an API key of Owner, it acts for the tenant of the admin who created it. Only the hash of the key is
+// stored, the key itself is returned once on creation.
+type Key struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	Owner      string     `json:"owner"`
+	Scopes     []string   `json:"scopes"`
+	Hash       string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	TenantID   string     `json:"-"`
+}
+
+// Validate checks the owner, the scopes and the expiry of a new key.
+func (k Key) Validate(now time.Time) error {
+	if strings.TrimSpace(k.Owner) == "" {
+		return fmt.Errorf("%w: owner is required", ErrInvalidRequest)
+	}
+	if len(k.Scopes) == 0 {
+		return fmt.Errorf("%w: scopes are required", ErrInvalidRequest)
+	}
+	for _, scope := range k.Scopes {
+		if !slices.Contains(Scopes, scope) {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidRequest, scope)
+		}
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expires_at must be in the future", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// Allows reports whether the key was granted the scope.
+func (k Key) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Store keeps the keys in memory. Revoked keys are kept, so their usage can still be audited.
+type Store struct {
+	mu   sync.RWMutex
+	keys map[string]Key
+	now  func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{keys: make(map[string]Key), now: time.Now}
+}
+
+// Create validates and stores the key, generating its ID, and returns the secret API key. It can't be
+// retrieved later.
+func (s *Store) Create(key *Key) (string, error) {
+	now := s.now().UTC()
+	if err := key.Validate(now); err != nil {
+		return "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("error to generate api key: %w", err)
+	}
+
+	key.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	raw := prefix + key.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	key.Hash = hash(raw)
+	key.CreatedAt = now
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = *key
+	return raw, nil
+}
+
+func (s *Store) Get(id string) (Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return Key{}, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// GetAll returns the keys ordered by creation time, optionally only the keys of owner.
+func (s *Store) GetAll(owner string) []Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		if owner == "" || key.Owner == owner {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// Revoke revokes the key, it is rejected from the next request on. Revoking a revoked key keeps its
+// revocation time.
+func (s *Store) Revoke(id string) (Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return Key{}, ErrKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		now := s.now().UTC()
+		key.RevokedAt = &now
+		s.keys[id] = key
+	}
+	return key, nil
+}
+
+// Authenticate returns the key of the secret API key and records its use. Malformed and unknown keys fail with
+// ErrInvalidKey, revoked and expired keys with ErrKeyRevoked and ErrKeyExpired.
+func (s *Store) Authenticate(raw string) (Key, error) {
+	id, _, ok := strings.Cut(strings.TrimPrefix(raw, prefix), "_")
+	if !ok || !strings.HasPrefix(raw, prefix) {
+		return Key{}, ErrInvalidKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash(raw))) != 1 {
+		return Key{}, ErrInvalidKey
+	}
+
+	now := s.now().UTC()
+	if key.RevokedAt != nil {
+		return Key{}, ErrKeyRevoked
+	}
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return Key{}, ErrKeyExpired
+	}
+
+	key.LastUsedAt = &now
+	s.keys[id] = key
+	return key, nil
+}
+
+func hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
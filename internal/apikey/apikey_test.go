@@ -0,0 +1,130 @@
+package apikey
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_Create(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := map[string]struct {
+		key      Key
+		expected error
+	}{
+		"valid": {
+			key: Key{Owner: "payroll", Scopes: []string{"employees:read"}, ExpiresAt: &future},
+		},
+		"no owner": {
+			key:      Key{Scopes: []string{"employees:read"}},
+			expected: ErrInvalidRequest,
+		},
+		"no scopes": {
+			key:      Key{Owner: "payroll"},
+			expected: ErrInvalidRequest,
+		},
+		"unknown scope": {
+			key:      Key{Owner: "payroll", Scopes: []string{"employees:delete"}},
+			expected: ErrInvalidRequest,
+		},
+		"expired": {
+			key:      Key{Owner: "payroll", Scopes: []string{"employees:read"}, ExpiresAt: &past},
+			expected: ErrInvalidRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := NewStore()
+			raw, err := store.Create(&tc.key)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected error %v, got %v", tc.expected, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if !strings.HasPrefix(raw, prefix+tc.key.ID+"_") {
+				t.Errorf("expected the key to start with its ID, got %s", raw)
+			}
+			stored, err := store.Get(tc.key.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Hash == "" || strings.Contains(stored.Hash, raw) {
+				t.Errorf("expected only the hash of the key to be stored, got %q", stored.Hash)
+			}
+		})
+	}
+}
+
+func TestStore_Authenticate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore()
+	store.now = func() time.Time { return now }
+
+	expiresAt := now.Add(time.Hour)
+	expiring := Key{Owner: "payroll", Scopes: []string{"employees:read"}, ExpiresAt: &expiresAt}
+	expiringRaw, err := store.Create(&expiring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked := Key{Owner: "reports", Scopes: []string{"positions:read"}}
+	revokedRaw, err := store.Create(&revoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Revoke(revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := store.Authenticate(expiringRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.ID != expiring.ID || !key.Allows("employees:read") || key.Allows("employees:write") {
+		t.Errorf("unexpected key %+v", key)
+	}
+	if stored, _ := store.Get(expiring.ID); stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(now) {
+		t.Errorf("expected the use to be recorded at %v, got %v", now, stored.LastUsedAt)
+	}
+
+	tests := map[string]struct {
+		raw      string
+		expected error
+	}{
+		"wrong secret": {
+			raw:      expiringRaw[:len(expiringRaw)-1] + "x",
+			expected: ErrInvalidKey,
+		},
+		"unknown key": {
+			raw:      prefix + "unknown_secret",
+			expected: ErrInvalidKey,
+		},
+		"malformed key": {
+			raw:      "secret",
+			expected: ErrInvalidKey,
+		},
+		"revoked key": {
+			raw:      revokedRaw,
+			expected: ErrKeyRevoked,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Authenticate(tc.raw); !errors.Is(err, tc.expected) {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	t.Run("expired key", func(t *testing.T) {
+		now = expiresAt
+		if _, err := store.Authenticate(expiringRaw); !errors.Is(err, ErrKeyExpired) {
+			t.Errorf("expected ErrKeyExpired, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unknownKeyRefresh is how often at most a token with an unknown "kid" loads the key set again, in case the
+// identity provider rotated its keys since the last refresh.
+const unknownKeyRefresh = time.Minute
+
+var (
+	errInvalidJWK = errors.New("invalid JSON web key")
+	// errUnsupportedKey skips the keys of other types, as required by RFC 7517.
+	errUnsupportedKey = errors.New("unsupported JSON web key")
+)
+
+// PublicKey is a key of a JSON Web Key Set. Algorithm is the "alg" of the key, it is empty if the key doesn't
+// restrict its algorithm.
+type PublicKey struct {
+	ID        string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS parses the signature keys of a JSON Web Key Set (RFC 7517). RSA, EC and Ed25519 keys are supported,
+// keys of other types or for encryption are skipped.
+func ParseJWKS(data []byte) ([]PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("error to unmarshal JWKS: %w", err)
+	}
+
+	keys := make([]PublicKey, 0, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if errors.Is(err, errUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %d %q: %w", i, k.Kid, err)
+		}
+		keys = append(keys, PublicKey{ID: k.Kid, Algorithm: k.Alg, Key: key})
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("%w: RSA exponent out of range", errInvalidJWK)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errUnsupportedKey
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("%w: point is not on curve %s", errInvalidJWK, k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, errUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid Ed25519 key", errInvalidJWK)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errUnsupportedKey
+	}
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("%w: invalid base64url integer", errInvalidJWK)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// KeySet caches the keys of a JWKS file or http(s) URL. The keys are refreshed by Run, and when a token has an
+// unknown "kid", so that tokens signed with a rotated key are accepted as soon as the key is published.
+type KeySet struct {
+	source string
+	client *http.Client
+	now    func() time.Time
+
+	mu       sync.RWMutex
+	keys     []PublicKey
+	loadedAt time.Time
+}
+
+func NewKeySet(source string, client *http.Client) *KeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &KeySet{source: source, client: client, now: time.Now}
+}
+
+// Refresh loads the keys again. The cached keys are kept if it fails.
+func (s *KeySet) Refresh(ctx context.Context) error {
+	data, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.loadedAt = s.now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the keys every interval until ctx is done.
+func (s *KeySet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("error to refresh JWKS from %s, keeping the cached keys: %v", s.source, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Keys returns the keys that may verify a token signed with the algorithm alg. If kid is not empty, only the
+// key with that ID is returned, otherwise every key of the algorithm is, as the provider may sign with any
+// of them during a rotation.
+func (s *KeySet) Keys(ctx context.Context, kid, alg string) ([]crypto.PublicKey, error) {
+	keys, loadedAt := s.cached()
+	matching := matchKeys(keys, kid, alg)
+	if len(matching) > 0 || kid == "" || s.now().Sub(loadedAt) < unknownKeyRefresh {
+		return matching, nil
+	}
+
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	keys, _ = s.cached()
+	return matchKeys(keys, kid, alg), nil
+}
+
+func (s *KeySet) cached() ([]PublicKey, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys, s.loadedAt
+}
+
+func (s *KeySet) load(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		data, err := os.ReadFile(s.source)
+		if err != nil {
+			return nil, fmt.Errorf("error to read JWKS: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("error to create JWKS request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error to fetch JWKS: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("error to read JWKS: %w", err)
+	}
+	return data, nil
+}
+
+func matchKeys(keys []PublicKey, kid, alg string) []crypto.PublicKey {
+	matching := make([]crypto.PublicKey, 0, 1)
+	for _, key := range keys {
+		if kid != "" && key.ID != kid {
+			continue
+		}
+		if key.Algorithm != "" && key.Algorithm != alg {
+			continue
+		}
+		if !keyFits(key.Key, alg) {
+			continue
+		}
+		matching = append(matching, key.Key)
+	}
+	return matching
+}
+
+// keyFits reports whether the key type can verify the algorithm, so that e.g. an RSA key is never used as an
+// HMAC secret.
+func keyFits(key crypto.PublicKey, alg string) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return strings.HasPrefix(alg, "ES")
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	default:
+		return false
+	}
+}
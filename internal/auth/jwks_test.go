@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func encodeJWKS(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"alg": "RS256",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edJWK := map[string]string{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(edKey)}
+
+	offCurve := ecJWK("ec", &ecKey.PublicKey)
+	offCurve["y"] = offCurve["x"]
+
+	tests := map[string]struct {
+		jwks        []byte
+		expectedIDs []string
+		expectedErr error
+	}{
+		"supported keys": {
+			jwks:        encodeJWKS(t, rsaJWK("rsa", &rsaKey.PublicKey), ecJWK("ec", &ecKey.PublicKey), edJWK),
+			expectedIDs: []string{"rsa", "ec", "ed"},
+		},
+		"skips encryption and unsupported keys": {
+			jwks: encodeJWKS(t,
+				map[string]string{"kty": "RSA", "kid": "enc", "use": "enc"},
+				map[string]string{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+				map[string]string{"kty": "OKP", "kid": "x25519", "crv": "X25519", "x": "AAAA"},
+				edJWK,
+			),
+			expectedIDs: []string{"ed"},
+		},
+		"point not on curve": {
+			jwks:        encodeJWKS(t, offCurve),
+			expectedErr: errInvalidJWK,
+		},
+		"invalid modulus": {
+			jwks:        encodeJWKS(t, map[string]string{"kty": "RSA", "kid": "rsa", "n": "!", "e": "AQAB"}),
+			expectedErr: errInvalidJWK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys, err := ParseJWKS(tc.jwks)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if len(keys) != len(tc.expectedIDs) {
+				t.Fatalf("expected keys %v, got %+v", tc.expectedIDs, keys)
+			}
+			for i, key := range keys {
+				if key.ID != tc.expectedIDs[i] {
+					t.Errorf("expected key %s, got %s", tc.expectedIDs[i], key.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	ctx := context.Background()
+
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu       sync.Mutex
+		jwks     = encodeJWKS(t, ecJWK("old", &oldKey.PublicKey))
+		requests int
+	)
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		w.Write(jwks)
+	}))
+	t.Cleanup(provider.Close)
+
+	now := time.Now()
+	keys := NewKeySet(provider.URL, provider.Client())
+	keys.now = func() time.Time { return now }
+	if err := keys.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := keys.Keys(ctx, "old", "ES256"); err != nil || len(found) != 1 || !oldKey.PublicKey.Equal(found[0]) {
+		t.Fatalf("expected the old key, got %v, %v", found, err)
+	}
+	if found, _ := keys.Keys(ctx, "old", "RS256"); len(found) != 0 {
+		t.Errorf("expected no key of another algorithm, got %v", found)
+	}
+
+	// the provider publishes the new key next to the old one before signing with it
+	mu.Lock()
+	jwks = encodeJWKS(t, ecJWK("old", &oldKey.PublicKey), ecJWK("new", &newKey.PublicKey))
+	mu.Unlock()
+
+	if found, _ := keys.Keys(ctx, "new", "ES256"); len(found) != 0 || requests != 1 {
+		t.Errorf("expected unknown keys not to be loaded right after a refresh, got %v after %d requests", found, requests)
+	}
+
+	now = now.Add(unknownKeyRefresh)
+	if found, err := keys.Keys(ctx, "new", "ES256"); err != nil || len(found) != 1 || !newKey.PublicKey.Equal(found[0]) {
+		t.Fatalf("expected the new key to be loaded, got %v, %v", found, err)
+	}
+	if found, _ := keys.Keys(ctx, "", "ES256"); len(found) != 2 {
+		t.Errorf("expected both keys during the rotation, got %v", found)
+	}
+}
+
+func TestKeySet_File(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, encodeJWKS(t, rsaJWK("rsa", &rsaKey.PublicKey)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := NewKeySet(path, nil)
+	if err := keys.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := keys.Keys(context.Background(), "rsa", "RS256"); len(found) != 1 || !rsaKey.PublicKey.Equal(found[0]) {
+		t.Errorf("expected the RSA key, got %v", found)
+	}
+
+	if err := NewKeySet(filepath.Join(t.TempDir(), "missing.json"), nil).Refresh(context.Background()); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyPassword(t *testing.T) {
+	bcryptHash, err := HashBcrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2Hash, err := HashArgon2("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		hash     string
+		password string
+		expected error
+	}{
+		"bcrypt": {
+			hash:     bcryptHash,
+			password: "secret",
+		},
+		"bcrypt mismatch": {
+			hash:     bcryptHash,
+			password: "Secret",
+			expected: ErrPasswordMismatch,
+		},
+		"argon2id": {
+			hash:     argon2Hash,
+			password: "secret",
+		},
+		"argon2id mismatch": {
+			hash:     argon2Hash,
+			password: "secret ",
+			expected: ErrPasswordMismatch,
+		},
+		"argon2id with other parameters": {
+			hash:     "$argon2id$v=19$m=1024,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$aLbhcMTjmb1OnQYmUPhGgTqijydpTRPl",
+			password: "secret",
+		},
+		"invalid argon2id": {
+			hash:     "$argon2id$v=19$m=1024$c29tZXNhbHQ$a2V5",
+			password: "secret",
+			expected: errInvalidArgon2Params,
+		},
+		"argon2id without threads": {
+			hash:     "$argon2id$v=19$m=1024,t=2,p=0$c29tZXNhbHRzb21lc2FsdA$aLbhcMTjmb1OnQYmUPhGgTqijydpTRPl",
+			password: "secret",
+			expected: errInvalidArgon2Params,
+		},
+		"argon2id without iterations": {
+			hash:     "$argon2id$v=19$m=1024,t=0,p=1$c29tZXNhbHRzb21lc2FsdA$aLbhcMTjmb1OnQYmUPhGgTqijydpTRPl",
+			password: "secret",
+			expected: errInvalidArgon2Params,
+		},
+		"argon2id without memory": {
+			hash:     "$argon2id$v=19$m=0,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$aLbhcMTjmb1OnQYmUPhGgTqijydpTRPl",
+			password: "secret",
+			expected: errInvalidArgon2Params,
+		},
+		"argon2id with too much memory": {
+			hash:     "$argon2id$v=19$m=4194304,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$aLbhcMTjmb1OnQYmUPhGgTqijydpTRPl",
+			password: "secret",
+			expected: errInvalidArgon2Params,
+		},
+		"argon2id with too many iterations": {
+			hash:     "$argon2id$v=19$m=1024,t=1000,p=1$c29tZXNhbHRzb21lc2FsdA$aLbhcMTjmb1OnQYmUPhGgTqijydpTRPl",
+			password: "secret",
+			expected: errInvalidArgon2Params,
+		},
+		"argon2id with too many threads": {
+			hash:     "$argon2id$v=19$m=1024,t=2,p=256$c29tZXNhbHRzb21lc2FsdA$aLbhcMTjmb1OnQYmUPhGgTqijydpTRPl",
+			password: "secret",
+			expected: errInvalidArgon2Params,
+		},
+		"argon2id with short salt": {
+			hash:     "$argon2id$v=19$m=1024,t=2,p=1$c2FsdA$aLbhcMTjmb1OnQYmUPhGgTqijydpTRPl",
+			password: "secret",
+			expected: errInvalidArgon2Params,
+		},
+		"dummy hash of unknown users": {
+			hash:     dummyHash,
+			password: "secret",
+			expected: ErrPasswordMismatch,
+		},
+		"plain text": {
+			hash:     "secret",
+			password: "secret",
+			expected: ErrUnsupportedHash,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := VerifyPassword(tc.hash, tc.password); !errors.Is(err, tc.expected) {
+				t.Errorf("expected error %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when a rotated refresh token is used again. It was probably stolen,
+	// so every token of its family is revoked.
+	ErrRefreshTokenReused = fmt.Errorf("%w: token was already used", ErrInvalidRefreshToken)
+)
+
+// refreshRecord is stored for each refresh token, the tokens rotated from the same login share a family.
+type refreshRecord struct {
+	UserID string `json:"user_id"`
+	Family string `json:"family"`
+}
+
+// RefreshStore keeps the refresh tokens in Redis by their hash, the tokens themselves are never stored.
+// Each family points to its current token: refreshing with any other token of the family revokes it.
+type RefreshStore struct {
+	client *redis.Client
+}
+
+func NewRefreshStore(client *redis.Client) *RefreshStore {
+	return &RefreshStore{client: client}
+}
+
+// rotateScript replaces the current token of the family if it is the old token and returns 1. Otherwise it
+// deletes the family and returns 0, or returns -1 if the family was already revoked.
+var rotateScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return -1
+end
+if current == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+	return 1
+end
+redis.call('DEL', KEYS[1])
+return 0
+`)
+
+func refreshKey(hash string) string {
+	return "auth:refresh:" + hash
+}
+
+func familyKey(family string) string {
+	return "auth:refresh-family:" + family
+}
+
+// Create returns a refresh token of the user starting a new family.
+func (s *RefreshStore) Create(ctx context.Context, userID string, ttl time.Duration) (string, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	record := refreshRecord{UserID: userID, Family: uuid.New().String()}
+	if err := s.save(ctx, hash, record, ttl); err != nil {
+		return "", err
+	}
+	if err := s.client.Set(ctx, familyKey(record.Family), hash, ttl).Err(); err != nil {
+		return "", fmt.Errorf("error to save refresh token family: %w", err)
+	}
+	return token, nil
+}
+
+// Rotate replaces the token by a new token of the same family, and returns the new token and its user ID.
+func (s *RefreshStore) Rotate(ctx context.Context, token string, ttl time.Duration) (string, string, error) {
+	oldHash := hashToken(token)
+	record, err := s.get(ctx, oldHash)
+	if err != nil {
+		return "", "", err
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+	// the new record is saved first, it is useless until the family points to it
+	if err := s.save(ctx, newHash, record, ttl); err != nil {
+		return "", "", err
+	}
+
+	rotated, err := rotateScript.Run(ctx, s.client, []string{familyKey(record.Family)}, oldHash, newHash, ttl.Milliseconds()).Int()
+	if err != nil {
+		return "", "", fmt.Errorf("error to rotate refresh token: %w", err)
+	}
+	switch rotated {
+	case 0:
+		return "", "", ErrRefreshTokenReused
+	case -1:
+		return "", "", ErrInvalidRefreshToken
+	}
+	return newToken, record.UserID, nil
+}
+
+// Revoke revokes every token of the family of the token. Unknown tokens are ignored.
+func (s *RefreshStore) Revoke(ctx context.Context, token string) error {
+	record, err := s.get(ctx, hashToken(token))
+	if errors.Is(err, ErrInvalidRefreshToken) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.Del(ctx, familyKey(record.Family)).Err(); err != nil {
+		return fmt.Errorf("error to revoke refresh token: %w", err)
+	}
+	return nil
+}
+
+func (s *RefreshStore) save(ctx context.Context, hash string, record refreshRecord, ttl time.Duration) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, refreshKey(hash), value, ttl).Err(); err != nil {
+		return fmt.Errorf("error to save refresh token: %w", err)
+	}
+	return nil
+}
+
+func (s *RefreshStore) get(ctx context.Context, hash string) (refreshRecord, error) {
+	value, err := s.client.Get(ctx, refreshKey(hash)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return refreshRecord{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return refreshRecord{}, fmt.Errorf("error to get refresh token: %w", err)
+	}
+
+	var record refreshRecord
+	if err := json.Unmarshal(value, &record); err != nil {
+		return refreshRecord{}, fmt.Errorf("error to unmarshal refresh token: %w", err)
+	}
+	return record, nil
+}
+
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("error to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationList keeps the IDs of the revoked access tokens in Redis until the tokens expire,
+// so every instance rejects them.
+type RevocationList struct {
+	client *redis.Client
+}
+
+func NewRevocationList(client *redis.Client) *RevocationList {
+	return &RevocationList{client: client}
+}
+
+func revokedKey(jti string) string {
+	return "auth:revoked:" + jti
+}
+
+// Revoke rejects the token with the ID until it expires at expiresAt.
+func (l *RevocationList) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := l.client.Set(ctx, revokedKey(jti), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("error to revoke token: %w", err)
+	}
+	return nil
+}
+
+func (l *RevocationList) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := l.client.Exists(ctx, revokedKey(jti)).Result()
+	if err != nil {
+		return false, fmt.Errorf("error to check token revocation: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const tokenType = "Bearer"
+
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// reservedClaims can't be set by the claims of a user.
+var reservedClaims = map[string]bool{
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
+	"preferred_username": true, "role": true, "roles": true,
+}
+
+// TokenConfig configures the issued tokens.
+type TokenConfig struct {
+	// Issuer and Audience are the "iss" and "aud" claims of the access tokens, they are omitted if empty.
+	Issuer   string
+	Audience string
+	// AccessTTL is the lifetime of access tokens, they are short-lived as revoking them takes a Redis lookup
+	// on every request.
+	AccessTTL time.Duration
+	// RefreshTTL is the lifetime of refresh tokens, every refresh issues a new one.
+	RefreshTTL time.Duration
+}
+
+// TokenPair is the response of a login or refresh, in the format of RFC 6749 section 5.1.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Issuer issues HMAC signed access tokens with the secret verified by middleware.JWTAuth.
+type Issuer struct {
+	secret      []byte
+	config      TokenConfig
+	users       UserStore
+	refresh     *RefreshStore
+	revocations *RevocationList
+	now         func() time.Time
+}
+
+func NewIssuer(secret string, config TokenConfig, users UserStore, refresh *RefreshStore, revocations *RevocationList) *Issuer {
+	return &Issuer{
+		secret:      []byte(secret),
+		config:      config,
+		users:       users,
+		refresh:     refresh,
+		revocations: revocations,
+		now:         time.Now,
+	}
+}
+
+// dummyHash is verified for unknown users, so the response time doesn't reveal which usernames exist.
+// It has the parameters of HashArgon2.
+const dummyHash = "$argon2id$v=19$m=65536,t=3,p=4$WjCY5TGJRd+ZCMMNhuxd5A$UXTKX+wx6MAn7kprasr/8ZmKEKH0z/MhZQbP8Od90bs"
+
+// Login returns the tokens of the user if the password matches. Unknown users, wrong passwords and disabled
+// users all fail with ErrInvalidCredentials.
+func (i *Issuer) Login(ctx context.Context, username, password string) (TokenPair, error) {
+	user, err := i.users.FindByUsername(ctx, username)
+	if errors.Is(err, ErrUserNotFound) {
+		VerifyPassword(dummyHash, password)
+		return TokenPair{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := VerifyPassword(user.PasswordHash, password); err != nil {
+		if errors.Is(err, ErrPasswordMismatch) {
+			return TokenPair{}, ErrInvalidCredentials
+		}
+		return TokenPair{}, err
+	}
+	if user.Disabled {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+
+	refreshToken, err := i.refresh.Create(ctx, user.ID, i.config.RefreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return i.tokenPair(user, refreshToken)
+}
+
+// Refresh exchanges the refresh token for new tokens, the refresh token can't be used again.
+func (i *Issuer) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	refreshToken, userID, err := i.refresh.Rotate(ctx, refreshToken, i.config.RefreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	// the user may have been disabled or changed roles since the login
+	user, err := i.users.FindByID(ctx, userID)
+	if errors.Is(err, ErrUserNotFound) || (err == nil && user.Disabled) {
+		if err := i.refresh.Revoke(ctx, refreshToken); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return i.tokenPair(user, refreshToken)
+}
+
+// Revoke revokes an access token or the family of a refresh token. As in RFC 7009, invalid and unknown
+// tokens are ignored.
+func (i *Issuer) Revoke(ctx context.Context, token string) error {
+	claims := jwt.RegisteredClaims{}
+	if _, err := jwt.ParseWithClaims(token, &claims, i.keyFunc); err == nil {
+		if claims.ID == "" || claims.ExpiresAt == nil {
+			return nil
+		}
+		return i.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+	}
+	return i.refresh.Revoke(ctx, token)
+}
+
+func (i *Issuer) tokenPair(user User, refreshToken string) (TokenPair, error) {
+	accessToken, err := i.accessToken(user)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		TokenType:    tokenType,
+		ExpiresIn:    int(i.config.AccessTTL.Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (i *Issuer) accessToken(user User) (string, error) {
+	now := i.now()
+
+	claims := jwt.MapClaims{
+		"sub":                user.ID,
+		"jti":                uuid.New().String(),
+		"iat":                now.Unix(),
+		"nbf":                now.Unix(),
+		"exp":                now.Add(i.config.AccessTTL).Unix(),
+		"preferred_username": user.Username,
+	}
+	if len(user.Roles) > 0 {
+		claims["roles"] = user.Roles
+	}
+	if i.config.Issuer != "" {
+		claims["iss"] = i.config.Issuer
+	}
+	if i.config.Audience != "" {
+		claims["aud"] = i.config.Audience
+	}
+
+	for name, value := range user.Claims {
+		if !reservedClaims[name] {
+			claims[name] = value
+		}
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+	if err != nil {
+		return "", fmt.Errorf("error to sign access token: %w", err)
+	}
+	return token, nil
+}
+
+func (i *Issuer) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, jwt.NewValidationError("unexpected signing method", jwt.ValidationErrorSignatureInvalid)
+	}
+	return i.secret, nil
+}
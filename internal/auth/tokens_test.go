@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+const secret = "secret"
+
+func setUpIssuer(t *testing.T) (*Issuer, *MemoryUserStore, *RevocationList) {
+	t.Helper()
+
+	cache := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { cache.Close() })
+
+	hash, err := HashBcrypt("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := NewMemoryUserStore(User{
+		ID:           "1",
+		Username:     "ann",
+		PasswordHash: hash,
+		Roles:        []string{"compensation_admin"},
+		Claims:       map[string]any{"department": "sales", "sub": "root"},
+	})
+
+	revocations := NewRevocationList(cache)
+	config := TokenConfig{Issuer: "employees-api", Audience: "employees", AccessTTL: time.Minute, RefreshTTL: time.Hour}
+	return NewIssuer(secret, config, users, NewRefreshStore(cache), revocations), users, revocations
+}
+
+func parseClaims(t *testing.T, token string) jwt.MapClaims {
+	t.Helper()
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func TestIssuer_Login(t *testing.T) {
+	issuer, users, _ := setUpIssuer(t)
+	ctx := context.Background()
+
+	tokens, err := issuer.Login(ctx, "ann", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.TokenType != "Bearer" || tokens.ExpiresIn != 60 || tokens.RefreshToken == "" {
+		t.Errorf("unexpected tokens %+v", tokens)
+	}
+
+	claims := parseClaims(t, tokens.AccessToken)
+	expected := map[string]any{"sub": "1", "iss": "employees-api", "aud": "employees", "department": "sales", "preferred_username": "ann"}
+	for name, value := range expected {
+		if claims[name] != value {
+			t.Errorf("expected claim %s %v, got %v", name, value, claims[name])
+		}
+	}
+	if roles, _ := claims["roles"].([]interface{}); len(roles) != 1 || roles[0] != "compensation_admin" {
+		t.Errorf("expected roles [compensation_admin], got %v", claims["roles"])
+	}
+	if claims["jti"] == "" {
+		t.Error("expected a jti claim")
+	}
+
+	failures := map[string]struct {
+		username, password string
+	}{
+		"wrong password": {username: "ann", password: "Password"},
+		"unknown user":   {username: "bob", password: "password"},
+	}
+	for name, tc := range failures {
+		t.Run(name, func(t *testing.T) {
+			if _, err := issuer.Login(ctx, tc.username, tc.password); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("expected ErrInvalidCredentials, got %v", err)
+			}
+		})
+	}
+
+	t.Run("disabled user", func(t *testing.T) {
+		user, _ := users.FindByID(ctx, "1")
+		user.Disabled = true
+		users.Put(user)
+
+		if _, err := issuer.Login(ctx, "ann", "password"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+}
+
+func TestIssuer_Refresh(t *testing.T) {
+	issuer, users, _ := setUpIssuer(t)
+	ctx := context.Background()
+
+	login, err := issuer.Login(ctx, "ann", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := issuer.Refresh(ctx, login.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.RefreshToken == login.RefreshToken || parseClaims(t, refreshed.AccessToken)["sub"] != "1" {
+		t.Fatalf("unexpected tokens %+v", refreshed)
+	}
+
+	// reusing the rotated token revokes the family, including the token it was rotated to
+	if _, err := issuer.Refresh(ctx, login.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("expected ErrRefreshTokenReused, got %v", err)
+	}
+	if _, err := issuer.Refresh(ctx, refreshed.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expected ErrInvalidRefreshToken after reuse, got %v", err)
+	}
+
+	if _, err := issuer.Refresh(ctx, "unknown"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expected ErrInvalidRefreshToken, got %v", err)
+	}
+
+	t.Run("disabled user", func(t *testing.T) {
+		login, err := issuer.Login(ctx, "ann", "password")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		user, _ := users.FindByID(ctx, "1")
+		user.Disabled = true
+		users.Put(user)
+
+		if _, err := issuer.Refresh(ctx, login.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("expected ErrInvalidRefreshToken, got %v", err)
+		}
+	})
+}
+
+func TestIssuer_Revoke(t *testing.T) {
+	issuer, _, revocations := setUpIssuer(t)
+	ctx := context.Background()
+
+	tokens, err := issuer.Login(ctx, "ann", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := issuer.Revoke(ctx, tokens.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := revocations.IsRevoked(ctx, parseClaims(t, tokens.AccessToken)["jti"].(string))
+	if err != nil || !revoked {
+		t.Errorf("expected the access token to be revoked, got %v, %v", revoked, err)
+	}
+
+	if err := issuer.Revoke(ctx, tokens.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issuer.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expected ErrInvalidRefreshToken after revocation, got %v", err)
+	}
+
+	if err := issuer.Revoke(ctx, "unknown"); err != nil {
+		t.Errorf("expected unknown tokens to be ignored, got %v", err)
+	}
+}
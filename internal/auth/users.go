@@ -0,0 +1,104 @@
+// Package auth issues the JWTs accepted by the API to users logging in with a password, and refreshes and
+// revokes them.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// User is an account allowed to log in. Its ID is the subject of its tokens.
+type User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	// PasswordHash is a bcrypt or argon2id hash, see HashBcrypt and HashArgon2.
+	PasswordHash string   `json:"password_hash"`
+	Roles        []string `json:"roles"`
+	// Claims are added to the access tokens of the user, e.g. {"department": "sales"}. They can't override
+	// the registered claims or the roles.
+	Claims   map[string]any `json:"claims"`
+	Disabled bool           `json:"disabled"`
+}
+
+// UserStore looks up the users logging in, it is implemented by the identity backend of the deployment.
+type UserStore interface {
+	// FindByUsername returns ErrUserNotFound if there is no user with the username.
+	FindByUsername(ctx context.Context, username string) (User, error)
+	// FindByID returns ErrUserNotFound if there is no user with the ID.
+	FindByID(ctx context.Context, id string) (User, error)
+}
+
+// MemoryUserStore keeps a fixed set of users, for small deployments and tests.
+type MemoryUserStore struct {
+	mu         sync.RWMutex
+	byUsername map[string]User
+	byID       map[string]User
+}
+
+func NewMemoryUserStore(users ...User) *MemoryUserStore {
+	s := &MemoryUserStore{byUsername: make(map[string]User), byID: make(map[string]User)}
+	for _, user := range users {
+		s.Put(user)
+	}
+	return s
+}
+
+// LoadUsers reads a JSON array of users from the file.
+func LoadUsers(path string) (*MemoryUserStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error to read users: %w", err)
+	}
+
+	var users []User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("error to unmarshal users: %w", err)
+	}
+
+	for i, user := range users {
+		if user.ID == "" || user.Username == "" || user.PasswordHash == "" {
+			return nil, fmt.Errorf("user %d: id, username and password_hash are required", i)
+		}
+	}
+	return NewMemoryUserStore(users...), nil
+}
+
+// Put adds or replaces the user with the ID of user.
+func (s *MemoryUserStore) Put(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.byID[user.ID]; ok {
+		delete(s.byUsername, old.Username)
+	}
+	s.byID[user.ID] = user
+	s.byUsername[user.Username] = user
+}
+
+func (s *MemoryUserStore) FindByUsername(_ context.Context, username string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.byUsername[username]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *MemoryUserStore) FindByID(_ context.Context, id string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.byID[id]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return user, nil
+}
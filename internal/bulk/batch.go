@@ -0,0 +1,259 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+type BatchOp string
+
+const (
+	OpCreate BatchOp = "create"
+	OpUpdate BatchOp = "update"
+	OpDelete BatchOp = "delete"
+)
+
+var (
+	ErrInvalidOperation        = errors.New("invalid batch operation")
+	ErrSalaryOverrideForbidden = errors.New("salary override requires " + domain.RoleCompensationAdmin + " role")
+	// ErrBatchAborted is the error of the items of an atomic batch that were not applied because another item failed.
+	ErrBatchAborted = errors.New("not applied, another item of the atomic batch failed")
+)
+
+type EmployeeOperation struct {
+	Op BatchOp `json:"op"`
+	// ID is the employee to update or delete, for updates it defaults to Employee.ID.
+	ID       string           `json:"id,omitempty"`
+	Employee *domain.Employee `json:"employee,omitempty"`
+}
+
+type PositionOperation struct {
+	Op BatchOp `json:"op"`
+	// ID is the position to update or delete, for updates it defaults to Position.ID.
+	ID       string           `json:"id,omitempty"`
+	Position *domain.Position `json:"position,omitempty"`
+}
+
+type BatchOptions struct {
+	// Atomic applies either all operations or none of them, otherwise every valid operation is applied.
+	Atomic bool
+	// AllowSalaryOverride permits employees with salary_override, it must only be set for privileged callers.
+	AllowSalaryOverride bool
+}
+
+// BatchItem is the outcome of the operation at Index, Err is nil if it was applied.
+type BatchItem struct {
+	Index int
+	Op    BatchOp
+	ID    string
+	Err   error
+}
+
+type BatchResult struct {
+	Atomic    bool
+	Succeeded int
+	Failed    int
+	Items     []BatchItem
+}
+
+type Batcher struct {
+	UnitOfWork domain.UnitOfWork
+}
+
+func NewBatcher(uow domain.UnitOfWork) *Batcher {
+	return &Batcher{UnitOfWork: uow}
+}
+
+// errBatchFailed rolls back the transaction of an atomic batch with failed items.
+var errBatchFailed = errors.New("batch failed")
+
+// BatchEmployees applies the operations in order. An atomic batch runs in a single transaction that is rolled back
+// if any operation fails, otherwise every operation runs in a transaction of its own.
+func (b *Batcher) BatchEmployees(ctx context.Context, ops []EmployeeOperation, opts BatchOptions) (BatchResult, error) {
+	items := make([]BatchItem, len(ops))
+	for i, op := range ops {
+		items[i] = BatchItem{Index: i, Op: op.Op, ID: op.ID}
+	}
+
+	return b.run(ctx, items, opts, func(ctx context.Context, tx domain.Tx, i int) error {
+		return applyEmployee(ctx, tx, ops[i], &items[i], opts)
+	})
+}
+
+// BatchPositions applies the operations like BatchEmployees, positions with employees can't be deleted.
+func (b *Batcher) BatchPositions(ctx context.Context, ops []PositionOperation, opts BatchOptions) (BatchResult, error) {
+	items := make([]BatchItem, len(ops))
+	for i, op := range ops {
+		items[i] = BatchItem{Index: i, Op: op.Op, ID: op.ID}
+	}
+
+	return b.run(ctx, items, opts, func(ctx context.Context, tx domain.Tx, i int) error {
+		return applyPosition(ctx, tx, ops[i], &items[i])
+	})
+}
+
+func (b *Batcher) run(ctx context.Context, items []BatchItem, opts BatchOptions, apply func(ctx context.Context, tx domain.Tx, i int) error) (BatchResult, error) {
+	result := BatchResult{Atomic: opts.Atomic, Items: items}
+
+	if !opts.Atomic {
+		for i := range items {
+			items[i].Err = b.UnitOfWork.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+				return apply(ctx, tx, i)
+			})
+		}
+		return summarize(result), nil
+	}
+
+	err := b.UnitOfWork.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		failed := false
+		for i := range items {
+			if items[i].Err = apply(ctx, tx, i); items[i].Err != nil {
+				failed = true
+			}
+		}
+		if failed {
+			return errBatchFailed
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchFailed) {
+		abort(items)
+		return summarize(result), nil
+	}
+	if err != nil {
+		return BatchResult{}, fmt.Errorf("error to apply batch: %w", err)
+	}
+	return summarize(result), nil
+}
+
+func applyEmployee(ctx context.Context, tx domain.Tx, op EmployeeOperation, item *BatchItem, opts BatchOptions) error {
+	switch op.Op {
+	case OpCreate:
+		if op.Employee == nil {
+			return fmt.Errorf("%w: employee is required", ErrInvalidOperation)
+		}
+
+		employee := *op.Employee
+		employee.ID = ""
+		if err := checkEmployee(ctx, tx, employee, opts); err != nil {
+			return err
+		}
+		if err := tx.Employees().Create(ctx, &employee); err != nil {
+			return err
+		}
+		item.ID = employee.ID
+		return nil
+	case OpUpdate:
+		if op.Employee == nil {
+			return fmt.Errorf("%w: employee is required", ErrInvalidOperation)
+		}
+
+		employee := *op.Employee
+		if op.ID != "" {
+			employee.ID = op.ID
+		}
+		item.ID = employee.ID
+
+		if employee.ID == "" {
+			return fmt.Errorf("%w: id is required", ErrInvalidOperation)
+		}
+		if _, err := tx.Employees().Get(ctx, employee.ID); err != nil {
+			return err
+		}
+		if err := checkEmployee(ctx, tx, employee, opts); err != nil {
+			return err
+		}
+		return tx.Employees().Update(ctx, employee)
+	case OpDelete:
+		if op.ID == "" {
+			return fmt.Errorf("%w: id is required", ErrInvalidOperation)
+		}
+		return tx.Employees().Delete(ctx, op.ID)
+	}
+	return fmt.Errorf("%w: unknown op %q", ErrInvalidOperation, op.Op)
+}
+
+func applyPosition(ctx context.Context, tx domain.Tx, op PositionOperation, item *BatchItem) error {
+	switch op.Op {
+	case OpCreate:
+		if op.Position == nil {
+			return fmt.Errorf("%w: position is required", ErrInvalidOperation)
+		}
+
+		position := *op.Position
+		position.ID = ""
+		if err := validatePosition(position); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOperation, err)
+		}
+		if err := tx.Positions().Create(ctx, &position); err != nil {
+			return err
+		}
+		item.ID = position.ID
+		return nil
+	case OpUpdate:
+		if op.Position == nil {
+			return fmt.Errorf("%w: position is required", ErrInvalidOperation)
+		}
+
+		position := *op.Position
+		if op.ID != "" {
+			position.ID = op.ID
+		}
+		item.ID = position.ID
+
+		if position.ID == "" {
+			return fmt.Errorf("%w: id is required", ErrInvalidOperation)
+		}
+		if err := validatePosition(position); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOperation, err)
+		}
+		return domain.UpdatePosition(ctx, tx, position)
+	case OpDelete:
+		if op.ID == "" {
+			return fmt.Errorf("%w: id is required", ErrInvalidOperation)
+		}
+		return domain.DeletePosition(ctx, tx, op.ID, "")
+	}
+	return fmt.Errorf("%w: unknown op %q", ErrInvalidOperation, op.Op)
+}
+
+// checkEmployee checks the salary override permission and that the position exists,
+// the salary is checked against the position band by the repository.
+func checkEmployee(ctx context.Context, tx domain.Tx, employee domain.Employee, opts BatchOptions) error {
+	if employee.SalaryOverride && !opts.AllowSalaryOverride {
+		return ErrSalaryOverrideForbidden
+	}
+
+	_, err := tx.Positions().Get(ctx, employee.PositionID)
+	if errors.Is(err, domain.ErrPositionNotFound) {
+		return fmt.Errorf("%w: %w", ErrInvalidOperation, err)
+	}
+	return err
+}
+
+// abort marks the items without an error of their own as aborted, created IDs of rolled back items are cleared.
+func abort(items []BatchItem) {
+	for i := range items {
+		if items[i].Err != nil {
+			continue
+		}
+		items[i].Err = ErrBatchAborted
+		if items[i].Op == OpCreate {
+			items[i].ID = ""
+		}
+	}
+}
+
+func summarize(result BatchResult) BatchResult {
+	for _, item := range result.Items {
+		if item.Err == nil {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+func TestBatcher_BatchEmployees(t *testing.T) {
+	tests := map[string]struct {
+		atomic          bool
+		expectErrors    []error
+		expectSucceeded int
+		expectStored    int
+	}{
+		"atomic": {
+			atomic:          true,
+			expectErrors:    []error{ErrBatchAborted, domain.ErrSalaryOutOfBand, ErrBatchAborted, domain.ErrEmployeeNotFound, ErrSalaryOverrideForbidden},
+			expectSucceeded: 0,
+			expectStored:    1,
+		},
+		"partial": {
+			atomic:          false,
+			expectErrors:    []error{nil, domain.ErrSalaryOutOfBand, nil, domain.ErrEmployeeNotFound, ErrSalaryOverrideForbidden},
+			expectSucceeded: 2,
+			expectStored:    1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			importer, developer := newTestImporter(t)
+			ctx := context.Background()
+
+			existing := &domain.Employee{FirstName: "Jane", LastName: "Doe", PositionID: developer.ID}
+			if err := importer.Employees.Create(ctx, existing); err != nil {
+				t.Fatal(err)
+			}
+
+			batcher := NewBatcher(importer.UnitOfWork)
+			result, err := batcher.BatchEmployees(ctx, []EmployeeOperation{
+				{Op: OpCreate, Employee: &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: developer.ID}},
+				{Op: OpCreate, Employee: &domain.Employee{FirstName: "Ann", LastName: "Lee", PositionID: developer.ID, Salary: &domain.Money{Amount: 999999, Currency: "USD"}}},
+				{Op: OpDelete, ID: existing.ID},
+				{Op: OpDelete, ID: existing.ID},
+				{Op: OpCreate, Employee: &domain.Employee{FirstName: "Bob", LastName: "Ray", PositionID: developer.ID, SalaryOverride: true}},
+			}, BatchOptions{Atomic: tt.atomic})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Succeeded != tt.expectSucceeded || result.Failed != len(tt.expectErrors)-tt.expectSucceeded {
+				t.Fatalf("expected %d succeeded items, got %+v", tt.expectSucceeded, result)
+			}
+			for i, expected := range tt.expectErrors {
+				if !errors.Is(result.Items[i].Err, expected) {
+					t.Fatalf("expected item %d error %v, got %v", i, expected, result.Items[i].Err)
+				}
+			}
+
+			stored, err := importer.Employees.GetAll(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stored) != tt.expectStored {
+				t.Fatalf("expected %d stored employees, got %d", tt.expectStored, len(stored))
+			}
+		})
+	}
+}
+
+func TestBatcher_BatchPositions_Atomic(t *testing.T) {
+	importer, developer := newTestImporter(t)
+	ctx := context.Background()
+	batcher := NewBatcher(importer.UnitOfWork)
+
+	renamed := *developer
+	renamed.Name = "Engineer"
+
+	result, err := batcher.BatchPositions(ctx, []PositionOperation{
+		{Op: OpDelete, ID: developer.ID},
+		{Op: OpUpdate, Position: &renamed},
+		{Op: OpCreate, Position: &domain.Position{Name: "Tester", Salary: domain.Money{Amount: 100000, Currency: "USD"}}},
+	}, BatchOptions{Atomic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Failed != 3 || !errors.Is(result.Items[1].Err, domain.ErrPositionNotFound) {
+		t.Fatalf("expected the update of the deleted position to fail the batch, got %+v", result)
+	}
+	if _, err := importer.Positions.Get(ctx, developer.ID); err != nil {
+		t.Fatalf("expected the position to be kept, got %v", err)
+	}
+
+	result, err = batcher.BatchPositions(ctx, []PositionOperation{
+		{Op: OpUpdate, Position: &renamed},
+		{Op: OpCreate, Position: &domain.Position{Name: "Tester", Salary: domain.Money{Amount: 100000, Currency: "USD"}}},
+	}, BatchOptions{Atomic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Succeeded != 2 || result.Items[1].ID == "" {
+		t.Fatalf("expected both items to succeed, got %+v", result)
+	}
+	if position, err := importer.Positions.Get(ctx, developer.ID); err != nil || position.Name != "Engineer" {
+		t.Fatalf("expected renamed position, got %+v %v", position, err)
+	}
+}
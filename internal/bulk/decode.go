@@ -0,0 +1,209 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+// EmployeeRow is an employee to import, the position is referenced either by ID or by name.
+type EmployeeRow struct {
+	Row          int
+	Employee     domain.Employee
+	PositionName string
+}
+
+type PositionRow struct {
+	Row      int
+	Position domain.Position
+}
+
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// employeeRecord is the NDJSON representation of an employee row.
+type employeeRecord struct {
+	domain.Employee
+	Position string `json:"position"`
+}
+
+// DecodeEmployees reads employees from CSV with a header row or from NDJSON.
+// Rows that can't be parsed are reported as row errors, an unreadable input is returned as error.
+// CSV columns: firstname, lastname, position_id, position, salary_amount, salary_currency, salary_override.
+func DecodeEmployees(r io.Reader, format Format) ([]EmployeeRow, []RowError, error) {
+	rows := make([]EmployeeRow, 0)
+	rowErrors := make([]RowError, 0)
+
+	switch format {
+	case FormatNDJSON:
+		err := decodeNDJSON(r, func(row int, line []byte) error {
+			var record employeeRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				return err
+			}
+			rows = append(rows, EmployeeRow{Row: row, Employee: record.Employee, PositionName: record.Position})
+			return nil
+		}, &rowErrors)
+		return rows, rowErrors, err
+	case FormatCSV:
+		err := decodeCSV(r, func(row int, record map[string]string) error {
+			employee := domain.Employee{
+				FirstName:  record["firstname"],
+				LastName:   record["lastname"],
+				PositionID: record["position_id"],
+			}
+
+			salary, err := parseMoney(record["salary_amount"], record["salary_currency"])
+			if err != nil {
+				return err
+			}
+			employee.Salary = salary
+
+			if value := record["salary_override"]; value != "" {
+				if employee.SalaryOverride, err = strconv.ParseBool(value); err != nil {
+					return fmt.Errorf("invalid salary_override: %w", err)
+				}
+			}
+
+			rows = append(rows, EmployeeRow{Row: row, Employee: employee, PositionName: record["position"]})
+			return nil
+		}, &rowErrors)
+		return rows, rowErrors, err
+	}
+	return nil, nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// DecodePositions reads positions from CSV with a header row or from NDJSON.
+// CSV columns: name, salary_amount, salary_currency, band_min, band_mid, band_max; band amounts use the salary currency.
+func DecodePositions(r io.Reader, format Format) ([]PositionRow, []RowError, error) {
+	rows := make([]PositionRow, 0)
+	rowErrors := make([]RowError, 0)
+
+	switch format {
+	case FormatNDJSON:
+		err := decodeNDJSON(r, func(row int, line []byte) error {
+			var position domain.Position
+			if err := json.Unmarshal(line, &position); err != nil {
+				return err
+			}
+			rows = append(rows, PositionRow{Row: row, Position: position})
+			return nil
+		}, &rowErrors)
+		return rows, rowErrors, err
+	case FormatCSV:
+		err := decodeCSV(r, func(row int, record map[string]string) error {
+			position := domain.Position{Name: record["name"]}
+
+			salary, err := parseMoney(record["salary_amount"], record["salary_currency"])
+			if err != nil {
+				return err
+			}
+			if salary != nil {
+				position.Salary = *salary
+			}
+
+			if record["band_min"] != "" || record["band_mid"] != "" || record["band_max"] != "" {
+				var band domain.SalaryBand
+				for _, field := range []struct {
+					name  string
+					money *domain.Money
+				}{{"band_min", &band.Min}, {"band_mid", &band.Mid}, {"band_max", &band.Max}} {
+					amount, err := strconv.ParseInt(record[field.name], 10, 64)
+					if err != nil {
+						return fmt.Errorf("invalid %s: %w", field.name, err)
+					}
+					*field.money = domain.Money{Amount: amount, Currency: position.Salary.Currency}
+				}
+				position.Band = &band
+			}
+
+			rows = append(rows, PositionRow{Row: row, Position: position})
+			return nil
+		}, &rowErrors)
+		return rows, rowErrors, err
+	}
+	return nil, nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// decodeNDJSON calls decode for every JSON value of the input, values are numbered from 1.
+func decodeNDJSON(r io.Reader, decode func(row int, line []byte) error, rowErrors *[]RowError) error {
+	decoder := json.NewDecoder(r)
+	for row := 1; ; row++ {
+		var line json.RawMessage
+		err := decoder.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading row %d: %w", row, err)
+		}
+
+		if err := decode(row, line); err != nil {
+			*rowErrors = append(*rowErrors, RowError{Row: row, Error: err.Error()})
+		}
+	}
+}
+
+// decodeCSV calls decode for every record keyed by the lowercase header names, the header is row 1.
+func decodeCSV(r io.Reader, decode func(row int, record map[string]string) error, rowErrors *[]RowError) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	reader.FieldsPerRecord = len(header)
+
+	for row := 2; ; row++ {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			*rowErrors = append(*rowErrors, RowError{Row: row, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading row %d: %w", row, err)
+		}
+
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			record[name] = strings.TrimSpace(fields[i])
+		}
+
+		if err := decode(row, record); err != nil {
+			*rowErrors = append(*rowErrors, RowError{Row: row, Error: err.Error()})
+		}
+	}
+}
+
+// parseMoney returns nil when both the amount and the currency are empty.
+func parseMoney(amount, currency string) (*domain.Money, error) {
+	if amount == "" && currency == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salary_amount: %w", err)
+	}
+	return &domain.Money{Amount: value, Currency: strings.ToUpper(currency)}, nil
+}
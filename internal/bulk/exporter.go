@@ -0,0 +1,177 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+// flushEvery is the number of rows written between flushes of the export output.
+const flushEvery = 100
+
+var (
+	// EmployeeColumns are the exportable employee columns, they are also accepted by DecodeEmployees.
+	EmployeeColumns = []string{"id", "firstname", "lastname", "position_id", "position", "salary_amount", "salary_currency", "salary_override", "compa_ratio"}
+	// PositionColumns are the exportable position columns, they are also accepted by DecodePositions.
+	PositionColumns = []string{"id", "name", "salary_amount", "salary_currency", "band_min", "band_mid", "band_max"}
+
+	ErrUnknownColumn = errors.New("unknown column")
+)
+
+type Exporter struct {
+	Employees domain.EmployeesRepository
+	Positions domain.PositionsRepository
+}
+
+func NewExporter(employees domain.EmployeesRepository, positions domain.PositionsRepository) *Exporter {
+	return &Exporter{Employees: employees, Positions: positions}
+}
+
+// ParseColumns parses a comma separated list of columns, an empty list selects all available columns.
+func ParseColumns(value string, available []string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return available, nil
+	}
+
+	columns := make([]string, 0)
+	for _, column := range strings.Split(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !contains(available, column) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, column)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// ExportEmployees writes the employees joined with their position name one row at a time.
+func (e *Exporter) ExportEmployees(ctx context.Context, w RowWriter, columns []string) error {
+	names, err := e.positionNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := 0
+	err = e.Employees.Iterate(ctx, func(employee domain.Employee) error {
+		values := make([]any, len(columns))
+		for i, column := range columns {
+			values[i] = employeeValue(employee, names[employee.PositionID], column)
+		}
+
+		if err := w.Write(values); err != nil {
+			return err
+		}
+		if rows++; rows%flushEvery == 0 {
+			return w.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error to export employees: %w", err)
+	}
+	return w.Close()
+}
+
+// ExportPositions writes the positions one row at a time.
+func (e *Exporter) ExportPositions(ctx context.Context, w RowWriter, columns []string) error {
+	rows := 0
+	err := e.Positions.Iterate(ctx, func(position domain.Position) error {
+		values := make([]any, len(columns))
+		for i, column := range columns {
+			values[i] = positionValue(position, column)
+		}
+
+		if err := w.Write(values); err != nil {
+			return err
+		}
+		if rows++; rows%flushEvery == 0 {
+			return w.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error to export positions: %w", err)
+	}
+	return w.Close()
+}
+
+func (e *Exporter) positionNames(ctx context.Context) (map[string]string, error) {
+	positions, err := e.Positions.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error to get positions: %w", err)
+	}
+
+	names := make(map[string]string, len(positions))
+	for _, position := range positions {
+		names[position.ID] = position.Name
+	}
+	return names, nil
+}
+
+func employeeValue(employee domain.Employee, positionName, column string) any {
+	switch column {
+	case "id":
+		return employee.ID
+	case "firstname":
+		return employee.FirstName
+	case "lastname":
+		return employee.LastName
+	case "position_id":
+		return employee.PositionID
+	case "position":
+		return positionName
+	case "salary_amount":
+		if employee.Salary != nil {
+			return employee.Salary.Amount
+		}
+	case "salary_currency":
+		if employee.Salary != nil {
+			return employee.Salary.Currency
+		}
+	case "salary_override":
+		return employee.SalaryOverride
+	case "compa_ratio":
+		if employee.CompaRatio != 0 {
+			return employee.CompaRatio
+		}
+	}
+	return nil
+}
+
+func positionValue(position domain.Position, column string) any {
+	switch column {
+	case "id":
+		return position.ID
+	case "name":
+		return position.Name
+	case "salary_amount":
+		return position.Salary.Amount
+	case "salary_currency":
+		return position.Salary.Currency
+	case "band_min":
+		if position.Band != nil {
+			return position.Band.Min.Amount
+		}
+	case "band_mid":
+		if position.Band != nil {
+			return position.Band.Mid.Amount
+		}
+	case "band_max":
+		if position.Band != nil {
+			return position.Band.Max.Amount
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package bulk
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+func newTestExporter(t *testing.T) *Exporter {
+	t.Helper()
+
+	importer, developer := newTestImporter(t)
+	for _, employee := range []*domain.Employee{
+		{FirstName: "John", LastName: "Smith", PositionID: developer.ID, Salary: &domain.Money{Amount: 150000, Currency: "USD"}},
+		{FirstName: "Jane", LastName: "Doe", PositionID: developer.ID},
+	} {
+		if err := importer.Employees.Create(context.Background(), employee); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return NewExporter(importer.Employees, importer.Positions)
+}
+
+func TestExporter_ExportEmployees(t *testing.T) {
+	columns := []string{"firstname", "position", "salary_amount", "compa_ratio"}
+
+	tests := map[string]struct {
+		format       Format
+		expectedRows []string
+	}{
+		"csv": {
+			format: FormatCSV,
+			expectedRows: []string{
+				"firstname,position,salary_amount,compa_ratio",
+				"John,Developer,150000,0.75",
+				"Jane,Developer,,",
+			},
+		},
+		"ndjson": {
+			format: FormatNDJSON,
+			expectedRows: []string{
+				`{"firstname":"John","position":"Developer","salary_amount":150000,"compa_ratio":0.75}`,
+				`{"firstname":"Jane","position":"Developer","salary_amount":null,"compa_ratio":null}`,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			exporter := newTestExporter(t)
+
+			var buf bytes.Buffer
+			writer, err := NewRowWriter(&buf, tt.format, columns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exporter.ExportEmployees(context.Background(), writer, columns); err != nil {
+				t.Fatal(err)
+			}
+
+			rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(rows) != len(tt.expectedRows) {
+				t.Fatalf("expected rows %q, got %q", tt.expectedRows, rows)
+			}
+			for _, expected := range tt.expectedRows {
+				if !strings.Contains(buf.String(), expected+"\n") {
+					t.Fatalf("expected row %q in %q", expected, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExporter_ExportPositions_XLSX(t *testing.T) {
+	exporter := newTestExporter(t)
+
+	var buf bytes.Buffer
+	writer, err := NewRowWriter(&buf, FormatXLSX, PositionColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.ExportPositions(context.Background(), writer, PositionColumns); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sheet []byte
+	for _, file := range archive.File {
+		if file.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		f, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheet, err = io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, expected := range []string{`<row r="1">`, `<t xml:space="preserve">Developer</t>`, `<c t="n"><v>300000</v></c>`} {
+		if !bytes.Contains(sheet, []byte(expected)) {
+			t.Fatalf("expected %q in sheet %s", expected, sheet)
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns(" Name, id", PositionColumns)
+	if err != nil || strings.Join(columns, ",") != "name,id" {
+		t.Fatalf("expected name,id, got %v %v", columns, err)
+	}
+
+	if _, err := ParseColumns("name,secret", PositionColumns); !errors.Is(err, ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn, got %v", err)
+	}
+}
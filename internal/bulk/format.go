@@ -0,0 +1,34 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ParseFormat accepts a format name or a media type.
+func ParseFormat(value string) (Format, error) {
+	if mediaType, _, err := mime.ParseMediaType(value); err == nil {
+		value = mediaType
+	}
+
+	switch value {
+	case "csv", "text/csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl", "application/x-ndjson", "application/jsonl", "application/json-seq":
+		return FormatNDJSON, nil
+	case "xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, value)
+}
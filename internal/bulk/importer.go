@@ -0,0 +1,225 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/search"
+)
+
+type ImportOptions struct {
+	// DryRun validates the rows without importing them.
+	DryRun bool
+	// BestEffort imports every valid row, otherwise nothing is imported if any row is invalid.
+	BestEffort bool
+	// AllowSalaryOverride permits rows with salary_override, it must only be set for privileged callers.
+	AllowSalaryOverride bool
+}
+
+type ImportResult struct {
+	Total    int        `json:"total"`
+	Imported int        `json:"imported"`
+	DryRun   bool       `json:"dry_run"`
+	IDs      []string   `json:"ids"`
+	Errors   []RowError `json:"errors"`
+}
+
+type Importer struct {
+	Employees  domain.EmployeesRepository
+	Positions  domain.PositionsRepository
+	UnitOfWork domain.UnitOfWork
+}
+
+func NewImporter(employees domain.EmployeesRepository, positions domain.PositionsRepository, uow domain.UnitOfWork) *Importer {
+	return &Importer{Employees: employees, Positions: positions, UnitOfWork: uow}
+}
+
+// ImportEmployees validates all rows and imports them according to the options.
+// rowErrors are decoding errors of rows that are not part of rows, they count towards the total.
+// Without BestEffort the import is all-or-nothing: the employees are created in a single transaction.
+func (i *Importer) ImportEmployees(ctx context.Context, rows []EmployeeRow, rowErrors []RowError, opts ImportOptions) (ImportResult, error) {
+	result := ImportResult{
+		Total:  len(rows) + len(rowErrors),
+		DryRun: opts.DryRun,
+		IDs:    make([]string, 0),
+		Errors: append(make([]RowError, 0), rowErrors...),
+	}
+
+	positionsByName, err := i.positionsByName(ctx)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	valid := make([]EmployeeRow, 0, len(rows))
+	for _, row := range rows {
+		if err := i.validateEmployee(ctx, &row, positionsByName, opts); err != nil {
+			result.Errors = append(result.Errors, RowError{Row: row.Row, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	if opts.DryRun || (!opts.BestEffort && len(result.Errors) > 0) {
+		return result, nil
+	}
+
+	if opts.BestEffort {
+		for _, row := range valid {
+			employee := row.Employee
+			if err := i.Employees.Create(ctx, &employee); err != nil {
+				result.Errors = append(result.Errors, RowError{Row: row.Row, Error: err.Error()})
+				continue
+			}
+			result.IDs = append(result.IDs, employee.ID)
+			result.Imported++
+		}
+		return result, nil
+	}
+
+	err = i.UnitOfWork.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		for _, row := range valid {
+			employee := row.Employee
+			if err := tx.Employees().Create(ctx, &employee); err != nil {
+				result.Errors = append(result.Errors, RowError{Row: row.Row, Error: err.Error()})
+				return errImportFailed
+			}
+			result.IDs = append(result.IDs, employee.ID)
+		}
+		return nil
+	})
+	return atomicResult(result, err)
+}
+
+// ImportPositions validates all rows and imports them according to the options, see ImportEmployees.
+func (i *Importer) ImportPositions(ctx context.Context, rows []PositionRow, rowErrors []RowError, opts ImportOptions) (ImportResult, error) {
+	result := ImportResult{
+		Total:  len(rows) + len(rowErrors),
+		DryRun: opts.DryRun,
+		IDs:    make([]string, 0),
+		Errors: append(make([]RowError, 0), rowErrors...),
+	}
+
+	valid := make([]PositionRow, 0, len(rows))
+	for _, row := range rows {
+		if err := validatePosition(row.Position); err != nil {
+			result.Errors = append(result.Errors, RowError{Row: row.Row, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	if opts.DryRun || (!opts.BestEffort && len(result.Errors) > 0) {
+		return result, nil
+	}
+
+	if opts.BestEffort {
+		for _, row := range valid {
+			position := row.Position
+			if err := i.Positions.Create(ctx, &position); err != nil {
+				result.Errors = append(result.Errors, RowError{Row: row.Row, Error: err.Error()})
+				continue
+			}
+			result.IDs = append(result.IDs, position.ID)
+			result.Imported++
+		}
+		return result, nil
+	}
+
+	err := i.UnitOfWork.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		for _, row := range valid {
+			position := row.Position
+			if err := tx.Positions().Create(ctx, &position); err != nil {
+				result.Errors = append(result.Errors, RowError{Row: row.Row, Error: err.Error()})
+				return errImportFailed
+			}
+			result.IDs = append(result.IDs, position.ID)
+		}
+		return nil
+	})
+	return atomicResult(result, err)
+}
+
+// errImportFailed rolls back the transaction of an all-or-nothing import with a failed row.
+var errImportFailed = errors.New("import failed")
+
+// atomicResult completes the result of an all-or-nothing import from the error of its transaction.
+func atomicResult(result ImportResult, err error) (ImportResult, error) {
+	if errors.Is(err, errImportFailed) {
+		result.IDs = make([]string, 0)
+		return result, nil
+	}
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("error to import: %w", err)
+	}
+
+	result.Imported = len(result.IDs)
+	return result, nil
+}
+
+// validateEmployee resolves the position of the row by name if no ID is given and checks the salary against its band.
+func (i *Importer) validateEmployee(ctx context.Context, row *EmployeeRow, positionsByName map[string][]string, opts ImportOptions) error {
+	employee := &row.Employee
+	employee.ID = ""
+	employee.CompaRatio = 0
+
+	if strings.TrimSpace(employee.FirstName) == "" || strings.TrimSpace(employee.LastName) == "" {
+		return errors.New("firstname and lastname are required")
+	}
+
+	if employee.PositionID == "" {
+		if row.PositionName == "" {
+			return errors.New("position_id or position is required")
+		}
+
+		ids := positionsByName[search.Normalize(strings.TrimSpace(row.PositionName))]
+		switch len(ids) {
+		case 0:
+			return fmt.Errorf("position %q not found", row.PositionName)
+		case 1:
+			employee.PositionID = ids[0]
+		default:
+			return fmt.Errorf("position name %q is ambiguous", row.PositionName)
+		}
+	}
+
+	position, err := i.Positions.Get(ctx, employee.PositionID)
+	if err != nil {
+		return err
+	}
+
+	if employee.SalaryOverride && !opts.AllowSalaryOverride {
+		return fmt.Errorf("salary override requires %s role", domain.RoleCompensationAdmin)
+	}
+	return position.CheckSalary(*employee)
+}
+
+func validatePosition(position domain.Position) error {
+	if strings.TrimSpace(position.Name) == "" {
+		return errors.New("name is required")
+	}
+	if err := position.Salary.Validate(); err != nil {
+		return fmt.Errorf("invalid salary: %w", err)
+	}
+	if position.Band != nil {
+		return position.Band.Validate()
+	}
+	return nil
+}
+
+// positionsByName maps normalized position names to the IDs of positions with that name.
+func (i *Importer) positionsByName(ctx context.Context) (map[string][]string, error) {
+	positions, err := i.Positions.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error to get positions: %w", err)
+	}
+
+	byName := make(map[string][]string, len(positions))
+	for _, position := range positions {
+		name := search.Normalize(strings.TrimSpace(position.Name))
+		byName[name] = append(byName[name], position.ID)
+	}
+	return byName, nil
+}
@@ -0,0 +1,156 @@
+package bulk
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+)
+
+func newTestImporter(t *testing.T) (*Importer, *domain.Position) {
+	t.Helper()
+
+	store := repository.NewStore(nil)
+	positions := position.NewPositionsRepository(store)
+	developer := &domain.Position{
+		Name:   "Developer",
+		Salary: domain.Money{Amount: 200000, Currency: "USD"},
+		Band: &domain.SalaryBand{
+			Min: domain.Money{Amount: 100000, Currency: "USD"},
+			Mid: domain.Money{Amount: 200000, Currency: "USD"},
+			Max: domain.Money{Amount: 300000, Currency: "USD"},
+		},
+	}
+	if err := positions.Create(context.Background(), developer); err != nil {
+		t.Fatal(err)
+	}
+
+	employees := employee.NewEmployeesRepository(store, positions)
+	return NewImporter(employees, positions, repository.NewUnitOfWork(store, employees, positions)), developer
+}
+
+func TestImporter_ImportEmployees(t *testing.T) {
+	const csvInput = `firstname,lastname,position_id,position,salary_amount,salary_currency
+John,Smith,,developer,150000,usd
+Jane,Doe,,Designer,,
+Ann,Lee,,Developer,999999,USD
+`
+
+	tests := map[string]struct {
+		opts           ImportOptions
+		expectImported int
+		expectErrors   []int
+		expectStored   int
+	}{
+		"atomic": {
+			opts:           ImportOptions{},
+			expectImported: 0,
+			expectErrors:   []int{3, 4},
+			expectStored:   0,
+		},
+		"best effort": {
+			opts:           ImportOptions{BestEffort: true},
+			expectImported: 1,
+			expectErrors:   []int{3, 4},
+			expectStored:   1,
+		},
+		"dry run": {
+			opts:           ImportOptions{DryRun: true, BestEffort: true},
+			expectImported: 0,
+			expectErrors:   []int{3, 4},
+			expectStored:   0,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			importer, _ := newTestImporter(t)
+
+			rows, rowErrors, err := DecodeEmployees(strings.NewReader(csvInput), FormatCSV)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := importer.ImportEmployees(context.Background(), rows, rowErrors, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Total != 3 || result.Imported != tt.expectImported {
+				t.Fatalf("expected 3 total and %d imported, got %+v", tt.expectImported, result)
+			}
+			if len(result.Errors) != len(tt.expectErrors) {
+				t.Fatalf("expected errors at rows %v, got %+v", tt.expectErrors, result.Errors)
+			}
+			for i, row := range tt.expectErrors {
+				if result.Errors[i].Row != row {
+					t.Fatalf("expected errors at rows %v, got %+v", tt.expectErrors, result.Errors)
+				}
+			}
+
+			stored, err := importer.Employees.GetAll(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stored) != tt.expectStored {
+				t.Fatalf("expected %d stored employees, got %d", tt.expectStored, len(stored))
+			}
+		})
+	}
+}
+
+func TestDecodeEmployees_NDJSON(t *testing.T) {
+	input := `{"firstname":"John","lastname":"Smith","position":"Developer","salary":{"amount":150000,"currency":"USD"}}
+
+{"firstname":"Jane","lastname":"Doe","position_id":"id"}
+["not an object"]
+`
+
+	rows, rowErrors, err := DecodeEmployees(strings.NewReader(input), FormatNDJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 || rows[0].PositionName != "Developer" || rows[0].Employee.Salary == nil || rows[1].Employee.PositionID != "id" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if len(rowErrors) != 1 || rowErrors[0].Row != 3 {
+		t.Fatalf("expected an error at row 3, got %+v", rowErrors)
+	}
+}
+
+func TestImporter_ImportPositions(t *testing.T) {
+	const csvInput = `name,salary_amount,salary_currency,band_min,band_mid,band_max
+Tester,100000,USD,80000,100000,120000
+Manager,100000,XYZ,,,
+,100000,USD,,,
+`
+
+	importer, _ := newTestImporter(t)
+
+	rows, rowErrors, err := DecodePositions(strings.NewReader(csvInput), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := importer.ImportPositions(context.Background(), rows, rowErrors, ImportOptions{BestEffort: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Imported != 1 || len(result.Errors) != 2 {
+		t.Fatalf("expected 1 imported position and 2 errors, got %+v", result)
+	}
+
+	imported, err := importer.Positions.Get(context.Background(), result.IDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Band == nil || imported.Band.Max.Amount != 120000 {
+		t.Fatalf("expected imported band, got %+v", imported)
+	}
+}
@@ -2,9 +2,16 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/dilyara4949/employees-api/internal/auth"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	"github.com/dilyara4949/employees-api/internal/tenant"
 )
 
 type Config struct {
@@ -12,6 +19,47 @@ type Config struct {
 	RestPort       string
 	GrpcPort       string
 	Address        string
+	// ExchangeRatesFile is an optional path to a JSON exchange-rate table used for salary reports.
+	ExchangeRatesFile string
+	// EventLogSize is the number of change events kept for subscribers resuming a stream.
+	EventLogSize int
+	// OutboxStream is the Redis Stream the outbox relay publishes change events to.
+	OutboxStream string
+	// IdempotencyTTL is how long responses are kept for requests retried with the same Idempotency-Key.
+	IdempotencyTTL time.Duration
+	// RateLimit is the per client limit of requests per minute, with overrides for routes, gRPC methods and tenants.
+	// Routes are keyed without version prefix, their aliases share the limit.
+	RateLimit ratelimit.Policy
+	// RateLimitRedis shares the rate limits of all instances through Redis.
+	RateLimitRedis bool
+	// Deprecations are the deprecated route patterns, e.g. "GET /employees", answered with Deprecation and Sunset headers.
+	// A route without version prefix also deprecates its /v1 and /v2 aliases.
+	Deprecations map[string]middleware.Deprecation
+	// OpenAPIValidation validates requests and responses against the OpenAPI document.
+	OpenAPIValidation bool
+	// GatewayPrefix is the path the gRPC services are served under as JSON/HTTP, e.g. "/gateway". The gateway is
+	// disabled if it is empty.
+	GatewayPrefix string
+	// AuthUsersFile is an optional JSON file of the users allowed to log in at /auth/token.
+	AuthUsersFile string
+	// Tokens configures the access and refresh tokens issued at /auth/token.
+	Tokens auth.TokenConfig
+	// JWKS is a file or http(s) URL of the JSON Web Key Set verifying RS256, ES256 and EdDSA signed tokens, e.g. of
+	// an identity provider. Only the tokens signed with JWTTokenSecret are accepted if it is empty.
+	JWKS string
+	// JWKSRefresh is how often the JWKS is loaded again to pick up rotated keys.
+	JWKSRefresh time.Duration
+	// JWTIssuers are the accepted "iss" claims of the tokens, and JWTAudience must be in their "aud" claim. Neither
+	// is checked if empty, AUTH_ISSUER and AUTH_AUDIENCE must be accepted to keep accepting the issued tokens.
+	JWTIssuers  []string
+	JWTAudience string
+	// TenantClaim is the JWT claim carrying the tenant of the caller, every token acts for the default tenant if it
+	// is empty. Tokens without the claim are rejected unless DefaultTenantFallback is set.
+	TenantClaim           string
+	DefaultTenantFallback bool
+	// GrpcAllowAnonymous lets gRPC calls without bearer token and API key through for the default tenant, they are
+	// rejected with Unauthenticated otherwise.
+	GrpcAllowAnonymous bool
 	RedisConfig
 }
 
@@ -26,19 +74,30 @@ type RedisConfig struct {
 }
 
 const (
-	defaultRedisTimeout  = 10
-	defaultRedisDB       = 0
-	defaultRedisPoolSize = 10
-	defaultRedisTtl      = 5
+	defaultRedisTimeout    = 10
+	defaultRedisDB         = 0
+	defaultRedisPoolSize   = 10
+	defaultRedisTtl        = 5
+	defaultEventLogSize    = 1000
+	defaultOutboxStream    = "employees-api:events"
+	defaultIdempotencyTTL  = 24
+	defaultRateLimit       = 600
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	defaultJWKSRefresh     = time.Hour
 )
 
 var (
-	errMissingPort           = errors.New("PORT is empty")
+	errMissingRestPort       = errors.New("REST_PORT is empty")
+	errMissingGrpcPort       = errors.New("GRPC_PORT is empty")
 	errMissingAddress        = errors.New("ADDRESS is empty")
 	errMissingJWTTokenSecret = errors.New("JWT_TOKEN_SECRET is empty")
 	errMissingRedisHost      = errors.New("REDIS_HOST is empty")
 	errMissingRedisPort      = errors.New("REDIS_PORT is empty")
 	errMissingRedisPass      = errors.New("REDIS_PASSWORD is empty")
+	errInvalidGatewayPrefix  = errors.New("GATEWAY_PREFIX must start with /")
+	errInvalidTokenTTL       = errors.New("AUTH_ACCESS_TOKEN_TTL and AUTH_REFRESH_TOKEN_TTL must be positive durations")
+	errInvalidJWKSRefresh    = errors.New("JWKS_REFRESH must be a positive duration")
 )
 
 func NewConfig() (Config, error) {
@@ -46,22 +105,104 @@ func NewConfig() (Config, error) {
 
 	jwtTokenSecret := os.Getenv("JWT_TOKEN_SECRET")
 	if jwtTokenSecret == "" {
-		return Config{}, errors.New("JWT_TOKEN_SECRET is empty")
+		return Config{}, errMissingJWTTokenSecret
 	}
 
 	restPort := os.Getenv("REST_PORT")
 	if restPort == "" {
-		return Config{}, errors.New("REST_PORT is empty")
+		return Config{}, errMissingRestPort
 	}
 
 	grpcPort := os.Getenv("GRPC_PORT")
 	if grpcPort == "" {
-		return Config{}, errors.New("GRPC_PORT is empty")
+		return Config{}, errMissingGrpcPort
 	}
 
 	address := os.Getenv("ADDRESS")
 	if address == "" {
-		return Config{}, errors.New("ADDRESS is empty")
+		return Config{}, errMissingAddress
+	}
+
+	exchangeRatesFile := os.Getenv("EXCHANGE_RATES_FILE")
+
+	eventLogSize, err := strconv.Atoi(os.Getenv("EVENT_LOG_SIZE"))
+	if err != nil || eventLogSize <= 0 {
+		eventLogSize = defaultEventLogSize
+	}
+
+	idempotencyTTL, err := strconv.Atoi(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil || idempotencyTTL <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+
+	rateLimit, err := strconv.Atoi(os.Getenv("RATE_LIMIT"))
+	if err != nil || rateLimit <= 0 {
+		rateLimit = defaultRateLimit
+	}
+
+	rateLimitBurst, err := strconv.Atoi(os.Getenv("RATE_LIMIT_BURST"))
+	if err != nil || rateLimitBurst < 0 {
+		rateLimitBurst = 0
+	}
+
+	rateLimitRoutes, err := ratelimit.ParseLimits(os.Getenv("RATE_LIMIT_ROUTES"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	rateLimitTenants, err := ratelimit.ParseLimits(os.Getenv("RATE_LIMIT_TENANTS"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for id := range rateLimitTenants {
+		if err := tenant.Validate(id); err != nil {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_TENANTS: %w %q", err, id))
+		}
+	}
+
+	rateLimitRedis, _ := strconv.ParseBool(os.Getenv("RATE_LIMIT_REDIS"))
+
+	deprecations, err := middleware.ParseDeprecations(os.Getenv("DEPRECATIONS"))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	openAPIValidation, _ := strconv.ParseBool(os.Getenv("OPENAPI_VALIDATION"))
+
+	grpcAllowAnonymous, _ := strconv.ParseBool(os.Getenv("GRPC_ALLOW_ANONYMOUS"))
+
+	gatewayPrefix := strings.TrimSuffix(os.Getenv("GATEWAY_PREFIX"), "/")
+	if gatewayPrefix != "" && !strings.HasPrefix(gatewayPrefix, "/") {
+		errs = append(errs, errInvalidGatewayPrefix)
+	}
+
+	accessTokenTTL, err := parseDuration(os.Getenv("AUTH_ACCESS_TOKEN_TTL"), defaultAccessTokenTTL, errInvalidTokenTTL)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	refreshTokenTTL, err := parseDuration(os.Getenv("AUTH_REFRESH_TOKEN_TTL"), defaultRefreshTokenTTL, errInvalidTokenTTL)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	jwksRefresh, err := parseDuration(os.Getenv("JWKS_REFRESH"), defaultJWKSRefresh, errInvalidJWKSRefresh)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	var jwtIssuers []string
+	for _, issuer := range strings.Split(os.Getenv("JWT_ISSUERS"), ",") {
+		if issuer = strings.TrimSpace(issuer); issuer != "" {
+			jwtIssuers = append(jwtIssuers, issuer)
+		}
+	}
+
+	defaultTenantFallback, _ := strconv.ParseBool(os.Getenv("DEFAULT_TENANT_FALLBACK"))
+
+	outboxStream := os.Getenv("OUTBOX_STREAM")
+	if outboxStream == "" {
+		outboxStream = defaultOutboxStream
 	}
 
 	redisHost := os.Getenv("REDIS_HOST")
@@ -104,10 +245,37 @@ func NewConfig() (Config, error) {
 	}
 
 	cfg := Config{
-		JWTTokenSecret: jwtTokenSecret,
-		RestPort:       restPort,
-		GrpcPort:       grpcPort,
-		Address:        address,
+		JWTTokenSecret:    jwtTokenSecret,
+		RestPort:          restPort,
+		GrpcPort:          grpcPort,
+		Address:           address,
+		ExchangeRatesFile: exchangeRatesFile,
+		EventLogSize:      eventLogSize,
+		OutboxStream:      outboxStream,
+		IdempotencyTTL:    time.Duration(idempotencyTTL) * time.Hour,
+		RateLimit: ratelimit.Policy{
+			Default: ratelimit.Limit{Requests: rateLimit, Period: time.Minute, Burst: rateLimitBurst},
+			Routes:  rateLimitRoutes,
+			Tenants: rateLimitTenants,
+		},
+		RateLimitRedis:    rateLimitRedis,
+		Deprecations:      deprecations,
+		OpenAPIValidation: openAPIValidation,
+		GatewayPrefix:     gatewayPrefix,
+		AuthUsersFile:     os.Getenv("AUTH_USERS_FILE"),
+		Tokens: auth.TokenConfig{
+			Issuer:     os.Getenv("AUTH_ISSUER"),
+			Audience:   os.Getenv("AUTH_AUDIENCE"),
+			AccessTTL:  accessTokenTTL,
+			RefreshTTL: refreshTokenTTL,
+		},
+		JWKS:                  os.Getenv("JWKS"),
+		JWKSRefresh:           jwksRefresh,
+		JWTIssuers:            jwtIssuers,
+		JWTAudience:           os.Getenv("JWT_AUDIENCE"),
+		TenantClaim:           os.Getenv("TENANT_CLAIM"),
+		DefaultTenantFallback: defaultTenantFallback,
+		GrpcAllowAnonymous:    grpcAllowAnonymous,
 		RedisConfig: RedisConfig{
 			Host:     redisHost,
 			Port:     redisPort,
@@ -121,3 +289,16 @@ func NewConfig() (Config, error) {
 
 	return cfg, nil
 }
+
+// parseDuration parses a positive duration like "15m", an empty value is the fallback.
+func parseDuration(value string, fallback time.Duration, invalid error) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, invalid
+	}
+	return d, nil
+}
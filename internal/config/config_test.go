@@ -2,9 +2,14 @@ package config
 
 import (
 	"errors"
-	"fmt"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/auth"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	"github.com/dilyara4949/employees-api/internal/tenant"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -19,12 +24,136 @@ func TestNewConfig(t *testing.T) {
 			input: map[string]string{
 				"ADDRESS":          "address",
 				"REST_PORT":        "restport",
+				"GRPC_PORT":        "grpcport",
+				"JWT_TOKEN_SECRET": "secret",
+				"REDIS_HOST":       "localhost",
+				"REDIS_PORT":       "6379",
+				"REDIS_PASSWORD":   "pass",
+			},
+			want: Config{
+				Address:        "address",
+				RestPort:       "restport",
+				GrpcPort:       "grpcport",
+				JWTTokenSecret: "secret",
+				EventLogSize:   defaultEventLogSize,
+				OutboxStream:   defaultOutboxStream,
+				IdempotencyTTL: defaultIdempotencyTTL * time.Hour,
+				RateLimit: ratelimit.Policy{
+					Default: ratelimit.Limit{Requests: defaultRateLimit, Period: time.Minute},
+					Routes:  map[string]ratelimit.Limit{},
+					Tenants: map[string]ratelimit.Limit{},
+				},
+				Deprecations: map[string]middleware.Deprecation{},
+				Tokens: auth.TokenConfig{
+					AccessTTL:  defaultAccessTokenTTL,
+					RefreshTTL: defaultRefreshTokenTTL,
+				},
+				JWKSRefresh: defaultJWKSRefresh,
+				RedisConfig: RedisConfig{
+					Host:     "localhost",
+					Port:     "6379",
+					Password: "pass",
+					Timeout:  defaultRedisTimeout * time.Second,
+					PoolSize: defaultRedisPoolSize,
+					Database: defaultRedisDB,
+					Ttl:      defaultRedisTtl * time.Hour,
+				},
+			},
+		},
+		{
+			name: "jwks",
+			input: map[string]string{
+				"ADDRESS":          "address",
+				"REST_PORT":        "restport",
+				"GRPC_PORT":        "grpcport",
 				"JWT_TOKEN_SECRET": "secret",
+				"REDIS_HOST":       "localhost",
+				"REDIS_PORT":       "6379",
+				"REDIS_PASSWORD":   "pass",
+				"JWKS":             "https://idp.example.com/.well-known/jwks.json",
+				"JWKS_REFRESH":     "10m",
+				"JWT_ISSUERS":      "https://idp.example.com/, employees-api",
+				"JWT_AUDIENCE":     "employees",
 			},
 			want: Config{
 				Address:        "address",
 				RestPort:       "restport",
+				GrpcPort:       "grpcport",
 				JWTTokenSecret: "secret",
+				EventLogSize:   defaultEventLogSize,
+				OutboxStream:   defaultOutboxStream,
+				IdempotencyTTL: defaultIdempotencyTTL * time.Hour,
+				RateLimit: ratelimit.Policy{
+					Default: ratelimit.Limit{Requests: defaultRateLimit, Period: time.Minute},
+					Routes:  map[string]ratelimit.Limit{},
+					Tenants: map[string]ratelimit.Limit{},
+				},
+				Deprecations: map[string]middleware.Deprecation{},
+				Tokens: auth.TokenConfig{
+					AccessTTL:  defaultAccessTokenTTL,
+					RefreshTTL: defaultRefreshTokenTTL,
+				},
+				JWKS:        "https://idp.example.com/.well-known/jwks.json",
+				JWKSRefresh: 10 * time.Minute,
+				JWTIssuers:  []string{"https://idp.example.com/", "employees-api"},
+				JWTAudience: "employees",
+				RedisConfig: RedisConfig{
+					Host:     "localhost",
+					Port:     "6379",
+					Password: "pass",
+					Timeout:  defaultRedisTimeout * time.Second,
+					PoolSize: defaultRedisPoolSize,
+					Database: defaultRedisDB,
+					Ttl:      defaultRedisTtl * time.Hour,
+				},
+			},
+		},
+		{
+			name: "tenants",
+			input: map[string]string{
+				"ADDRESS":                 "address",
+				"REST_PORT":               "restport",
+				"GRPC_PORT":               "grpcport",
+				"JWT_TOKEN_SECRET":        "secret",
+				"REDIS_HOST":              "localhost",
+				"REDIS_PORT":              "6379",
+				"REDIS_PASSWORD":          "pass",
+				"TENANT_CLAIM":            "org",
+				"RATE_LIMIT_TENANTS":      "acme=6000:500",
+				"GRPC_ALLOW_ANONYMOUS":    "true",
+				"DEFAULT_TENANT_FALLBACK": "true",
+			},
+			want: Config{
+				Address:        "address",
+				RestPort:       "restport",
+				GrpcPort:       "grpcport",
+				JWTTokenSecret: "secret",
+				EventLogSize:   defaultEventLogSize,
+				OutboxStream:   defaultOutboxStream,
+				IdempotencyTTL: defaultIdempotencyTTL * time.Hour,
+				RateLimit: ratelimit.Policy{
+					Default: ratelimit.Limit{Requests: defaultRateLimit, Period: time.Minute},
+					Routes:  map[string]ratelimit.Limit{},
+					Tenants: map[string]ratelimit.Limit{"acme": {Requests: 6000, Period: time.Minute, Burst: 500}},
+				},
+				Deprecations: map[string]middleware.Deprecation{},
+				Tokens: auth.TokenConfig{
+					AccessTTL:  defaultAccessTokenTTL,
+					RefreshTTL: defaultRefreshTokenTTL,
+				},
+				JWKSRefresh:           defaultJWKSRefresh,
+				TenantClaim:           "org",
+				DefaultTenantFallback: true,
+				GrpcAllowAnonymous:    true,
+				RedisConfig: RedisConfig{
+					Host:     "localhost",
+					Port:     "6379",
+					Password: "pass",
+					Timeout:  defaultRedisTimeout * time.Second,
+					PoolSize: defaultRedisPoolSize,
+					Database: defaultRedisDB,
+					Ttl:      defaultRedisTtl * time.Hour,
+				},
 			},
 		},
 		{
@@ -33,12 +162,22 @@ func TestNewConfig(t *testing.T) {
 				"ADDRESS":          "address",
 				"JWT_TOKEN_SECRET": "secret",
 			},
-			wantErr: errMissingPort,
+			wantErr: errMissingRestPort,
+		},
+		{
+			name: "empty grpc port",
+			input: map[string]string{
+				"ADDRESS":          "address",
+				"REST_PORT":        "restport",
+				"JWT_TOKEN_SECRET": "secret",
+			},
+			wantErr: errMissingGrpcPort,
 		},
 		{
 			name: "empty address",
 			input: map[string]string{
-				"PORT":             "port",
+				"REST_PORT":        "restport",
+				"GRPC_PORT":        "grpcport",
 				"JWT_TOKEN_SECRET": "secret",
 			},
 			wantErr: errMissingAddress,
@@ -46,11 +185,105 @@ func TestNewConfig(t *testing.T) {
 		{
 			name: "empty jwt secret",
 			input: map[string]string{
-				"ADDRESS": "address",
-				"PORT":    "port",
+				"ADDRESS":   "address",
+				"REST_PORT": "restport",
 			},
 			wantErr: errMissingJWTTokenSecret,
 		},
+		{
+			name: "empty redis",
+			input: map[string]string{
+				"ADDRESS":          "address",
+				"REST_PORT":        "restport",
+				"GRPC_PORT":        "grpcport",
+				"JWT_TOKEN_SECRET": "secret",
+			},
+			wantErr: errMissingRedisHost,
+		},
+		{
+			name: "invalid rate limit routes",
+			input: map[string]string{
+				"ADDRESS":           "address",
+				"REST_PORT":         "restport",
+				"GRPC_PORT":         "grpcport",
+				"JWT_TOKEN_SECRET":  "secret",
+				"REDIS_HOST":        "localhost",
+				"REDIS_PORT":        "6379",
+				"REDIS_PASSWORD":    "pass",
+				"RATE_LIMIT_ROUTES": "GET /employees=many",
+			},
+			wantErr: ratelimit.ErrInvalidLimit,
+		},
+		{
+			name: "invalid rate limit tenant",
+			input: map[string]string{
+				"ADDRESS":            "address",
+				"REST_PORT":          "restport",
+				"GRPC_PORT":          "grpcport",
+				"JWT_TOKEN_SECRET":   "secret",
+				"REDIS_HOST":         "localhost",
+				"REDIS_PORT":         "6379",
+				"REDIS_PASSWORD":     "pass",
+				"RATE_LIMIT_TENANTS": "acme:eu=600",
+			},
+			wantErr: tenant.ErrInvalidTenant,
+		},
+		{
+			name: "invalid deprecations",
+			input: map[string]string{
+				"ADDRESS":          "address",
+				"REST_PORT":        "restport",
+				"GRPC_PORT":        "grpcport",
+				"JWT_TOKEN_SECRET": "secret",
+				"REDIS_HOST":       "localhost",
+				"REDIS_PORT":       "6379",
+				"REDIS_PASSWORD":   "pass",
+				"DEPRECATIONS":     "GET /employees=soon",
+			},
+			wantErr: middleware.ErrInvalidDeprecation,
+		},
+		{
+			name: "invalid gateway prefix",
+			input: map[string]string{
+				"ADDRESS":          "address",
+				"REST_PORT":        "restport",
+				"GRPC_PORT":        "grpcport",
+				"JWT_TOKEN_SECRET": "secret",
+				"REDIS_HOST":       "localhost",
+				"REDIS_PORT":       "6379",
+				"REDIS_PASSWORD":   "pass",
+				"GATEWAY_PREFIX":   "gateway",
+			},
+			wantErr: errInvalidGatewayPrefix,
+		},
+		{
+			name: "invalid token ttl",
+			input: map[string]string{
+				"ADDRESS":               "address",
+				"REST_PORT":             "restport",
+				"GRPC_PORT":             "grpcport",
+				"JWT_TOKEN_SECRET":      "secret",
+				"REDIS_HOST":            "localhost",
+				"REDIS_PORT":            "6379",
+				"REDIS_PASSWORD":        "pass",
+				"AUTH_ACCESS_TOKEN_TTL": "15",
+			},
+			wantErr: errInvalidTokenTTL,
+		},
+		{
+			name: "invalid jwks refresh",
+			input: map[string]string{
+				"ADDRESS":          "address",
+				"REST_PORT":        "restport",
+				"GRPC_PORT":        "grpcport",
+				"JWT_TOKEN_SECRET": "secret",
+				"REDIS_HOST":       "localhost",
+				"REDIS_PORT":       "6379",
+				"REDIS_PASSWORD":   "pass",
+				"JWKS_REFRESH":     "-1h",
+			},
+			wantErr: errInvalidJWKSRefresh,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -58,7 +291,6 @@ func TestNewConfig(t *testing.T) {
 				t.Setenv(confName, confValue)
 			}
 			got, err := NewConfig()
-			fmt.Println(err, tt.wantErr)
 			if (tt.wantErr != nil) && !errors.Is(err, tt.wantErr) {
 				t.Errorf("NewConfig() error: %v, wantErr: %v", err, tt.wantErr)
 				return
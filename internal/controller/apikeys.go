@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+)
+
+type APIKeysController struct {
+	Store *apikey.Store
+}
+
+func NewAPIKeysController(store *apikey.Store) *APIKeysController {
+	return &APIKeysController{Store: store}
+}
+
+// apiKeyRequest is the body of create requests, keys without expires_at don't expire.
+type apiKeyRequest struct {
+	Name      string     `json:"name"`
+	Owner     string     `json:"owner"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// createdAPIKey is the key with its secret, which is only returned on creation.
+type createdAPIKey struct {
+	apikey.Key
+	Secret string `json:"key"`
+}
+
+func (c *APIKeysController) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at create api key", Status: http.StatusMethodNotAllowed})
+		return
+	}
+	if !authorizeAPIKeyAdmin(w, r) {
+		return
+	}
+
+	var req apiKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	key := apikey.Key{Name: req.Name, Owner: req.Owner, Scopes: req.Scopes, ExpiresAt: req.ExpiresAt, TenantID: tenant.From(r.Context())}
+	secret, err := c.Store.Create(&key)
+	if err != nil {
+		errorHandler(w, r, apiKeyError(err, "error creating api key"))
+		return
+	}
+
+	writeAPIKeyResponse(w, r, http.StatusCreated, createdAPIKey{Key: key, Secret: secret})
+}
+
+func (c *APIKeysController) GetAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get api key", Status: http.StatusMethodNotAllowed})
+		return
+	}
+	if !authorizeAPIKeyAdmin(w, r) {
+		return
+	}
+
+	key, err := c.key(r)
+	if err != nil {
+		errorHandler(w, r, apiKeyError(err, "error getting api key"))
+		return
+	}
+
+	writeAPIKeyResponse(w, r, http.StatusOK, key)
+}
+
+// GetAllAPIKeys returns the keys of the tenant, including the revoked ones, optionally filtered by the owner
+// query parameter.
+func (c *APIKeysController) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get all api keys", Status: http.StatusMethodNotAllowed})
+		return
+	}
+	if !authorizeAPIKeyAdmin(w, r) {
+		return
+	}
+
+	keys := make([]apikey.Key, 0)
+	for _, key := range c.Store.GetAll(r.URL.Query().Get("owner")) {
+		if key.TenantID == tenant.From(r.Context()) {
+			keys = append(keys, key)
+		}
+	}
+
+	writeAPIKeyResponse(w, r, http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes the key, requests with it are rejected from then on.
+func (c *APIKeysController) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at revoke api key", Status: http.StatusMethodNotAllowed})
+		return
+	}
+	if !authorizeAPIKeyAdmin(w, r) {
+		return
+	}
+
+	if _, err := c.key(r); err != nil {
+		errorHandler(w, r, apiKeyError(err, "error revoking api key"))
+		return
+	}
+
+	key, err := c.Store.Revoke(r.PathValue("id"))
+	if err != nil {
+		errorHandler(w, r, apiKeyError(err, "error revoking api key"))
+		return
+	}
+
+	writeAPIKeyResponse(w, r, http.StatusOK, key)
+}
+
+// key returns the key of the path, the keys of other tenants are not found.
+func (c *APIKeysController) key(r *http.Request) (apikey.Key, error) {
+	key, err := c.Store.Get(r.PathValue("id"))
+	if err != nil {
+		return apikey.Key{}, err
+	}
+	if key.TenantID != tenant.From(r.Context()) {
+		return apikey.Key{}, apikey.ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// authorizeAPIKeyAdmin writes 403 unless the JWT of the caller grants apikey.RoleAdmin.
+func authorizeAPIKeyAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if middleware.HasRole(r.Context(), apikey.RoleAdmin) {
+		return true
+	}
+	errorHandler(w, r, &HTTPError{Detail: "managing api keys requires the " + apikey.RoleAdmin + " role", Status: http.StatusForbidden})
+	return false
+}
+
+func apiKeyError(err error, detail string) *HTTPError {
+	switch {
+	case errors.Is(err, apikey.ErrInvalidRequest):
+		return &HTTPError{Detail: err.Error(), Status: http.StatusBadRequest, Cause: err}
+	case errors.Is(err, apikey.ErrKeyNotFound):
+		return &HTTPError{Detail: "api key not found", Status: http.StatusNotFound, Cause: err}
+	}
+	return &HTTPError{Detail: detail, Status: http.StatusInternalServerError, Cause: err}
+}
+
+func writeAPIKeyResponse(w http.ResponseWriter, r *http.Request, statusCode int, v any) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal api key response", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(response)
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/dilyara4949/employees-api/internal/auth"
+)
+
+const (
+	grantPassword     = "password"
+	grantRefreshToken = "refresh_token"
+)
+
+type AuthController struct {
+	Issuer *auth.Issuer
+}
+
+func NewAuthController(issuer *auth.Issuer) *AuthController {
+	return &AuthController{Issuer: issuer}
+}
+
+// tokenRequest is a login with the password grant or a refresh with the refresh_token grant of RFC 6749.
+type tokenRequest struct {
+	GrantType    string `json:"grant_type"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type revokeRequest struct {
+	Token string `json:"token"`
+}
+
+func (c *AuthController) Token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at token", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	var req tokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	var (
+		tokens auth.TokenPair
+		err    error
+	)
+	switch req.GrantType {
+	case grantPassword:
+		if req.Username == "" || req.Password == "" {
+			errorHandler(w, r, &HTTPError{Detail: "username and password are required", Status: http.StatusBadRequest})
+			return
+		}
+		tokens, err = c.Issuer.Login(r.Context(), req.Username, req.Password)
+	case grantRefreshToken:
+		if req.RefreshToken == "" {
+			errorHandler(w, r, &HTTPError{Detail: "refresh_token is required", Status: http.StatusBadRequest})
+			return
+		}
+		tokens, err = c.Issuer.Refresh(r.Context(), req.RefreshToken)
+	default:
+		errorHandler(w, r, &HTTPError{Detail: "grant_type must be password or refresh_token", Status: http.StatusBadRequest})
+		return
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			errorHandler(w, r, &HTTPError{Detail: "invalid username or password", Status: http.StatusUnauthorized, Cause: err})
+		case errors.Is(err, auth.ErrInvalidRefreshToken):
+			errorHandler(w, r, &HTTPError{Detail: "invalid refresh token", Status: http.StatusUnauthorized, Cause: err})
+		default:
+			errorHandler(w, r, &HTTPError{Detail: "error issuing token", Status: http.StatusInternalServerError, Cause: err})
+		}
+		return
+	}
+
+	response, err := json.Marshal(tokens)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal token", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// Revoke revokes an access or refresh token. Unknown tokens are ignored, as in RFC 7009.
+func (c *AuthController) Revoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at revoke", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	var req revokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		errorHandler(w, r, &HTTPError{Detail: "token is required", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	if err := c.Issuer.Revoke(r.Context(), req.Token); err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error revoking token", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
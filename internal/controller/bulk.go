@@ -0,0 +1,370 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	importModeAtomic     = "atomic"
+	importModeBestEffort = "best_effort"
+
+	batchModeAtomic  = "atomic"
+	batchModePartial = "partial"
+)
+
+type BulkController struct {
+	Importer *bulk.Importer
+	Exporter *bulk.Exporter
+	Batcher  *bulk.Batcher
+}
+
+func NewBulkController(importer *bulk.Importer, exporter *bulk.Exporter, batcher *bulk.Batcher) *BulkController {
+	return &BulkController{Importer: importer, Exporter: exporter, Batcher: batcher}
+}
+
+type employeesBatchRequest struct {
+	Mode       string                   `json:"mode"`
+	Operations []bulk.EmployeeOperation `json:"operations"`
+}
+
+type positionsBatchRequest struct {
+	Mode       string                   `json:"mode"`
+	Operations []bulk.PositionOperation `json:"operations"`
+}
+
+type batchItemResponse struct {
+	Index  int          `json:"index"`
+	Op     bulk.BatchOp `json:"op"`
+	Status int          `json:"status"`
+	ID     string       `json:"id,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Mode      string              `json:"mode"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []batchItemResponse `json:"results"`
+}
+
+func (c *BulkController) ImportEmployees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at import employees", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	format, opts, httpErr := parseImportRequest(r)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
+	rows, rowErrors, err := bulk.DecodeEmployees(r.Body, format)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error reading employees", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	result, err := c.Importer.ImportEmployees(r.Context(), rows, rowErrors, opts)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error importing employees", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	writeImportResult(w, r, result, opts)
+}
+
+func (c *BulkController) ImportPositions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at import positions", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	format, opts, httpErr := parseImportRequest(r)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
+	rows, rowErrors, err := bulk.DecodePositions(r.Body, format)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error reading positions", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	result, err := c.Importer.ImportPositions(r.Context(), rows, rowErrors, opts)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error importing positions", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	writeImportResult(w, r, result, opts)
+}
+
+func (c *BulkController) ExportEmployees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at export employees", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	format, columns, httpErr := parseExportRequest(r, bulk.EmployeeColumns)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
+	writer, ok := startExport(w, r, format, columns, "employees")
+	if !ok {
+		return
+	}
+
+	if err := c.Exporter.ExportEmployees(r.Context(), writer, columns); err != nil {
+		log.Printf("error exporting employees: %v", err)
+	}
+}
+
+func (c *BulkController) ExportPositions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at export positions", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	format, columns, httpErr := parseExportRequest(r, bulk.PositionColumns)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
+	writer, ok := startExport(w, r, format, columns, "positions")
+	if !ok {
+		return
+	}
+
+	if err := c.Exporter.ExportPositions(r.Context(), writer, columns); err != nil {
+		log.Printf("error exporting positions: %v", err)
+	}
+}
+
+func (c *BulkController) BatchEmployees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at batch employees", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	var req employeesBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at unmarshal batch request", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	opts, httpErr := parseBatchMode(req.Mode)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+	opts.AllowSalaryOverride = middleware.HasRole(r.Context(), domain.RoleCompensationAdmin)
+
+	result, err := c.Batcher.BatchEmployees(r.Context(), req.Operations, opts)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at batch employees", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	writeBatchResult(w, r, result)
+}
+
+func (c *BulkController) BatchPositions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at batch positions", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	var req positionsBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at unmarshal batch request", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	opts, httpErr := parseBatchMode(req.Mode)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
+	result, err := c.Batcher.BatchPositions(r.Context(), req.Operations, opts)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at batch positions", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	writeBatchResult(w, r, result)
+}
+
+// parseBatchMode defaults to the atomic mode.
+func parseBatchMode(mode string) (bulk.BatchOptions, *HTTPError) {
+	switch mode {
+	case "", batchModeAtomic:
+		return bulk.BatchOptions{Atomic: true}, nil
+	case batchModePartial:
+		return bulk.BatchOptions{}, nil
+	}
+	return bulk.BatchOptions{}, &HTTPError{Detail: "invalid mode, expected atomic or partial", Status: http.StatusBadRequest}
+}
+
+// writeBatchResult responds with 200 if every item succeeded, 422 if an atomic batch was rejected
+// and 207 if only some items of a partial batch succeeded.
+func writeBatchResult(w http.ResponseWriter, r *http.Request, result bulk.BatchResult) {
+	res := batchResponse{
+		Mode:      batchModePartial,
+		Succeeded: result.Succeeded,
+		Failed:    result.Failed,
+		Results:   make([]batchItemResponse, len(result.Items)),
+	}
+	if result.Atomic {
+		res.Mode = batchModeAtomic
+	}
+
+	for i, item := range result.Items {
+		res.Results[i] = batchItemResponse{Index: item.Index, Op: item.Op, ID: item.ID, Status: batchItemStatus(item)}
+		if item.Err != nil {
+			res.Results[i].Error = item.Err.Error()
+		}
+	}
+
+	response, err := json.Marshal(res)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal batch result", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if result.Failed > 0 {
+		statusCode = http.StatusMultiStatus
+		if result.Atomic {
+			statusCode = http.StatusUnprocessableEntity
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(response)
+}
+
+func batchItemStatus(item bulk.BatchItem) int {
+	switch {
+	case item.Err == nil && item.Op == bulk.OpCreate:
+		return http.StatusCreated
+	case item.Err == nil && item.Op == bulk.OpDelete:
+		return http.StatusNoContent
+	case item.Err == nil:
+		return http.StatusOK
+	case errors.Is(item.Err, bulk.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.Is(item.Err, bulk.ErrSalaryOverrideForbidden):
+		return http.StatusForbidden
+	case errors.Is(item.Err, domain.ErrBandExcludesEmployees):
+		return http.StatusConflict
+	case errors.Is(item.Err, bulk.ErrInvalidOperation),
+		errors.Is(item.Err, domain.ErrSalaryOutOfBand),
+		errors.Is(item.Err, domain.ErrUnknownCurrency),
+		errors.Is(item.Err, domain.ErrNegativeAmount),
+		errors.Is(item.Err, domain.ErrInvalidBand):
+		return http.StatusBadRequest
+	case errors.Is(item.Err, domain.ErrEmployeeNotFound), errors.Is(item.Err, domain.ErrPositionNotFound):
+		return http.StatusNotFound
+	case errors.Is(item.Err, domain.ErrPositionInUse):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// parseExportRequest reads the format, csv by default, and the comma separated columns from the query.
+func parseExportRequest(r *http.Request, available []string) (bulk.Format, []string, *HTTPError) {
+	format := bulk.FormatCSV
+	if value := r.URL.Query().Get("format"); value != "" {
+		var err error
+		if format, err = bulk.ParseFormat(value); err != nil {
+			return "", nil, &HTTPError{Detail: "unsupported export format", Status: http.StatusBadRequest, Cause: err}
+		}
+	}
+
+	columns, err := bulk.ParseColumns(r.URL.Query().Get("columns"), available)
+	if err != nil {
+		return "", nil, &HTTPError{Detail: "invalid columns", Status: http.StatusBadRequest, Cause: err}
+	}
+	return format, columns, nil
+}
+
+// startExport writes the response headers, once it returns the status can't be changed anymore,
+// so errors while exporting rows are only logged.
+func startExport(w http.ResponseWriter, r *http.Request, format bulk.Format, columns []string, name string) (bulk.RowWriter, bool) {
+	w.Header().Set("Content-Type", bulk.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+string(format)))
+
+	writer, err := bulk.NewRowWriter(w, format, columns)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error starting export", Status: http.StatusInternalServerError, Cause: err})
+		return nil, false
+	}
+	return writer, true
+}
+
+// parseImportRequest reads the format from the format query parameter or the Content-Type header,
+// and the options from the dry_run and mode query parameters.
+func parseImportRequest(r *http.Request) (bulk.Format, bulk.ImportOptions, *HTTPError) {
+	value := r.URL.Query().Get("format")
+	if value == "" {
+		value = r.Header.Get("Content-Type")
+	}
+
+	format, err := bulk.ParseFormat(value)
+	if err != nil || format == bulk.FormatXLSX {
+		return "", bulk.ImportOptions{}, &HTTPError{Detail: "unsupported import format", Status: http.StatusUnsupportedMediaType, Cause: err}
+	}
+
+	opts := bulk.ImportOptions{
+		AllowSalaryOverride: middleware.HasRole(r.Context(), domain.RoleCompensationAdmin),
+	}
+
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		if opts.DryRun, err = strconv.ParseBool(value); err != nil {
+			return "", bulk.ImportOptions{}, &HTTPError{Detail: "invalid dry_run", Status: http.StatusBadRequest, Cause: err}
+		}
+	}
+
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", importModeAtomic:
+	case importModeBestEffort:
+		opts.BestEffort = true
+	default:
+		return "", bulk.ImportOptions{}, &HTTPError{Detail: "invalid mode, expected atomic or best_effort", Status: http.StatusBadRequest}
+	}
+
+	return format, opts, nil
+}
+
+// writeImportResult responds with 422 when an atomic import was rejected because of invalid rows.
+func writeImportResult(w http.ResponseWriter, r *http.Request, result bulk.ImportResult, opts bulk.ImportOptions) {
+	response, err := json.Marshal(result)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal import result", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if !opts.DryRun && !opts.BestEffort && len(result.Errors) > 0 {
+		statusCode = http.StatusUnprocessableEntity
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(response)
+}
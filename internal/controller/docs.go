@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"io/fs"
+	"net/http"
+)
+
+// DocsController serves the OpenAPI document and the Swagger UI page rendering it.
+type DocsController struct {
+	Spec      []byte
+	SwaggerUI []byte
+	// Assets are the scripts and stylesheets of the Swagger UI page, served at /docs/{file}.
+	Assets fs.FS
+}
+
+func NewDocsController(spec, swaggerUI []byte, assets fs.FS) *DocsController {
+	return &DocsController{Spec: spec, SwaggerUI: swaggerUI, Assets: assets}
+}
+
+func (c *DocsController) GetSpec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get openapi spec", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(c.Spec)
+}
+
+func (c *DocsController) GetDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get docs", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(c.SwaggerUI)
+}
+
+func (c *DocsController) GetDocsAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get docs asset", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	http.ServeFileFS(w, r, c.Assets, r.PathValue("file"))
+}
@@ -2,17 +2,31 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/dto"
+	"github.com/dilyara4949/employees-api/internal/middleware"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type EmployeesController struct {
 	Repo domain.EmployeesRepository
+	// Codec is the JSON representation of employees of the API version served by the controller.
+	Codec dto.Codec
 }
 
 func NewEmployeesController(repo domain.EmployeesRepository) *EmployeesController {
-	return &EmployeesController{repo}
+	return &EmployeesController{Repo: repo, Codec: dto.V1}
+}
+
+// WithCodec returns a copy of the controller serving the representation of another API version.
+func (c *EmployeesController) WithCodec(codec dto.Codec) *EmployeesController {
+	controller := *c
+	controller.Codec = codec
+	return &controller
 }
 
 func (c *EmployeesController) GetEmployee(w http.ResponseWriter, r *http.Request) {
@@ -24,18 +38,33 @@ func (c *EmployeesController) GetEmployee(w http.ResponseWriter, r *http.Request
 	employeeID := r.PathValue("id")
 	employee, err := c.Repo.Get(r.Context(), employeeID)
 
+	if errors.Is(err, domain.ErrEmployeeNotFound) {
+		errorHandler(w, r, &HTTPError{Detail: "employee not found", Status: http.StatusNotFound, Cause: err})
+		return
+	}
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error getting employee", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
-	response, err := json.Marshal(employee)
+	history, err := c.Repo.PositionHistory(r.Context(), employeeID)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error getting position history", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+	dates := make([]time.Time, len(history))
+	for i, assignment := range history {
+		dates[i] = assignment.EffectiveFrom
+	}
+
+	response, err := json.Marshal(c.Codec.EncodeEmployee(*employee))
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error at marshal employee", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	setExpires(w, dates...)
 	w.WriteHeader(http.StatusOK)
 	w.Write(response)
 }
@@ -52,18 +81,31 @@ func (c *EmployeesController) CreateEmployee(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	var employee domain.Employee
-	if err := json.Unmarshal(body, &employee); err != nil {
+	employee, err := c.Codec.DecodeEmployee(body)
+	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err})
 		return
 	}
 
+	if httpErr := validateSalary(r, employee); httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
 	if err = c.Repo.Create(r.Context(), &employee); err != nil {
+		if errors.Is(err, domain.ErrSalaryOutOfBand) {
+			errorHandler(w, r, &HTTPError{Detail: "salary is out of position band", Status: http.StatusBadRequest, Cause: err})
+			return
+		}
+		if errors.Is(err, domain.ErrPositionNotFound) {
+			errorHandler(w, r, &HTTPError{Detail: "position of the employee does not exist", Status: http.StatusConflict, Cause: err})
+			return
+		}
 		errorHandler(w, r, &HTTPError{Detail: "error creating employee", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
-	response, err := json.Marshal(employee)
+	response, err := json.Marshal(c.Codec.EncodeEmployee(employee))
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error at marshal employee", Status: http.StatusInternalServerError, Cause: err})
 		return
@@ -83,6 +125,10 @@ func (c *EmployeesController) DeleteEmployee(w http.ResponseWriter, r *http.Requ
 	employeeID := r.PathValue("id")
 	err := c.Repo.Delete(r.Context(), employeeID)
 
+	if errors.Is(err, domain.ErrEmployeeNotFound) {
+		errorHandler(w, r, &HTTPError{Detail: "employee not found", Status: http.StatusNotFound, Cause: err})
+		return
+	}
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error deleting employee", Status: http.StatusInternalServerError, Cause: err})
 		return
@@ -109,19 +155,36 @@ func (c *EmployeesController) UpdateEmployee(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	var employee domain.Employee
-	if err := json.Unmarshal(body, &employee); err != nil {
+	employee, err := c.Codec.DecodeEmployee(body)
+	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err})
 		return
 	}
 
+	if httpErr := validateSalary(r, employee); httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
 	employee.ID = employeeID
 	if err := c.Repo.Update(r.Context(), employee); err != nil {
+		if errors.Is(err, domain.ErrSalaryOutOfBand) {
+			errorHandler(w, r, &HTTPError{Detail: "salary is out of position band", Status: http.StatusBadRequest, Cause: err})
+			return
+		}
+		if errors.Is(err, domain.ErrPositionNotFound) {
+			errorHandler(w, r, &HTTPError{Detail: "position of the employee does not exist", Status: http.StatusConflict, Cause: err})
+			return
+		}
+		if errors.Is(err, domain.ErrEmployeeNotFound) {
+			errorHandler(w, r, &HTTPError{Detail: "employee not found", Status: http.StatusNotFound, Cause: err})
+			return
+		}
 		errorHandler(w, r, &HTTPError{Detail: "error updating employee", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
-	response, err := json.Marshal(employee)
+	response, err := json.Marshal(c.Codec.EncodeEmployee(employee))
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error at marshal employee", Status: http.StatusInternalServerError, Cause: err})
 		return
@@ -140,11 +203,175 @@ func (e *EmployeesController) GetAllEmployees(w http.ResponseWriter, r *http.Req
 
 	employees, err := e.Repo.GetAll(r.Context())
 	if err != nil {
-		errorHandler(w, r, &HTTPError{Detail: "error at get all employees", Status: http.StatusInternalServerError})
+		errorHandler(w, r, &HTTPError{Detail: "error at getting all employees", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	response, err := json.Marshal(dto.EncodeEmployees(e.Codec, employees))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal employees", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func (c *EmployeesController) GetEmployeeAsOf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get employee as of date", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	date, err := parseDate(r.URL.Query().Get("date"))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid date", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	employeeID := r.PathValue("id")
+	employee, err := c.Repo.GetAsOf(r.Context(), employeeID, date)
+	if err != nil {
+		errorHandler(w, r, employeeHistoryError(err, "error getting employee as of date"))
+		return
+	}
+
+	response, err := json.Marshal(c.Codec.EncodeEmployee(*employee))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal employee", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func (c *EmployeesController) GetPositionHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get position history", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	employeeID := r.PathValue("id")
+	history, err := c.Repo.PositionHistory(r.Context(), employeeID)
+	if err != nil {
+		errorHandler(w, r, employeeHistoryError(err, "error getting position history"))
+		return
+	}
+
+	response, err := json.Marshal(dto.EncodePositionAssignments(c.Codec, history))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal position history", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func (c *EmployeesController) SchedulePositionChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at schedule position change", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error reading request body", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	assignment, err := c.Codec.DecodePositionAssignment(body)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	if !assignment.EffectiveFrom.After(time.Now()) {
+		errorHandler(w, r, &HTTPError{Detail: "effective date must be in the future", Status: http.StatusBadRequest})
+		return
+	}
+
+	assignment.EmployeeID = r.PathValue("id")
+	if err := c.Repo.SchedulePositionChange(r.Context(), assignment); err != nil {
+		errorHandler(w, r, employeeHistoryError(err, "error scheduling position change"))
+		return
+	}
+
+	response, err := json.Marshal(c.Codec.EncodePositionAssignment(assignment))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal position change", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(response)
+}
+
+// employeeHistoryError maps the errors of the effective-dated operations on employees, the position of a scheduled
+// change has to exist.
+func employeeHistoryError(err error, detail string) *HTTPError {
+	switch {
+	case errors.Is(err, domain.ErrEmployeeNotFoundAsOf):
+		return &HTTPError{Detail: "employee not found at given date", Status: http.StatusNotFound, Cause: err}
+	case errors.Is(err, domain.ErrEmployeeNotFound):
+		return &HTTPError{Detail: "employee not found", Status: http.StatusNotFound, Cause: err}
+	case errors.Is(err, domain.ErrPositionNotFound):
+		return &HTTPError{Detail: "position of the employee does not exist", Status: http.StatusConflict, Cause: err}
+	case errors.Is(err, domain.ErrEffectiveDateNotInFuture):
+		return &HTTPError{Detail: "effective date must be in the future", Status: http.StatusBadRequest, Cause: err}
+	case errors.Is(err, domain.ErrSalaryOutOfBand):
+		return &HTTPError{Detail: "salary is out of position band", Status: http.StatusBadRequest, Cause: err}
+	}
+	return &HTTPError{Detail: detail, Status: http.StatusInternalServerError, Cause: err}
+}
+
+// validateSalary checks the salary of the request body, overriding the position band requires a privileged role.
+func validateSalary(r *http.Request, employee domain.Employee) *HTTPError {
+	if employee.Salary != nil {
+		if err := employee.Salary.Validate(); err != nil {
+			return &HTTPError{Detail: "invalid salary", Status: http.StatusBadRequest, Cause: err}
+		}
+	}
+
+	if employee.SalaryOverride && !middleware.HasRole(r.Context(), domain.RoleCompensationAdmin) {
+		return &HTTPError{Detail: "salary override requires " + domain.RoleCompensationAdmin + " role", Status: http.StatusForbidden}
+	}
+	return nil
+}
+
+func (c *EmployeesController) SearchEmployees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at search employees", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		errorHandler(w, r, &HTTPError{Detail: "missing search query", Status: http.StatusBadRequest})
+		return
+	}
+
+	limit := domain.DefaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			errorHandler(w, r, &HTTPError{Detail: "invalid limit", Status: http.StatusBadRequest, Cause: err})
+			return
+		}
+	}
+
+	results, err := c.Repo.Search(r.Context(), query, limit)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error searching employees", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
-	response, err := json.Marshal(employees)
+	response, err := json.Marshal(dto.EncodeSearchResults(c.Codec, results))
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error at marshal employees", Status: http.StatusInternalServerError, Cause: err})
 		return
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dilyara4949/employees-api/internal/domain"
 )
@@ -69,6 +70,103 @@ func (e empRepoMock) GetAll(_ context.Context) ([]domain.Employee, error) {
 	}, nil
 }
 
+func (e empRepoMock) GetAsOf(_ context.Context, id string, date time.Time) (*domain.Employee, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return &domain.Employee{
+		ID:         "id",
+		FirstName:  "first name",
+		LastName:   "last name",
+		PositionID: "position id",
+	}, nil
+}
+
+func (e empRepoMock) PositionHistory(_ context.Context, id string) ([]domain.PositionAssignment, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return []domain.PositionAssignment{
+		{
+			EmployeeID:    "id",
+			PositionID:    "position id",
+			EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}, nil
+}
+
+func (e empRepoMock) SchedulePositionChange(_ context.Context, assignment domain.PositionAssignment) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	return nil
+}
+
+func (e empRepoMock) Search(_ context.Context, query string, limit int) ([]domain.EmployeeSearchResult, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return []domain.EmployeeSearchResult{
+		{
+			Employee: domain.Employee{
+				ID:         "id",
+				FirstName:  "first name",
+				LastName:   "last name",
+				PositionID: "position id",
+			},
+			Score: 1,
+		},
+	}, nil
+}
+
+func (e empRepoMock) ListByPosition(_ context.Context, positionID string) ([]domain.Employee, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if positionID != "used" {
+		return []domain.Employee{}, nil
+	}
+
+	return []domain.Employee{{
+		ID:         "id",
+		FirstName:  "first name",
+		LastName:   "last name",
+		PositionID: positionID,
+	}}, nil
+}
+
+func (e empRepoMock) ListScheduledForPosition(_ context.Context, positionID string) ([]domain.PositionAssignment, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if positionID != "scheduled" {
+		return []domain.PositionAssignment{}, nil
+	}
+
+	return []domain.PositionAssignment{{
+		EmployeeID:    "id",
+		PositionID:    positionID,
+		EffectiveFrom: time.Now().Add(24 * time.Hour),
+	}}, nil
+}
+
+func (e empRepoMock) Iterate(_ context.Context, fn func(domain.Employee) error) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	return fn(domain.Employee{
+		ID:         "id",
+		FirstName:  "first name",
+		LastName:   "last name",
+		PositionID: "position id",
+	})
+}
+
 func TestEmployeesController_GetEmployee(t *testing.T) {
 	tests := map[string]struct {
 		id       string
@@ -85,6 +183,11 @@ func TestEmployeesController_GetEmployee(t *testing.T) {
 			expected: "error getting employee\n",
 			repo:     empRepoMock{err: errors.New("error")},
 		},
+		"not found": {
+			id:       "missing",
+			expected: "employee not found\n",
+			repo:     empRepoMock{err: domain.ErrEmployeeNotFound},
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -119,6 +222,47 @@ func TestEmployeesController_GetEmployee(t *testing.T) {
 	}
 }
 
+// scheduledEmpRepoMock has a position change scheduled at next.
+type scheduledEmpRepoMock struct {
+	empRepoMock
+	next time.Time
+}
+
+func (e scheduledEmpRepoMock) PositionHistory(ctx context.Context, id string) ([]domain.PositionAssignment, error) {
+	history, err := e.empRepoMock.PositionHistory(ctx, id)
+	return append(history, domain.PositionAssignment{EmployeeID: id, PositionID: "other position id", EffectiveFrom: e.next}), err
+}
+
+func TestEmployeesController_GetEmployee_Expires(t *testing.T) {
+	next := time.Now().Add(24 * time.Hour)
+
+	tests := map[string]struct {
+		repo     domain.EmployeesRepository
+		expected string
+	}{
+		"no scheduled change": {
+			repo: empRepoMock{},
+		},
+		"scheduled change": {
+			repo:     scheduledEmpRepoMock{next: next},
+			expected: next.UTC().Format(http.TimeFormat),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /employees/{id}", NewEmployeesController(tt.repo).GetEmployee)
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/employees/id", nil))
+
+			if expires := rr.Header().Get("Expires"); rr.Code != http.StatusOK || expires != tt.expected {
+				t.Errorf(`expected 200 expiring at "%s", got %d "%s"`, tt.expected, rr.Code, expires)
+			}
+		})
+	}
+}
+
 func TestEmployeesController_CreateEmployee(t *testing.T) {
 	tests := map[string]struct {
 		body     string
@@ -140,6 +284,16 @@ func TestEmployeesController_CreateEmployee(t *testing.T) {
 			expected: "error creating employee\n",
 			repo:     empRepoMock{err: errors.New("error")},
 		},
+		"salary out of band": {
+			body:     "{\"firstname\":\"first name\",\"lastname\":\"last name\",\"position_id\":\"position id\",\"salary\":{\"amount\":100,\"currency\":\"USD\"}}",
+			expected: "salary is out of position band\n",
+			repo:     empRepoMock{err: fmt.Errorf("error to create employee: %w", domain.ErrSalaryOutOfBand)},
+		},
+		"salary override without role": {
+			body:     "{\"firstname\":\"first name\",\"lastname\":\"last name\",\"position_id\":\"position id\",\"salary\":{\"amount\":100,\"currency\":\"USD\"},\"salary_override\":true}",
+			expected: "salary override requires compensation_admin role\n",
+			repo:     empRepoMock{},
+		},
 	}
 
 	for name, tt := range tests {
@@ -194,6 +348,12 @@ func TestEmployeesController_DeleteEmployee(t *testing.T) {
 			expectedCode: 500,
 			repo:         empRepoMock{err: errors.New("error")},
 		},
+		"not found": {
+			id:           "missing",
+			expected:     "employee not found\n",
+			expectedCode: 404,
+			repo:         empRepoMock{err: domain.ErrEmployeeNotFound},
+		},
 	}
 
 	for name, tt := range tests {
@@ -343,3 +503,269 @@ func TestEmployeesController_GetAllEmployees(t *testing.T) {
 		})
 	}
 }
+
+func TestEmployeesController_SearchEmployees(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		expected string
+		repo     empRepoMock
+	}{
+		"OK": {
+			query:    "q=first&limit=10",
+			expected: "[{\"id\":\"id\",\"firstname\":\"first name\",\"lastname\":\"last name\",\"position_id\":\"position id\",\"score\":1}]",
+			repo:     empRepoMock{},
+		},
+		"missing query": {
+			query:    "limit=10",
+			expected: "missing search query\n",
+			repo:     empRepoMock{},
+		},
+		"invalid limit": {
+			query:    "q=first&limit=-1",
+			expected: "invalid limit\n",
+			repo:     empRepoMock{},
+		},
+		"err": {
+			query:    "q=first",
+			expected: "error searching employees\n",
+			repo:     empRepoMock{err: errors.New("error")},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewEmployeesController(tt.repo)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /employees/search", h.SearchEmployees)
+
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			req, err := http.NewRequest("GET", fmt.Sprintf("%s/employees/search?%s", svr.URL, tt.query), http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cl := http.Client{}
+			resp, err := cl.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			response, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res := string(response); res != tt.expected {
+				t.Fatalf(`expected "%s", got "%s"`, tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestEmployeesController_GetEmployeeAsOf(t *testing.T) {
+	tests := map[string]struct {
+		date     string
+		expected string
+		repo     empRepoMock
+	}{
+		"OK": {
+			date:     "2024-01-01",
+			expected: "{\"id\":\"id\",\"firstname\":\"first name\",\"lastname\":\"last name\",\"position_id\":\"position id\"}",
+			repo:     empRepoMock{},
+		},
+		"invalid date": {
+			date:     "yesterday",
+			expected: "invalid date\n",
+			repo:     empRepoMock{},
+		},
+		"err": {
+			date:     "2024-01-01",
+			expected: "error getting employee as of date\n",
+			repo:     empRepoMock{err: errors.New("error")},
+		},
+		"not found at date": {
+			date:     "2020-01-01",
+			expected: "employee not found at given date\n",
+			repo:     empRepoMock{err: domain.ErrEmployeeNotFoundAsOf},
+		},
+		"not found": {
+			date:     "2024-01-01",
+			expected: "employee not found\n",
+			repo:     empRepoMock{err: domain.ErrEmployeeNotFound},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewEmployeesController(tt.repo)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /employees/{id}/as-of", h.GetEmployeeAsOf)
+
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			req, err := http.NewRequest("GET", fmt.Sprintf("%s/employees/id/as-of?date=%s", svr.URL, tt.date), http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hcl := http.Client{}
+			resp, err := hcl.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			response, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strResponse := string(response); strResponse != tt.expected {
+				t.Fatalf(`expected "%s", got "%s"`, tt.expected, strResponse)
+			}
+		})
+	}
+}
+
+func TestEmployeesController_GetPositionHistory(t *testing.T) {
+	tests := map[string]struct {
+		expected string
+		repo     empRepoMock
+	}{
+		"OK": {
+			expected: "[{\"employee_id\":\"id\",\"position_id\":\"position id\",\"effective_from\":\"2024-01-01T00:00:00Z\"}]",
+			repo:     empRepoMock{},
+		},
+		"err": {
+			expected: "error getting position history\n",
+			repo:     empRepoMock{err: errors.New("error")},
+		},
+		"not found": {
+			expected: "employee not found\n",
+			repo:     empRepoMock{err: domain.ErrEmployeeNotFound},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewEmployeesController(tt.repo)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /employees/{id}/history", h.GetPositionHistory)
+
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			req, err := http.NewRequest("GET", fmt.Sprintf("%s/employees/id/history", svr.URL), http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hcl := http.Client{}
+			resp, err := hcl.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			response, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strResponse := string(response); strResponse != tt.expected {
+				t.Fatalf(`expected "%s", got "%s"`, tt.expected, strResponse)
+			}
+		})
+	}
+}
+
+func TestEmployeesController_SchedulePositionChange(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	body := fmt.Sprintf("{\"position_id\":\"position id\",\"effective_from\":\"%s\"}", future)
+
+	tests := map[string]struct {
+		body     string
+		expected string
+		status   int
+		repo     empRepoMock
+	}{
+		"OK": {
+			body:     body,
+			expected: fmt.Sprintf("{\"employee_id\":\"id\",\"position_id\":\"position id\",\"effective_from\":\"%s\"}", future),
+			status:   http.StatusCreated,
+			repo:     empRepoMock{},
+		},
+		"past date": {
+			body:     "{\"position_id\":\"position id\",\"effective_from\":\"2020-01-01T00:00:00Z\"}",
+			expected: "effective date must be in the future\n",
+			status:   http.StatusBadRequest,
+			repo:     empRepoMock{},
+		},
+		"err": {
+			body:     body,
+			expected: "error scheduling position change\n",
+			status:   http.StatusInternalServerError,
+			repo:     empRepoMock{err: errors.New("error")},
+		},
+		"employee not found": {
+			body:     body,
+			expected: "employee not found\n",
+			status:   http.StatusNotFound,
+			repo:     empRepoMock{err: domain.ErrEmployeeNotFound},
+		},
+		"position not found": {
+			body:     body,
+			expected: "position of the employee does not exist\n",
+			status:   http.StatusConflict,
+			repo:     empRepoMock{err: fmt.Errorf("error to schedule position change: %w", domain.ErrPositionNotFound)},
+		},
+		"date passed": {
+			body:     body,
+			expected: "effective date must be in the future\n",
+			status:   http.StatusBadRequest,
+			repo:     empRepoMock{err: domain.ErrEffectiveDateNotInFuture},
+		},
+		"out of band": {
+			body:     body,
+			expected: "salary is out of position band\n",
+			status:   http.StatusBadRequest,
+			repo:     empRepoMock{err: fmt.Errorf("error to schedule position change: %w", domain.ErrSalaryOutOfBand)},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewEmployeesController(tt.repo)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /employees/{id}/history", h.SchedulePositionChange)
+
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			req, err := http.NewRequest("POST", fmt.Sprintf("%s/employees/id/history", svr.URL), strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			hcl := http.Client{}
+			resp, err := hcl.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			response, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if strResponse := string(response); strResponse != tt.expected {
+				t.Fatalf(`expected "%s", got "%s"`, tt.expected, strResponse)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+)
+
+const (
+	defaultHeartbeat = 15 * time.Second
+	// sseRetry is the reconnection delay in milliseconds suggested to clients.
+	sseRetry = 3000
+)
+
+type EventsController struct {
+	Events *events.Bus
+	// Heartbeat is the interval of comments keeping idle connections open.
+	Heartbeat time.Duration
+}
+
+func NewEventsController(bus *events.Bus) *EventsController {
+	return &EventsController{Events: bus, Heartbeat: defaultHeartbeat}
+}
+
+// StreamEvents streams employee and position changes of the tenant of the caller as server-sent events. The entity and id query parameters
+// filter the events by comma separated entity types and IDs, the Last-Event-ID header or the last_event_id
+// query parameter resume the stream after the given event.
+func (c *EventsController) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at stream events", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	filter, httpErr := parseEventFilter(r)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	sub, err := c.Events.Subscribe(lastEventID, filter)
+	if errors.Is(err, events.ErrInvalidToken) {
+		errorHandler(w, r, &HTTPError{Detail: "invalid last event ID", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+	if errors.Is(err, events.ErrTokenExpired) {
+		errorHandler(w, r, &HTTPError{Detail: "last event ID expired, reload the entities and subscribe again", Status: http.StatusGone, Cause: err})
+		return
+	}
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error subscribing to events", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+
+	for {
+		if err := rc.Flush(); err != nil {
+			log.Printf("error flushing events: %v", err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), c.Heartbeat)
+		event, err := sub.Next(ctx)
+		cancel()
+
+		switch {
+		case err == nil:
+			if err := writeEvent(w, event); err != nil {
+				log.Printf("error writing event: %v", err)
+				return
+			}
+		case errors.Is(err, context.DeadlineExceeded) && r.Context().Err() == nil:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		default:
+			// the client reconnects with the ID of the last event it received
+			return
+		}
+	}
+}
+
+// writeEvent writes the event named after its entity and type, e.g. employee.created.
+func writeEvent(w http.ResponseWriter, event domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s.%s\ndata: %s\n\n", event.Token, event.Entity, event.Type, data)
+	return err
+}
+
+func parseEventFilter(r *http.Request) (func(domain.Event) bool, *HTTPError) {
+	entities := make(map[domain.EntityType]struct{})
+	for _, value := range splitQuery(r, "entity") {
+		entity := domain.EntityType(value)
+		if entity != domain.EntityEmployee && entity != domain.EntityPosition {
+			return nil, &HTTPError{Detail: "invalid entity, expected employee or position", Status: http.StatusBadRequest}
+		}
+		entities[entity] = struct{}{}
+	}
+
+	ids := make(map[string]struct{})
+	for _, id := range splitQuery(r, "id") {
+		ids[id] = struct{}{}
+	}
+
+	tenantID := tenant.From(r.Context())
+	return func(event domain.Event) bool {
+		if event.TenantID != tenantID {
+			return false
+		}
+		if _, ok := entities[event.Entity]; len(entities) > 0 && !ok {
+			return false
+		}
+		if _, ok := ids[event.EntityID]; len(ids) > 0 && !ok {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// splitQuery returns the comma separated values of a repeatable query parameter.
+func splitQuery(r *http.Request, key string) []string {
+	values := make([]string, 0)
+	for _, value := range r.URL.Query()[key] {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
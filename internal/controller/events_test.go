@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
+)
+
+func TestEventsController_StreamEvents(t *testing.T) {
+	bus := events.NewBus(10)
+
+	sub, err := bus.Subscribe("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	for _, event := range []domain.Event{
+		{Type: domain.EventCreated, Entity: domain.EntityEmployee, EntityID: "1"},
+		{Type: domain.EventUpdated, Entity: domain.EntityPosition, EntityID: "p"},
+		// events of other tenants are not streamed
+		{Type: domain.EventCreated, Entity: domain.EntityEmployee, EntityID: "3", TenantID: "acme"},
+		{Type: domain.EventDeleted, Entity: domain.EntityEmployee, EntityID: "2"},
+	} {
+		bus.Publish(event)
+	}
+
+	first, err := sub.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		query        string
+		lastEventID  string
+		expectedCode int
+		expected     string
+	}{
+		"resume filtered": {
+			query:        "?entity=employee",
+			lastEventID:  first.Token,
+			expectedCode: 200,
+			expected:     "event: employee.deleted",
+		},
+		"resume by query": {
+			query:        "?id=p&last_event_id=" + first.Token,
+			expectedCode: 200,
+			expected:     "event: position.updated",
+		},
+		"invalid entity": {
+			query:        "?entity=team",
+			expectedCode: 400,
+			expected:     "invalid entity, expected employee or position",
+		},
+		"invalid last event ID": {
+			lastEventID:  "token",
+			expectedCode: 400,
+			expected:     "invalid last event ID",
+		},
+		"expired": {
+			lastEventID:  "epoch.1",
+			expectedCode: 410,
+			expected:     "last event ID expired, reload the entities and subscribe again",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewEventsController(bus)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /events", h.StreamEvents)
+
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, "GET", svr.URL+"/events"+tt.query, http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedCode {
+				t.Fatalf(`expected "%d", got "%d"`, tt.expectedCode, resp.StatusCode)
+			}
+
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				line := scanner.Text()
+				if strings.HasPrefix(line, "event: ") || resp.StatusCode != 200 {
+					if line != tt.expected {
+						t.Fatalf(`expected "%s", got "%s"`, tt.expected, line)
+					}
+					return
+				}
+			}
+			t.Fatalf("expected %q, got end of stream: %v", tt.expected, scanner.Err())
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/dilyara4949/employees-api/internal/middleware"
 	"log"
 	"net/http"
+	"time"
 )
 
 type HTTPError struct {
@@ -24,7 +25,6 @@ func errorHandler(w http.ResponseWriter, r *http.Request, err error) {
 		correlationId := r.Context().Value(middleware.CorrelationID)
 		if correlationId == nil {
 			log.Println("Correlation id set incorrect")
-			http.Error(w, "internal server error: Correlation id set incorrect", http.StatusInternalServerError)
 		}
 
 		log.Printf("HTTP error at %v: %v, correlationID=%v", r.URL, err, correlationId)
@@ -36,3 +36,26 @@ func errorHandler(w http.ResponseWriter, r *http.Request, err error) {
 		}
 	}
 }
+
+// parseDate accepts either a full RFC 3339 timestamp or a plain date.
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// setExpires sets the Expires header to the earliest of the dates after now, the date the response goes stale
+// because a scheduled change takes effect.
+func setExpires(w http.ResponseWriter, dates ...time.Time) {
+	var next time.Time
+	now := time.Now()
+	for _, date := range dates {
+		if date.After(now) && (next.IsZero() || date.Before(next)) {
+			next = date
+		}
+	}
+	if !next.IsZero() {
+		w.Header().Set("Expires", next.UTC().Format(http.TimeFormat))
+	}
+}
@@ -1,18 +1,34 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"github.com/dilyara4949/employees-api/internal/currency"
 	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/dto"
 	"io"
 	"net/http"
+	"time"
 )
 
 type PositionsController struct {
-	Repo domain.PositionsRepository
+	Repo       domain.PositionsRepository
+	UnitOfWork domain.UnitOfWork
+	Rates      *currency.Rates
+	// Codec is the JSON representation of positions of the API version served by the controller.
+	Codec dto.Codec
 }
 
-func NewPositionsController(repo domain.PositionsRepository) *PositionsController {
-	return &PositionsController{repo}
+func NewPositionsController(repo domain.PositionsRepository, uow domain.UnitOfWork, rates *currency.Rates) *PositionsController {
+	return &PositionsController{Repo: repo, UnitOfWork: uow, Rates: rates, Codec: dto.V1}
+}
+
+// WithCodec returns a copy of the controller serving the representation of another API version.
+func (c *PositionsController) WithCodec(codec dto.Codec) *PositionsController {
+	controller := *c
+	controller.Codec = codec
+	return &controller
 }
 
 func (c *PositionsController) GetPosition(w http.ResponseWriter, r *http.Request) {
@@ -24,18 +40,33 @@ func (c *PositionsController) GetPosition(w http.ResponseWriter, r *http.Request
 	positionID := r.PathValue("id")
 	position, err := c.Repo.Get(r.Context(), positionID)
 
+	if errors.Is(err, domain.ErrPositionNotFound) {
+		errorHandler(w, r, &HTTPError{Detail: "position not found", Status: http.StatusNotFound, Cause: err})
+		return
+	}
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error getting position", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
-	response, err := json.Marshal(position)
+	history, err := c.Repo.SalaryHistory(r.Context(), positionID)
+	if err != nil {
+		errorHandler(w, r, positionHistoryError(err, "error getting salary history"))
+		return
+	}
+	dates := make([]time.Time, len(history))
+	for i, change := range history {
+		dates[i] = change.EffectiveFrom
+	}
+
+	response, err := json.Marshal(c.Codec.EncodePosition(*position))
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error at marshal position", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	setExpires(w, dates...)
 	w.WriteHeader(http.StatusOK)
 	w.Write(response)
 }
@@ -52,18 +83,30 @@ func (c *PositionsController) CreatePosition(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	var position domain.Position
-	if err := json.Unmarshal(body, &position); err != nil {
+	position, err := c.Codec.DecodePosition(body)
+	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err})
 		return
 	}
 
+	if err := position.Salary.Validate(); err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid salary", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	if position.Band != nil {
+		if err := position.Band.Validate(); err != nil {
+			errorHandler(w, r, &HTTPError{Detail: "invalid salary band", Status: http.StatusBadRequest, Cause: err})
+			return
+		}
+	}
+
 	if err = c.Repo.Create(r.Context(), &position); err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error creating position", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
-	response, err := json.Marshal(position)
+	response, err := json.Marshal(c.Codec.EncodePosition(position))
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error at marshal position", Status: http.StatusInternalServerError, Cause: err})
 		return
@@ -81,8 +124,20 @@ func (c *PositionsController) DeletePosition(w http.ResponseWriter, r *http.Requ
 	}
 
 	positionID := r.PathValue("id")
-	err := c.Repo.Delete(r.Context(), positionID)
+	reassignTo := r.URL.Query().Get("reassign_to")
+
+	err := c.UnitOfWork.Do(r.Context(), func(ctx context.Context, tx domain.Tx) error {
+		return domain.DeletePosition(ctx, tx, positionID, reassignTo)
+	})
 
+	if errors.Is(err, domain.ErrPositionInUse) {
+		errorHandler(w, r, &HTTPError{Detail: "position has employees assigned, set reassign_to to move them", Status: http.StatusConflict, Cause: err})
+		return
+	}
+	if errors.Is(err, domain.ErrPositionNotFound) {
+		errorHandler(w, r, &HTTPError{Detail: "position not found", Status: http.StatusNotFound, Cause: err})
+		return
+	}
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error deleting position", Status: http.StatusInternalServerError, Cause: err})
 		return
@@ -109,19 +164,42 @@ func (c *PositionsController) UpdatePosition(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	var position domain.Position
-	if err := json.Unmarshal(body, &position); err != nil {
+	position, err := c.Codec.DecodePosition(body)
+	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err})
 		return
 	}
 
+	if err := position.Salary.Validate(); err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid salary", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	if position.Band != nil {
+		if err := position.Band.Validate(); err != nil {
+			errorHandler(w, r, &HTTPError{Detail: "invalid salary band", Status: http.StatusBadRequest, Cause: err})
+			return
+		}
+	}
+
 	position.ID = positionID
-	if err := c.Repo.Update(r.Context(), position); err != nil {
+	err = c.UnitOfWork.Do(r.Context(), func(ctx context.Context, tx domain.Tx) error {
+		return domain.UpdatePosition(ctx, tx, position)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrPositionNotFound) {
+			errorHandler(w, r, &HTTPError{Detail: "position not found", Status: http.StatusNotFound, Cause: err})
+			return
+		}
+		if errors.Is(err, domain.ErrBandExcludesEmployees) {
+			errorHandler(w, r, &HTTPError{Detail: "salary band excludes employees of the position", Status: http.StatusConflict, Cause: err})
+			return
+		}
 		errorHandler(w, r, &HTTPError{Detail: "error updating position", Status: http.StatusInternalServerError, Cause: err})
 		return
 	}
 
-	response, err := json.Marshal(position)
+	response, err := json.Marshal(c.Codec.EncodePosition(position))
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error at marshal position", Status: http.StatusInternalServerError, Cause: err})
 		return
@@ -140,10 +218,25 @@ func (c *PositionsController) GetAllPositions(w http.ResponseWriter, r *http.Req
 
 	positions, err := c.Repo.GetAll(r.Context())
 	if err != nil {
-		errorHandler(w, r, &HTTPError{Detail: "error at get all positions", Status: http.StatusInternalServerError})
+		errorHandler(w, r, &HTTPError{Detail: "error at getting all positions", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	if to := r.URL.Query().Get("currency"); to != "" {
+		if c.Rates == nil {
+			errorHandler(w, r, &HTTPError{Detail: "currency conversion is not configured", Status: http.StatusBadRequest})
+			return
+		}
+
+		for i := range positions {
+			if positions[i].Salary, err = c.Rates.Convert(positions[i].Salary, to); err != nil {
+				errorHandler(w, r, &HTTPError{Detail: "error converting salary", Status: http.StatusBadRequest, Cause: err})
+				return
+			}
+		}
 	}
 
-	response, err := json.Marshal(positions)
+	response, err := json.Marshal(dto.EncodePositions(c.Codec, positions))
 	if err != nil {
 		errorHandler(w, r, &HTTPError{Detail: "error at marshal positions", Status: http.StatusInternalServerError, Cause: err})
 		return
@@ -153,3 +246,115 @@ func (c *PositionsController) GetAllPositions(w http.ResponseWriter, r *http.Req
 	w.WriteHeader(http.StatusOK)
 	w.Write(response)
 }
+
+func (c *PositionsController) GetPositionAsOf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get position as of date", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	date, err := parseDate(r.URL.Query().Get("date"))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid date", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	positionID := r.PathValue("id")
+	position, err := c.Repo.GetAsOf(r.Context(), positionID, date)
+	if err != nil {
+		errorHandler(w, r, positionHistoryError(err, "error getting position as of date"))
+		return
+	}
+
+	response, err := json.Marshal(c.Codec.EncodePosition(*position))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal position", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func (c *PositionsController) GetSalaryHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get salary history", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	positionID := r.PathValue("id")
+	history, err := c.Repo.SalaryHistory(r.Context(), positionID)
+	if err != nil {
+		errorHandler(w, r, positionHistoryError(err, "error getting salary history"))
+		return
+	}
+
+	response, err := json.Marshal(dto.EncodeSalaryChanges(c.Codec, history))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal salary history", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func (c *PositionsController) ScheduleSalaryChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at schedule salary change", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error reading request body", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	change, err := c.Codec.DecodeSalaryChange(body)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	if err := change.Salary.Validate(); err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "invalid salary", Status: http.StatusBadRequest, Cause: err})
+		return
+	}
+
+	if !change.EffectiveFrom.After(time.Now()) {
+		errorHandler(w, r, &HTTPError{Detail: "effective date must be in the future", Status: http.StatusBadRequest})
+		return
+	}
+
+	change.PositionID = r.PathValue("id")
+	if err := c.Repo.ScheduleSalaryChange(r.Context(), change); err != nil {
+		errorHandler(w, r, positionHistoryError(err, "error scheduling salary change"))
+		return
+	}
+
+	response, err := json.Marshal(c.Codec.EncodeSalaryChange(change))
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal salary change", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(response)
+}
+
+// positionHistoryError maps the errors of the effective-dated operations on positions.
+func positionHistoryError(err error, detail string) *HTTPError {
+	switch {
+	case errors.Is(err, domain.ErrPositionNotFoundAsOf):
+		return &HTTPError{Detail: "position not found at given date", Status: http.StatusNotFound, Cause: err}
+	case errors.Is(err, domain.ErrPositionNotFound):
+		return &HTTPError{Detail: "position not found", Status: http.StatusNotFound, Cause: err}
+	case errors.Is(err, domain.ErrEffectiveDateNotInFuture):
+		return &HTTPError{Detail: "effective date must be in the future", Status: http.StatusBadRequest, Cause: err}
+	}
+	return &HTTPError{Detail: detail, Status: http.StatusInternalServerError, Cause: err}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dilyara4949/employees-api/internal/domain"
 )
@@ -31,7 +32,7 @@ func (p posRepoMock) Get(_ context.Context, id string) (*domain.Position, error)
 	return &domain.Position{
 		ID:     "id",
 		Name:   "name",
-		Salary: 100,
+		Salary: domain.Money{Amount: 10000, Currency: "USD"},
 	}, nil
 }
 
@@ -58,11 +59,77 @@ func (p posRepoMock) GetAll(_ context.Context) ([]domain.Position, error) {
 		{
 			ID:     "id",
 			Name:   "name",
-			Salary: 100,
+			Salary: domain.Money{Amount: 10000, Currency: "USD"},
 		},
 	}, nil
 }
 
+func (p posRepoMock) GetAsOf(_ context.Context, id string, date time.Time) (*domain.Position, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &domain.Position{
+		ID:     "id",
+		Name:   "name",
+		Salary: domain.Money{Amount: 10000, Currency: "USD"},
+	}, nil
+}
+
+func (p posRepoMock) SalaryHistory(_ context.Context, id string) ([]domain.SalaryChange, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []domain.SalaryChange{
+		{
+			PositionID:    "id",
+			Salary:        domain.Money{Amount: 10000, Currency: "USD"},
+			EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}, nil
+}
+
+func (p posRepoMock) ScheduleSalaryChange(_ context.Context, change domain.SalaryChange) error {
+	if p.err != nil {
+		return p.err
+	}
+	return nil
+}
+
+func (p posRepoMock) SearchName(_ context.Context, token string) (domain.SearchHits, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return domain.SearchHits{"id": 1}, nil
+}
+
+func (p posRepoMock) Iterate(_ context.Context, fn func(domain.Position) error) error {
+	if p.err != nil {
+		return p.err
+	}
+	return fn(domain.Position{
+		ID:     "id",
+		Name:   "name",
+		Salary: domain.Money{Amount: 10000, Currency: "USD"},
+	})
+}
+
+type uowMock struct {
+	employees domain.EmployeesRepository
+	positions domain.PositionsRepository
+}
+
+func (u uowMock) Do(ctx context.Context, fn func(ctx context.Context, tx domain.Tx) error) error {
+	return fn(ctx, u)
+}
+
+func (u uowMock) Employees() domain.EmployeesRepository {
+	return u.employees
+}
+
+func (u uowMock) Positions() domain.PositionsRepository {
+	return u.positions
+}
+
 func TestPositionsController_GetPosition(t *testing.T) {
 	tests := map[string]struct {
 		id       string
@@ -71,7 +138,7 @@ func TestPositionsController_GetPosition(t *testing.T) {
 	}{
 		"OK": {
 			id:       "1",
-			expected: "{\"id\":\"id\",\"name\":\"name\",\"salary\":100}",
+			expected: "{\"id\":\"id\",\"name\":\"name\",\"salary\":{\"amount\":10000,\"currency\":\"USD\"}}",
 			repo:     posRepoMock{},
 		},
 		"err": {
@@ -82,7 +149,7 @@ func TestPositionsController_GetPosition(t *testing.T) {
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			h := NewPositionsController(tt.repo)
+			h := NewPositionsController(tt.repo, nil, nil)
 
 			mux := http.NewServeMux()
 			mux.HandleFunc("GET /{id}", h.GetPosition)
@@ -109,6 +176,47 @@ func TestPositionsController_GetPosition(t *testing.T) {
 	}
 }
 
+// scheduledPosRepoMock has a salary change scheduled at next.
+type scheduledPosRepoMock struct {
+	posRepoMock
+	next time.Time
+}
+
+func (p scheduledPosRepoMock) SalaryHistory(ctx context.Context, id string) ([]domain.SalaryChange, error) {
+	history, err := p.posRepoMock.SalaryHistory(ctx, id)
+	return append(history, domain.SalaryChange{PositionID: id, Salary: domain.Money{Amount: 12000, Currency: "USD"}, EffectiveFrom: p.next}), err
+}
+
+func TestPositionsController_GetPosition_Expires(t *testing.T) {
+	next := time.Now().Add(24 * time.Hour)
+
+	tests := map[string]struct {
+		repo     domain.PositionsRepository
+		expected string
+	}{
+		"no scheduled change": {
+			repo: posRepoMock{},
+		},
+		"scheduled change": {
+			repo:     scheduledPosRepoMock{next: next},
+			expected: next.UTC().Format(http.TimeFormat),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /{id}", NewPositionsController(tt.repo, nil, nil).GetPosition)
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/id", nil))
+
+			if expires := rr.Header().Get("Expires"); rr.Code != http.StatusOK || expires != tt.expected {
+				t.Errorf(`expected 200 expiring at "%s", got %d "%s"`, tt.expected, rr.Code, expires)
+			}
+		})
+	}
+}
+
 func TestPositionsController_CreatePosition(t *testing.T) {
 	tests := map[string]struct {
 		body     string
@@ -116,8 +224,8 @@ func TestPositionsController_CreatePosition(t *testing.T) {
 		repo     posRepoMock
 	}{
 		"OK": {
-			body:     "{\"id\":\"id\",\"name\":\"name\",\"salary\":100}",
-			expected: "{\"id\":\"id\",\"name\":\"name\",\"salary\":100}",
+			body:     "{\"id\":\"id\",\"name\":\"name\",\"salary\":{\"amount\":10000,\"currency\":\"USD\"}}",
+			expected: "{\"id\":\"id\",\"name\":\"name\",\"salary\":{\"amount\":10000,\"currency\":\"USD\"}}",
 			repo:     posRepoMock{},
 		},
 		"Empty body": {
@@ -125,8 +233,13 @@ func TestPositionsController_CreatePosition(t *testing.T) {
 			expected: "invalid request body\n",
 			repo:     posRepoMock{},
 		},
+		"unknown currency": {
+			body:     "{\"id\":\"id\",\"name\":\"name\",\"salary\":{\"amount\":10000,\"currency\":\"ABC\"}}",
+			expected: "invalid salary\n",
+			repo:     posRepoMock{},
+		},
 		"err": {
-			body:     "{\"id\":\"err\",\"name\":\"name\",\"salary\":100}",
+			body:     "{\"id\":\"err\",\"name\":\"name\",\"salary\":{\"amount\":10000,\"currency\":\"USD\"}}",
 			expected: "error creating position\n",
 			repo:     posRepoMock{err: errors.New("error")},
 		},
@@ -134,7 +247,7 @@ func TestPositionsController_CreatePosition(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			h := NewPositionsController(tt.repo)
+			h := NewPositionsController(tt.repo, nil, nil)
 
 			mux := http.NewServeMux()
 			mux.HandleFunc("POST /", h.CreatePosition)
@@ -179,6 +292,24 @@ func TestPositionsController_DeletePosition(t *testing.T) {
 			expectedCode: 204,
 			repo:         posRepoMock{},
 		},
+		"reassign": {
+			id:           "used?reassign_to=10",
+			expected:     "",
+			expectedCode: 204,
+			repo:         posRepoMock{},
+		},
+		"in use": {
+			id:           "used",
+			expected:     "position has employees assigned, set reassign_to to move them\n",
+			expectedCode: 409,
+			repo:         posRepoMock{},
+		},
+		"scheduled": {
+			id:           "scheduled",
+			expected:     "position has employees assigned, set reassign_to to move them\n",
+			expectedCode: 409,
+			repo:         posRepoMock{},
+		},
 		"err": {
 			id:           "err",
 			expected:     "error deleting position\n",
@@ -189,7 +320,7 @@ func TestPositionsController_DeletePosition(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			h := NewPositionsController(tt.repo)
+			h := NewPositionsController(tt.repo, uowMock{employees: empRepoMock{}, positions: tt.repo}, nil)
 
 			mux := http.NewServeMux()
 			mux.HandleFunc("DELETE /{id}", h.DeletePosition)
@@ -234,8 +365,8 @@ func TestPositionsController_UpdatePosition(t *testing.T) {
 	}{
 		"OK": {
 			id:       "1",
-			body:     "{\"id\":\"1\",\"name\":\"updated name\",\"salary\":200}",
-			expected: "{\"id\":\"1\",\"name\":\"updated name\",\"salary\":200}",
+			body:     "{\"id\":\"1\",\"name\":\"updated name\",\"salary\":{\"amount\":20000,\"currency\":\"USD\"}}",
+			expected: "{\"id\":\"1\",\"name\":\"updated name\",\"salary\":{\"amount\":20000,\"currency\":\"USD\"}}",
 			repo:     posRepoMock{},
 		},
 		"Empty body": {
@@ -246,15 +377,21 @@ func TestPositionsController_UpdatePosition(t *testing.T) {
 		},
 		"err": {
 			id:       "err",
-			body:     "{\"err\":\"1\",\"name\":\"updated name\",\"salary\":200}",
+			body:     "{\"err\":\"1\",\"name\":\"updated name\",\"salary\":{\"amount\":20000,\"currency\":\"USD\"}}",
 			expected: "error updating position\n",
 			repo:     posRepoMock{err: errors.New("error")},
 		},
+		"band excludes employees": {
+			id:       "1",
+			body:     "{\"id\":\"1\",\"name\":\"updated name\",\"salary\":{\"amount\":20000,\"currency\":\"USD\"}}",
+			expected: "salary band excludes employees of the position\n",
+			repo:     posRepoMock{err: fmt.Errorf("%w: employee id: %w", domain.ErrBandExcludesEmployees, domain.ErrSalaryOutOfBand)},
+		},
 	}
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			h := NewPositionsController(tt.repo)
+			h := NewPositionsController(tt.repo, uowMock{employees: empRepoMock{}, positions: tt.repo}, nil)
 
 			mux := http.NewServeMux()
 			mux.HandleFunc("PUT /{id}", h.UpdatePosition)
@@ -292,7 +429,7 @@ func TestPositionsController_GetAllPositions(t *testing.T) {
 		repo     posRepoMock
 	}{
 		"OK": {
-			expected: "[{\"id\":\"id\",\"name\":\"name\",\"salary\":100}]",
+			expected: "[{\"id\":\"id\",\"name\":\"name\",\"salary\":{\"amount\":10000,\"currency\":\"USD\"}}]",
 			repo:     posRepoMock{},
 		},
 		"error": {
@@ -303,7 +440,7 @@ func TestPositionsController_GetAllPositions(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			h := NewPositionsController(tt.repo)
+			h := NewPositionsController(tt.repo, nil, nil)
 
 			mux := http.NewServeMux()
 			mux.HandleFunc("GET /", h.GetAllPositions)
@@ -334,3 +471,183 @@ func TestPositionsController_GetAllPositions(t *testing.T) {
 		})
 	}
 }
+
+func TestPositionsController_GetPositionAsOf(t *testing.T) {
+	tests := map[string]struct {
+		date     string
+		expected string
+		repo     posRepoMock
+	}{
+		"OK": {
+			date:     "2024-01-01",
+			expected: "{\"id\":\"id\",\"name\":\"name\",\"salary\":{\"amount\":10000,\"currency\":\"USD\"}}",
+			repo:     posRepoMock{},
+		},
+		"invalid date": {
+			date:     "yesterday",
+			expected: "invalid date\n",
+			repo:     posRepoMock{},
+		},
+		"err": {
+			date:     "2024-01-01T10:00:00Z",
+			expected: "error getting position as of date\n",
+			repo:     posRepoMock{err: errors.New("error")},
+		},
+		"not found at date": {
+			date:     "2020-01-01",
+			expected: "position not found at given date\n",
+			repo:     posRepoMock{err: domain.ErrPositionNotFoundAsOf},
+		},
+		"not found": {
+			date:     "2024-01-01",
+			expected: "position not found\n",
+			repo:     posRepoMock{err: domain.ErrPositionNotFound},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewPositionsController(tt.repo, nil, nil)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /{id}/as-of", h.GetPositionAsOf)
+
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			req, err := http.NewRequest("GET", fmt.Sprintf("%s/id/as-of?date=%s", svr.URL, tt.date), http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cl := http.Client{}
+			resp, err := cl.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			response, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res := string(response); res != tt.expected {
+				t.Fatalf(`expected "%s", got "%s"`, tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestPositionsController_GetSalaryHistory(t *testing.T) {
+	tests := map[string]struct {
+		expected string
+		repo     posRepoMock
+	}{
+		"OK": {
+			expected: "[{\"position_id\":\"id\",\"salary\":{\"amount\":10000,\"currency\":\"USD\"},\"effective_from\":\"2024-01-01T00:00:00Z\"}]",
+			repo:     posRepoMock{},
+		},
+		"err": {
+			expected: "error getting salary history\n",
+			repo:     posRepoMock{err: errors.New("error")},
+		},
+		"not found": {
+			expected: "position not found\n",
+			repo:     posRepoMock{err: domain.ErrPositionNotFound},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewPositionsController(tt.repo, nil, nil)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /{id}/history", h.GetSalaryHistory)
+
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			req, err := http.NewRequest("GET", fmt.Sprintf("%s/id/history", svr.URL), http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cl := http.Client{}
+			resp, err := cl.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			response, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res := string(response); res != tt.expected {
+				t.Fatalf(`expected "%s", got "%s"`, tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestPositionsController_ScheduleSalaryChange(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := map[string]struct {
+		body     string
+		expected string
+		repo     posRepoMock
+	}{
+		"OK": {
+			body:     fmt.Sprintf("{\"salary\":{\"amount\":20000,\"currency\":\"USD\"},\"effective_from\":\"%s\"}", future),
+			expected: fmt.Sprintf("{\"position_id\":\"id\",\"salary\":{\"amount\":20000,\"currency\":\"USD\"},\"effective_from\":\"%s\"}", future),
+			repo:     posRepoMock{},
+		},
+		"past date": {
+			body:     "{\"salary\":{\"amount\":20000,\"currency\":\"USD\"},\"effective_from\":\"2020-01-01T00:00:00Z\"}",
+			expected: "effective date must be in the future\n",
+			repo:     posRepoMock{},
+		},
+		"err": {
+			body:     fmt.Sprintf("{\"salary\":{\"amount\":20000,\"currency\":\"USD\"},\"effective_from\":\"%s\"}", future),
+			expected: "error scheduling salary change\n",
+			repo:     posRepoMock{err: errors.New("error")},
+		},
+		"not found": {
+			body:     fmt.Sprintf("{\"salary\":{\"amount\":20000,\"currency\":\"USD\"},\"effective_from\":\"%s\"}", future),
+			expected: "position not found\n",
+			repo:     posRepoMock{err: domain.ErrPositionNotFound},
+		},
+		"date passed": {
+			body:     fmt.Sprintf("{\"salary\":{\"amount\":20000,\"currency\":\"USD\"},\"effective_from\":\"%s\"}", future),
+			expected: "effective date must be in the future\n",
+			repo:     posRepoMock{err: domain.ErrEffectiveDateNotInFuture},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewPositionsController(tt.repo, nil, nil)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /{id}/history", h.ScheduleSalaryChange)
+
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			req, err := http.NewRequest("POST", fmt.Sprintf("%s/id/history", svr.URL), strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cl := http.Client{}
+			resp, err := cl.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			response, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res := string(response); res != tt.expected {
+				t.Fatalf(`expected "%s", got "%s"`, tt.expected, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	"github.com/dilyara4949/employees-api/internal/webhook"
+)
+
+type WebhooksController struct {
+	Store      *webhook.Store
+	Dispatcher *webhook.Dispatcher
+}
+
+func NewWebhooksController(store *webhook.Store, dispatcher *webhook.Dispatcher) *WebhooksController {
+	return &WebhooksController{Store: store, Dispatcher: dispatcher}
+}
+
+// webhookRequest is the body of create and update requests, subscriptions are active unless active is false.
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+func (c *WebhooksController) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at create webhook", Status: http.StatusMethodNotAllowed})
+		return
+	}
+	if !authorizeWebhookAdmin(w, r) {
+		return
+	}
+
+	sub, httpErr := parseWebhookRequest(r)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
+	sub.TenantID = tenant.From(r.Context())
+	if err := c.Store.Create(&sub); err != nil {
+		errorHandler(w, r, webhookError(err, "error creating webhook"))
+		return
+	}
+
+	// the secret is only returned on creation
+	writeWebhookResponse(w, r, http.StatusCreated, sub)
+}
+
+func (c *WebhooksController) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get webhook", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	sub, err := c.subscription(r)
+	if err != nil {
+		errorHandler(w, r, webhookError(err, "error getting webhook"))
+		return
+	}
+
+	sub.Secret = ""
+	writeWebhookResponse(w, r, http.StatusOK, sub)
+}
+
+func (c *WebhooksController) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at update webhook", Status: http.StatusMethodNotAllowed})
+		return
+	}
+	if !authorizeWebhookAdmin(w, r) {
+		return
+	}
+
+	sub, httpErr := parseWebhookRequest(r)
+	if httpErr != nil {
+		errorHandler(w, r, httpErr)
+		return
+	}
+
+	if _, err := c.subscription(r); err != nil {
+		errorHandler(w, r, webhookError(err, "error updating webhook"))
+		return
+	}
+
+	sub.ID = r.PathValue("id")
+	if err := c.Store.Update(&sub); err != nil {
+		errorHandler(w, r, webhookError(err, "error updating webhook"))
+		return
+	}
+
+	sub.Secret = ""
+	writeWebhookResponse(w, r, http.StatusOK, sub)
+}
+
+func (c *WebhooksController) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at delete webhook", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	if _, err := c.subscription(r); err != nil {
+		errorHandler(w, r, webhookError(err, "error deleting webhook"))
+		return
+	}
+
+	if err := c.Store.Delete(r.PathValue("id")); err != nil {
+		errorHandler(w, r, webhookError(err, "error deleting webhook"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *WebhooksController) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get all webhooks", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	subs := make([]webhook.Subscription, 0)
+	for _, sub := range c.Store.GetAll() {
+		if sub.TenantID == tenant.From(r.Context()) {
+			sub.Secret = ""
+			subs = append(subs, sub)
+		}
+	}
+
+	writeWebhookResponse(w, r, http.StatusOK, subs)
+}
+
+// GetDeliveries returns the last deliveries of a webhook, newest first.
+func (c *WebhooksController) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get webhook deliveries", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	if _, err := c.subscription(r); err != nil {
+		errorHandler(w, r, webhookError(err, "error getting webhook deliveries"))
+		return
+	}
+
+	deliveries, err := c.Store.Deliveries(r.PathValue("id"))
+	if err != nil {
+		errorHandler(w, r, webhookError(err, "error getting webhook deliveries"))
+		return
+	}
+
+	writeWebhookResponse(w, r, http.StatusOK, deliveries)
+}
+
+// GetDeadLetters returns the deliveries that failed all their attempts, newest first.
+func (c *WebhooksController) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at get webhook dead letters", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	writeWebhookResponse(w, r, http.StatusOK, c.deadLetters(r))
+}
+
+// RetryDeadLetter delivers a dead letter again, the delivery is retried in the background.
+func (c *WebhooksController) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errorHandler(w, r, &HTTPError{Detail: "invalid method at retry webhook delivery", Status: http.StatusMethodNotAllowed})
+		return
+	}
+
+	id := r.PathValue("id")
+	if !slices.ContainsFunc(c.deadLetters(r), func(delivery webhook.Delivery) bool { return delivery.ID == id }) {
+		errorHandler(w, r, webhookError(webhook.ErrDeliveryNotFound, "error retrying webhook delivery"))
+		return
+	}
+
+	delivery, err := c.Dispatcher.Redeliver(id)
+	if err != nil {
+		errorHandler(w, r, webhookError(err, "error retrying webhook delivery"))
+		return
+	}
+
+	writeWebhookResponse(w, r, http.StatusAccepted, delivery)
+}
+
+// subscription returns the subscription of the path, the subscriptions of other tenants are not found.
+func (c *WebhooksController) subscription(r *http.Request) (webhook.Subscription, error) {
+	sub, err := c.Store.Get(r.PathValue("id"))
+	if err != nil {
+		return webhook.Subscription{}, err
+	}
+	if sub.TenantID != tenant.From(r.Context()) {
+		return webhook.Subscription{}, webhook.ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+// deadLetters returns the dead letters of the tenant of the request, newest first.
+func (c *WebhooksController) deadLetters(r *http.Request) []webhook.Delivery {
+	deliveries := make([]webhook.Delivery, 0)
+	for _, delivery := range c.Store.DeadLetters() {
+		if delivery.TenantID == tenant.From(r.Context()) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries
+}
+
+// authorizeWebhookAdmin allows setting the target of webhooks, which receive every change of the tenant, to JWTs
+// with the webhook.RoleAdmin role and to API keys, which were granted the webhooks:write scope of the route.
+func authorizeWebhookAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := r.Context().Value(middleware.APIKey).(apikey.Key); ok || middleware.HasRole(r.Context(), webhook.RoleAdmin) {
+		return true
+	}
+	errorHandler(w, r, &HTTPError{Detail: "registering webhooks requires the " + webhook.RoleAdmin + " role", Status: http.StatusForbidden})
+	return false
+}
+
+func parseWebhookRequest(r *http.Request) (webhook.Subscription, *HTTPError) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return webhook.Subscription{}, &HTTPError{Detail: "error reading request body", Status: http.StatusBadRequest, Cause: err}
+	}
+
+	var req webhookRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return webhook.Subscription{}, &HTTPError{Detail: "invalid request body", Status: http.StatusBadRequest, Cause: err}
+	}
+
+	sub := webhook.Subscription{URL: req.URL, Events: req.Events, Secret: req.Secret, Active: true}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+	return sub, nil
+}
+
+func webhookError(err error, detail string) *HTTPError {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidSubscription):
+		return &HTTPError{Detail: err.Error(), Status: http.StatusBadRequest, Cause: err}
+	case errors.Is(err, webhook.ErrSubscriptionNotFound):
+		return &HTTPError{Detail: "webhook not found", Status: http.StatusNotFound, Cause: err}
+	case errors.Is(err, webhook.ErrDeliveryNotFound):
+		return &HTTPError{Detail: "dead letter not found", Status: http.StatusNotFound, Cause: err}
+	}
+	return &HTTPError{Detail: detail, Status: http.StatusInternalServerError, Cause: err}
+}
+
+func writeWebhookResponse(w http.ResponseWriter, r *http.Request, statusCode int, v any) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		errorHandler(w, r, &HTTPError{Detail: "error at marshal webhook response", Status: http.StatusInternalServerError, Cause: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(response)
+}
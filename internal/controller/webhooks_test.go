@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/webhook"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// webhookAdmin returns the request authenticated with a token of the webhook.RoleAdmin role.
+func webhookAdmin(r *http.Request) *http.Request {
+	claims := jwt.MapClaims{"sub": "ann", "roles": []interface{}{webhook.RoleAdmin}}
+	return r.WithContext(context.WithValue(r.Context(), middleware.JWTClaims, claims))
+}
+
+func newWebhooksController(t *testing.T) (*WebhooksController, webhook.Subscription) {
+	t.Helper()
+
+	store := webhook.NewStore()
+	sub := webhook.Subscription{URL: "https://payroll.example.com/hook", Events: []string{"employee.created"}, Active: true}
+	if err := store.Create(&sub); err != nil {
+		t.Fatal(err)
+	}
+	return NewWebhooksController(store, webhook.NewDispatcher(store, events.NewBus(10))), sub
+}
+
+func TestWebhooksController_CreateWebhook(t *testing.T) {
+	tests := map[string]struct {
+		body         string
+		authenticate func(r *http.Request) *http.Request
+		expectedCode int
+		expected     string
+	}{
+		"created": {
+			body:         `{"url":"https://it.example.com/hook","events":["employee.created","employee.position_changed"],"secret":"s3cret"}`,
+			expectedCode: 201,
+			expected:     `"url":"https://it.example.com/hook","events":["employee.created","employee.position_changed"],"secret":"s3cret","active":true`,
+		},
+		"inactive": {
+			body:         `{"url":"https://it.example.com/hook","events":["employee.deleted"],"active":false}`,
+			expectedCode: 201,
+			expected:     `"active":false`,
+		},
+		"unknown event": {
+			body:         `{"url":"https://it.example.com/hook","events":["employee.hired"]}`,
+			expectedCode: 400,
+			expected:     "invalid webhook subscription: unknown event \"employee.hired\"\n",
+		},
+		"invalid url": {
+			body:         `{"url":"it.example.com","events":["employee.created"]}`,
+			expectedCode: 400,
+			expected:     "invalid webhook subscription: url must be an absolute http or https URL\n",
+		},
+		"invalid body": {
+			body:         `{"url":`,
+			expectedCode: 400,
+			expected:     "invalid request body\n",
+		},
+		"private url": {
+			body:         `{"url":"http://10.0.0.5/hook","events":["employee.created"]}`,
+			expectedCode: 400,
+			expected:     "invalid webhook subscription: url must not target a private, loopback or link-local address\n",
+		},
+		"api key": {
+			body: `{"url":"https://it.example.com/hook","events":["employee.created"]}`,
+			authenticate: func(r *http.Request) *http.Request {
+				key := apikey.Key{ID: "1", Scopes: []string{"webhooks:write"}}
+				return r.WithContext(context.WithValue(r.Context(), middleware.APIKey, key))
+			},
+			expectedCode: 201,
+			expected:     `"url":"https://it.example.com/hook"`,
+		},
+		"without role": {
+			body: `{"url":"https://it.example.com/hook","events":["employee.created"]}`,
+			authenticate: func(r *http.Request) *http.Request {
+				return r.WithContext(context.WithValue(r.Context(), middleware.JWTClaims, jwt.MapClaims{"sub": "bob"}))
+			},
+			expectedCode: 403,
+			expected:     "registering webhooks requires the webhook_admin role\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newWebhooksController(t)
+
+			authenticate := tc.authenticate
+			if authenticate == nil {
+				authenticate = webhookAdmin
+			}
+
+			req := authenticate(httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tc.body)))
+			rr := httptest.NewRecorder()
+			c.CreateWebhook(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expected) {
+				t.Errorf("expected body to contain %q, got %q", tc.expected, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhooksController_GetWebhook(t *testing.T) {
+	c, sub := newWebhooksController(t)
+
+	tests := map[string]struct {
+		id           string
+		expectedCode int
+		expected     string
+	}{
+		"found": {
+			id:           sub.ID,
+			expectedCode: 200,
+			expected:     `{"id":"` + sub.ID + `","url":"https://payroll.example.com/hook","events":["employee.created"],"active":true,`,
+		},
+		"not found": {
+			id:           "unknown",
+			expectedCode: 404,
+			expected:     "webhook not found\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/webhooks/"+tc.id, nil)
+			req.SetPathValue("id", tc.id)
+			rr := httptest.NewRecorder()
+			c.GetWebhook(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, rr.Code)
+			}
+			if !strings.HasPrefix(rr.Body.String(), tc.expected) {
+				t.Errorf("expected body to start with %q, got %q", tc.expected, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhooksController_UpdateWebhook(t *testing.T) {
+	c, sub := newWebhooksController(t)
+
+	req := webhookAdmin(httptest.NewRequest(http.MethodPut, "/webhooks/"+sub.ID, strings.NewReader(`{"url":"https://payroll.example.com/v2","events":["employee.deleted"]}`)))
+	req.SetPathValue("id", sub.ID)
+	rr := httptest.NewRecorder()
+	c.UpdateWebhook(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	updated, err := c.Store.Get(sub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.URL != "https://payroll.example.com/v2" || updated.Secret != sub.Secret || !updated.CreatedAt.Equal(sub.CreatedAt) {
+		t.Errorf("unexpected subscription %+v", updated)
+	}
+}
+
+func TestWebhooksController_GetAllWebhooks(t *testing.T) {
+	c, sub := newWebhooksController(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+	rr := httptest.NewRecorder()
+	c.GetAllWebhooks(rr, req)
+
+	var subs []webhook.Subscription
+	if err := json.Unmarshal(rr.Body.Bytes(), &subs); err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].ID != sub.ID || subs[0].Secret != "" {
+		t.Errorf("expected the subscription without secret, got %+v", subs)
+	}
+}
+
+func TestWebhooksController_DeleteWebhook(t *testing.T) {
+	c, sub := newWebhooksController(t)
+
+	// the second delete of the same webhook doesn't find it
+	for _, expectedCode := range []int{http.StatusNoContent, http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodDelete, "/webhooks/"+sub.ID, nil)
+		req.SetPathValue("id", sub.ID)
+		rr := httptest.NewRecorder()
+		c.DeleteWebhook(rr, req)
+
+		if rr.Code != expectedCode {
+			t.Errorf("expected code %d, got %d", expectedCode, rr.Code)
+		}
+	}
+}
+
+func TestWebhooksController_RetryDeadLetter(t *testing.T) {
+	c, _ := newWebhooksController(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/dead-letters/unknown/retry", nil)
+	req.SetPathValue("id", "unknown")
+	rr := httptest.NewRecorder()
+	c.RetryDeadLetter(rr, req)
+
+	if rr.Code != http.StatusNotFound || rr.Body.String() != "dead letter not found\n" {
+		t.Errorf("expected 404 dead letter not found, got %d %q", rr.Code, rr.Body.String())
+	}
+}
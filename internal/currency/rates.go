@@ -0,0 +1,84 @@
+package currency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+var ErrMissingRate = errors.New("missing exchange rate")
+
+// Rates is an exchange-rate table, each rate is the amount of the currency for one unit of Base.
+type Rates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// LoadRates reads an exchange-rate table from a JSON file, e.g.
+// {"base": "USD", "rates": {"EUR": 0.92, "KZT": 447.5}}.
+func LoadRates(path string) (*Rates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading exchange rates: %w", err)
+	}
+
+	var rates Rates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("error parsing exchange rates: %w", err)
+	}
+
+	if _, err := domain.CurrencyExponent(rates.Base); err != nil {
+		return nil, fmt.Errorf("invalid base currency: %w", err)
+	}
+	for code, rate := range rates.Rates {
+		if _, err := domain.CurrencyExponent(code); err != nil {
+			return nil, fmt.Errorf("invalid exchange rate: %w", err)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate for %s: %v", code, rate)
+		}
+	}
+	return &rates, nil
+}
+
+func (r *Rates) rate(currency string) (float64, error) {
+	if currency == r.Base {
+		return 1, nil
+	}
+	if rate, ok := r.Rates[currency]; ok {
+		return rate, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrMissingRate, currency)
+}
+
+// Convert converts money to the given currency, rounding to the nearest minor unit.
+func (r *Rates) Convert(m domain.Money, to string) (domain.Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	fromExp, err := domain.CurrencyExponent(m.Currency)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	toExp, err := domain.CurrencyExponent(to)
+	if err != nil {
+		return domain.Money{}, err
+	}
+
+	fromRate, err := r.rate(m.Currency)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	toRate, err := r.rate(to)
+	if err != nil {
+		return domain.Money{}, err
+	}
+
+	amount := float64(m.Amount) / fromRate * toRate * math.Pow10(toExp-fromExp)
+	return domain.Money{Amount: int64(math.Round(amount)), Currency: to}, nil
+}
@@ -0,0 +1,109 @@
+package currency
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+func TestRates_Convert(t *testing.T) {
+	rates := &Rates{
+		Base:  "USD",
+		Rates: map[string]float64{"EUR": 0.5, "JPY": 150, "KWD": 0.25},
+	}
+
+	tests := map[string]struct {
+		money   domain.Money
+		to      string
+		want    domain.Money
+		wantErr error
+	}{
+		"same currency": {
+			money: domain.Money{Amount: 100, Currency: "USD"},
+			to:    "USD",
+			want:  domain.Money{Amount: 100, Currency: "USD"},
+		},
+		"from base": {
+			money: domain.Money{Amount: 1000, Currency: "USD"},
+			to:    "EUR",
+			want:  domain.Money{Amount: 500, Currency: "EUR"},
+		},
+		"to base": {
+			money: domain.Money{Amount: 500, Currency: "EUR"},
+			to:    "USD",
+			want:  domain.Money{Amount: 1000, Currency: "USD"},
+		},
+		"zero exponent": {
+			money: domain.Money{Amount: 1050, Currency: "USD"},
+			to:    "JPY",
+			want:  domain.Money{Amount: 1575, Currency: "JPY"},
+		},
+		"three digit exponent": {
+			money: domain.Money{Amount: 400, Currency: "EUR"},
+			to:    "KWD",
+			want:  domain.Money{Amount: 2000, Currency: "KWD"},
+		},
+		"missing rate": {
+			money:   domain.Money{Amount: 100, Currency: "USD"},
+			to:      "GBP",
+			wantErr: ErrMissingRate,
+		},
+		"unknown currency": {
+			money:   domain.Money{Amount: 100, Currency: "USD"},
+			to:      "XXX",
+			wantErr: domain.ErrUnknownCurrency,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := rates.Convert(tt.money, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadRates(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		wantErr bool
+	}{
+		"OK": {
+			content: `{"base":"USD","rates":{"EUR":0.92,"KZT":447.5}}`,
+		},
+		"unknown base": {
+			content: `{"base":"ABC","rates":{"EUR":0.92}}`,
+			wantErr: true,
+		},
+		"negative rate": {
+			content: `{"base":"USD","rates":{"EUR":-1}}`,
+			wantErr: true,
+		},
+		"invalid json": {
+			content: `rates`,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rates.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadRates(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS employee_positions;
+DROP TABLE IF EXISTS position_salaries;
@@ -0,0 +1,21 @@
+CREATE TABLE position_salaries (
+                           position_id VARCHAR NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+                           salary INT NOT NULL,
+                           effective_from TIMESTAMPTZ NOT NULL,
+                           created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+                           PRIMARY KEY (position_id, effective_from)
+);
+
+CREATE TABLE employee_positions (
+                           employee_id VARCHAR NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+                           position_id VARCHAR NOT NULL REFERENCES positions(id),
+                           effective_from TIMESTAMPTZ NOT NULL,
+                           created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+                           PRIMARY KEY (employee_id, effective_from)
+);
+
+INSERT INTO position_salaries (position_id, salary, effective_from)
+SELECT id, salary, COALESCE(created_at, CURRENT_TIMESTAMP) FROM positions;
+
+INSERT INTO employee_positions (employee_id, position_id, effective_from)
+SELECT id, position_id, COALESCE(created_at, CURRENT_TIMESTAMP) FROM employees WHERE position_id IS NOT NULL;
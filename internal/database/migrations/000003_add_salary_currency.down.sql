@@ -0,0 +1,9 @@
+UPDATE position_salaries SET salary_amount = salary_amount / 100;
+ALTER TABLE position_salaries DROP COLUMN salary_currency;
+ALTER TABLE position_salaries RENAME COLUMN salary_amount TO salary;
+ALTER TABLE position_salaries ALTER COLUMN salary TYPE INT;
+
+UPDATE positions SET salary_amount = salary_amount / 100;
+ALTER TABLE positions DROP COLUMN salary_currency;
+ALTER TABLE positions RENAME COLUMN salary_amount TO salary;
+ALTER TABLE positions ALTER COLUMN salary TYPE INT;
@@ -0,0 +1,9 @@
+ALTER TABLE positions ALTER COLUMN salary TYPE BIGINT;
+ALTER TABLE positions RENAME COLUMN salary TO salary_amount;
+ALTER TABLE positions ADD COLUMN salary_currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE positions SET salary_amount = salary_amount * 100;
+
+ALTER TABLE position_salaries ALTER COLUMN salary TYPE BIGINT;
+ALTER TABLE position_salaries RENAME COLUMN salary TO salary_amount;
+ALTER TABLE position_salaries ADD COLUMN salary_currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE position_salaries SET salary_amount = salary_amount * 100;
@@ -0,0 +1,9 @@
+ALTER TABLE employees DROP COLUMN salary_override;
+ALTER TABLE employees DROP COLUMN salary_currency;
+ALTER TABLE employees DROP COLUMN salary_amount;
+
+ALTER TABLE positions DROP CONSTRAINT positions_band_check;
+ALTER TABLE positions DROP COLUMN band_currency;
+ALTER TABLE positions DROP COLUMN band_max_amount;
+ALTER TABLE positions DROP COLUMN band_mid_amount;
+ALTER TABLE positions DROP COLUMN band_min_amount;
@@ -0,0 +1,10 @@
+ALTER TABLE positions ADD COLUMN band_min_amount BIGINT;
+ALTER TABLE positions ADD COLUMN band_mid_amount BIGINT;
+ALTER TABLE positions ADD COLUMN band_max_amount BIGINT;
+ALTER TABLE positions ADD COLUMN band_currency CHAR(3);
+ALTER TABLE positions ADD CONSTRAINT positions_band_check
+    CHECK (band_min_amount <= band_mid_amount AND band_mid_amount <= band_max_amount);
+
+ALTER TABLE employees ADD COLUMN salary_amount BIGINT;
+ALTER TABLE employees ADD COLUMN salary_currency CHAR(3);
+ALTER TABLE employees ADD COLUMN salary_override BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS employees_tenant_id_idx;
+ALTER TABLE employees DROP COLUMN tenant_id;
+
+DROP INDEX IF EXISTS positions_tenant_id_idx;
+ALTER TABLE positions DROP COLUMN tenant_id;
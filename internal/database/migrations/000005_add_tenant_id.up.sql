@@ -0,0 +1,5 @@
+ALTER TABLE positions ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX positions_tenant_id_idx ON positions (tenant_id);
+
+ALTER TABLE employees ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX employees_tenant_id_idx ON employees (tenant_id);
@@ -1,12 +1,51 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrEmployeeNotFound = errors.New("employee not found")
+	// ErrEmployeeNotFoundAsOf is returned for dates before the employee was created, it matches ErrEmployeeNotFound.
+	ErrEmployeeNotFoundAsOf = fmt.Errorf("%w at given date", ErrEmployeeNotFound)
+	// ErrEffectiveDateNotInFuture rejects scheduled position and salary changes that would take effect right away.
+	ErrEffectiveDateNotInFuture = errors.New("effective date must be in the future")
+)
 
 type Employee struct {
 	ID         string `json:"id"`
 	FirstName  string `json:"firstname"`
 	LastName   string `json:"lastname"`
 	PositionID string `json:"position_id"`
+	Salary     *Money `json:"salary,omitempty"`
+	// SalaryOverride allows a salary outside of the position band, setting it requires RoleCompensationAdmin.
+	SalaryOverride bool `json:"salary_override,omitempty"`
+	// CompaRatio is the salary divided by the position band midpoint, it is computed on read.
+	CompaRatio float64 `json:"compa_ratio,omitempty"`
+	// TenantID is the tenant owning the employee, it is set on create from the context.
+	TenantID string `json:"-"`
+}
+
+// RoleCompensationAdmin is the role allowed to set salaries outside of position bands.
+const RoleCompensationAdmin = "compensation_admin"
+
+// DefaultSearchLimit is the number of search results returned when the limit isn't positive.
+const DefaultSearchLimit = 50
+
+// PositionAssignment is an effective-dated assignment of an employee to a position.
+type PositionAssignment struct {
+	EmployeeID    string    `json:"employee_id"`
+	PositionID    string    `json:"position_id"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+// EmployeeSearchResult is an employee matching a search query, results with a higher score rank first.
+type EmployeeSearchResult struct {
+	Employee
+	Score float64 `json:"score"`
 }
 
 type EmployeesRepository interface {
@@ -15,4 +54,15 @@ type EmployeesRepository interface {
 	Update(ctx context.Context, emp Employee) error
 	Delete(ctx context.Context, id string) error
 	GetAll(ctx context.Context) ([]Employee, error)
+	GetAsOf(ctx context.Context, id string, date time.Time) (*Employee, error)
+	PositionHistory(ctx context.Context, id string) ([]PositionAssignment, error)
+	SchedulePositionChange(ctx context.Context, assignment PositionAssignment) error
+	// Search returns at most limit results, or DefaultSearchLimit if limit isn't positive.
+	Search(ctx context.Context, query string, limit int) ([]EmployeeSearchResult, error)
+	// ListByPosition returns the employees currently assigned to the position.
+	ListByPosition(ctx context.Context, positionID string) ([]Employee, error)
+	// ListScheduledForPosition returns the scheduled assignments to the position that haven't taken effect yet.
+	ListScheduledForPosition(ctx context.Context, positionID string) ([]PositionAssignment, error)
+	// Iterate calls fn for every employee until fn returns an error, without loading all employees at once.
+	Iterate(ctx context.Context, fn func(Employee) error) error
 }
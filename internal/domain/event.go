@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+type EntityType string
+
+const (
+	EntityEmployee EntityType = "employee"
+	EntityPosition EntityType = "position"
+)
+
+// Event is a change of an employee or a position, it carries the entity as it is after the change,
+// or before it for deletes. Updates also carry the entity as it was before.
+type Event struct {
+	// Token identifies the event in the stream, subscribing with it resumes after the event. It is set on publish.
+	Token            string     `json:"token"`
+	Type             EventType  `json:"type"`
+	Entity           EntityType `json:"entity"`
+	EntityID         string     `json:"entity_id"`
+	Employee         *Employee  `json:"employee,omitempty"`
+	Position         *Position  `json:"position,omitempty"`
+	PreviousEmployee *Employee  `json:"previous_employee,omitempty"`
+	PreviousPosition *Position  `json:"previous_position,omitempty"`
+	Time             time.Time  `json:"time"`
+	// TenantID is the tenant of the changed entity, it is set on publish.
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+const (
+	EventEmployeePositionChanged = "employee.position_changed"
+	EventPositionSalaryChanged   = "position.salary_changed"
+)
+
+// Names returns the names of the event, e.g. employee.created. Updates moving an employee to another position
+// or changing the salary of a position are also named employee.position_changed and position.salary_changed.
+func (e Event) Names() []string {
+	names := []string{string(e.Entity) + "." + string(e.Type)}
+	if e.Type != EventUpdated {
+		return names
+	}
+
+	if e.Employee != nil && e.PreviousEmployee != nil && e.Employee.PositionID != e.PreviousEmployee.PositionID {
+		names = append(names, EventEmployeePositionChanged)
+	}
+	if e.Position != nil && e.PreviousPosition != nil && e.Position.Salary != e.PreviousPosition.Salary {
+		names = append(names, EventPositionSalaryChanged)
+	}
+	return names
+}
+
+type EventPublisher interface {
+	Publish(event Event)
+}
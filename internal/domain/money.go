@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Money is an amount in minor units of an ISO 4217 currency, e.g. {15000, "USD"} is $150.00.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// currencyExponents maps supported ISO 4217 currency codes to the number of their minor unit digits.
+var currencyExponents = map[string]int{
+	"AED": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2,
+	"DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2,
+	"JOD": 3, "JPY": 0, "KGS": 2, "KRW": 0, "KWD": 3, "KZT": 2, "MXN": 2, "NOK": 2,
+	"NZD": 2, "OMR": 3, "PLN": 2, "RUB": 2, "SAR": 2, "SEK": 2, "SGD": 2, "THB": 2,
+	"TRY": 2, "TWD": 2, "UAH": 2, "USD": 2, "UZS": 2, "VND": 0, "ZAR": 2,
+}
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrNegativeAmount  = errors.New("amount must not be negative")
+)
+
+// CurrencyExponent returns the number of minor unit digits of the currency.
+func CurrencyExponent(currency string) (int, error) {
+	exp, ok := currencyExponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return exp, nil
+}
+
+func (m Money) Validate() error {
+	if _, err := CurrencyExponent(m.Currency); err != nil {
+		return err
+	}
+	if m.Amount < 0 {
+		return ErrNegativeAmount
+	}
+	return nil
+}
+
+// SalaryBand is the salary range of a position.
+type SalaryBand struct {
+	Min Money `json:"min"`
+	Mid Money `json:"mid"`
+	Max Money `json:"max"`
+}
+
+var (
+	ErrInvalidBand     = errors.New("invalid salary band")
+	ErrSalaryOutOfBand = errors.New("salary is out of position band")
+)
+
+func (b SalaryBand) Validate() error {
+	for _, m := range []Money{b.Min, b.Mid, b.Max} {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidBand, err)
+		}
+	}
+	if b.Min.Currency != b.Mid.Currency || b.Mid.Currency != b.Max.Currency {
+		return fmt.Errorf("%w: min, mid and max must have the same currency", ErrInvalidBand)
+	}
+	if b.Min.Amount > b.Mid.Amount || b.Mid.Amount > b.Max.Amount {
+		return fmt.Errorf("%w: expected min <= mid <= max", ErrInvalidBand)
+	}
+	return nil
+}
+
+// Contains reports an error if the salary is not within the band.
+func (b SalaryBand) Contains(salary Money) error {
+	if salary.Currency != b.Mid.Currency {
+		return fmt.Errorf("%w: expected currency %s, got %s", ErrSalaryOutOfBand, b.Mid.Currency, salary.Currency)
+	}
+	if salary.Amount < b.Min.Amount || salary.Amount > b.Max.Amount {
+		return fmt.Errorf("%w: expected between %d and %d", ErrSalaryOutOfBand, b.Min.Amount, b.Max.Amount)
+	}
+	return nil
+}
+
+// CompaRatio returns the salary divided by the band midpoint, or false if it can't be computed.
+func (b SalaryBand) CompaRatio(salary Money) (float64, bool) {
+	if salary.Currency != b.Mid.Currency || b.Mid.Amount == 0 {
+		return 0, false
+	}
+	return math.Round(float64(salary.Amount)/float64(b.Mid.Amount)*1000) / 1000, true
+}
@@ -1,17 +1,62 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrPositionNotFound = errors.New("position not found")
+	// ErrPositionNotFoundAsOf is returned for dates before the position was created, it matches ErrPositionNotFound.
+	ErrPositionNotFoundAsOf = fmt.Errorf("%w at given date", ErrPositionNotFound)
+)
 
 type Position struct {
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	Salary int    `json:"salary"`
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Salary Money       `json:"salary"`
+	Band   *SalaryBand `json:"band,omitempty"`
+	// TenantID is the tenant owning the position, it is set on create from the context.
+	TenantID string `json:"-"`
+}
+
+// CheckSalary validates the employee salary against the position band unless it is overridden.
+func (p Position) CheckSalary(employee Employee) error {
+	if employee.Salary == nil {
+		return nil
+	}
+	if err := employee.Salary.Validate(); err != nil {
+		return err
+	}
+	if p.Band == nil || employee.SalaryOverride {
+		return nil
+	}
+	return p.Band.Contains(*employee.Salary)
 }
 
+// SalaryChange is an effective-dated salary record of a position.
+type SalaryChange struct {
+	PositionID    string    `json:"position_id"`
+	Salary        Money     `json:"salary"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+// SearchHits maps the IDs of matched entities to their match score.
+type SearchHits map[string]float64
+
 type PositionsRepository interface {
 	Create(ctx context.Context, pos *Position) error
 	Get(ctx context.Context, id string) (*Position, error)
 	Update(ctx context.Context, pos Position) error
 	Delete(ctx context.Context, id string) error
 	GetAll(ctx context.Context) ([]Position, error)
+	GetAsOf(ctx context.Context, id string, date time.Time) (*Position, error)
+	SalaryHistory(ctx context.Context, id string) ([]SalaryChange, error)
+	ScheduleSalaryChange(ctx context.Context, change SalaryChange) error
+	// SearchName returns the positions whose name matches a single search token.
+	SearchName(ctx context.Context, token string) (SearchHits, error)
+	// Iterate calls fn for every position until fn returns an error, without loading all positions at once.
+	Iterate(ctx context.Context, fn func(Position) error) error
 }
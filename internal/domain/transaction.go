@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrPositionInUse = errors.New("position has employees assigned")
+	// ErrBandExcludesEmployees is returned for a salary band that doesn't contain the salaries of the employees
+	// assigned to the position.
+	ErrBandExcludesEmployees = errors.New("salary band excludes employees of the position")
+)
+
+// Tx gives access to the repositories within a transaction, they must be called with the context passed to UnitOfWork.Do.
+type Tx interface {
+	Employees() EmployeesRepository
+	Positions() PositionsRepository
+}
+
+// UnitOfWork runs fn in a transaction: the writes made through tx are applied together if fn returns nil
+// and discarded otherwise, and concurrent operations don't observe them before fn returns.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context, tx Tx) error) error
+}
+
+// DeletePosition deletes the position within tx. Its current employees are moved to the position reassignTo and
+// the scheduled changes to it are rescheduled to reassignTo, without reassignTo a position with employees or
+// scheduled changes isn't deleted and ErrPositionInUse is returned.
+func DeletePosition(ctx context.Context, tx Tx, id, reassignTo string) error {
+	employees, err := tx.Employees().ListByPosition(ctx, id)
+	if err != nil {
+		return err
+	}
+	scheduled, err := tx.Employees().ListScheduledForPosition(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if (len(employees) > 0 || len(scheduled) > 0) && (reassignTo == "" || reassignTo == id) {
+		return ErrPositionInUse
+	}
+
+	for _, employee := range employees {
+		employee.PositionID = reassignTo
+		if err := tx.Employees().Update(ctx, employee); err != nil {
+			return err
+		}
+	}
+
+	for _, assignment := range scheduled {
+		assignment.PositionID = reassignTo
+		if err := tx.Employees().SchedulePositionChange(ctx, assignment); err != nil {
+			return err
+		}
+	}
+
+	return tx.Positions().Delete(ctx, id)
+}
+
+// UpdatePosition updates the position within tx. A changed salary band has to contain the salaries of the employees
+// assigned to the position, now or by a scheduled change, otherwise ErrBandExcludesEmployees is returned.
+func UpdatePosition(ctx context.Context, tx Tx, position Position) error {
+	current, err := tx.Positions().Get(ctx, position.ID)
+	if err != nil {
+		return err
+	}
+
+	if !sameBand(current.Band, position.Band) {
+		employees, err := tx.Employees().ListByPosition(ctx, position.ID)
+		if err != nil {
+			return err
+		}
+		scheduled, err := tx.Employees().ListScheduledForPosition(ctx, position.ID)
+		if err != nil {
+			return err
+		}
+		for _, assignment := range scheduled {
+			employee, err := tx.Employees().Get(ctx, assignment.EmployeeID)
+			if err != nil {
+				return err
+			}
+			employees = append(employees, *employee)
+		}
+
+		for _, employee := range employees {
+			if err := position.CheckSalary(employee); err != nil {
+				return fmt.Errorf("%w: employee %s: %w", ErrBandExcludesEmployees, employee.ID, err)
+			}
+		}
+	}
+
+	return tx.Positions().Update(ctx, position)
+}
+
+func sameBand(a, b *SalaryBand) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,117 @@
+// Package dto holds the JSON representations of employees and positions of every API version,
+// so the domain structs can change without breaking clients of older versions.
+package dto
+
+import (
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+// Codec converts employees, positions and their history between domain structs and the JSON representation of an
+// API version.
+type Codec interface {
+	Version() string
+	EncodeEmployee(employee domain.Employee) any
+	EncodeSearchResult(result domain.EmployeeSearchResult) any
+	DecodeEmployee(data []byte) (domain.Employee, error)
+	EncodePosition(position domain.Position) any
+	DecodePosition(data []byte) (domain.Position, error)
+	EncodePositionAssignment(assignment domain.PositionAssignment) any
+	DecodePositionAssignment(data []byte) (domain.PositionAssignment, error)
+	EncodeSalaryChange(change domain.SalaryChange) any
+	DecodeSalaryChange(data []byte) (domain.SalaryChange, error)
+}
+
+// EncodeEmployees encodes every employee with the codec.
+func EncodeEmployees(codec Codec, employees []domain.Employee) []any {
+	encoded := make([]any, len(employees))
+	for i, employee := range employees {
+		encoded[i] = codec.EncodeEmployee(employee)
+	}
+	return encoded
+}
+
+// EncodePositions encodes every position with the codec.
+func EncodePositions(codec Codec, positions []domain.Position) []any {
+	encoded := make([]any, len(positions))
+	for i, position := range positions {
+		encoded[i] = codec.EncodePosition(position)
+	}
+	return encoded
+}
+
+// EncodeSearchResults encodes every search result with the codec.
+func EncodeSearchResults(codec Codec, results []domain.EmployeeSearchResult) []any {
+	encoded := make([]any, len(results))
+	for i, result := range results {
+		encoded[i] = codec.EncodeSearchResult(result)
+	}
+	return encoded
+}
+
+// EncodePositionAssignments encodes every position assignment with the codec.
+func EncodePositionAssignments(codec Codec, assignments []domain.PositionAssignment) []any {
+	encoded := make([]any, len(assignments))
+	for i, assignment := range assignments {
+		encoded[i] = codec.EncodePositionAssignment(assignment)
+	}
+	return encoded
+}
+
+// EncodeSalaryChanges encodes every salary change with the codec.
+func EncodeSalaryChanges(codec Codec, changes []domain.SalaryChange) []any {
+	encoded := make([]any, len(changes))
+	for i, change := range changes {
+		encoded[i] = codec.EncodeSalaryChange(change)
+	}
+	return encoded
+}
+
+// Money is the representation of domain.Money shared by all versions.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type SalaryBand struct {
+	Min Money `json:"min"`
+	Mid Money `json:"mid"`
+	Max Money `json:"max"`
+}
+
+func fromMoney(m domain.Money) Money {
+	return Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func (m Money) toDomain() domain.Money {
+	return domain.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func fromMoneyPtr(m *domain.Money) *Money {
+	if m == nil {
+		return nil
+	}
+	money := fromMoney(*m)
+	return &money
+}
+
+func (m *Money) toDomainPtr() *domain.Money {
+	if m == nil {
+		return nil
+	}
+	money := m.toDomain()
+	return &money
+}
+
+func fromBand(b *domain.SalaryBand) *SalaryBand {
+	if b == nil {
+		return nil
+	}
+	return &SalaryBand{Min: fromMoney(b.Min), Mid: fromMoney(b.Mid), Max: fromMoney(b.Max)}
+}
+
+func (b *SalaryBand) toDomain() *domain.SalaryBand {
+	if b == nil {
+		return nil
+	}
+	return &domain.SalaryBand{Min: b.Min.toDomain(), Mid: b.Mid.toDomain(), Max: b.Max.toDomain()}
+}
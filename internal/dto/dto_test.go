@@ -0,0 +1,163 @@
+package dto
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+func TestCodec_EncodeEmployee(t *testing.T) {
+	employee := domain.Employee{ID: "1", FirstName: "Ann", LastName: "Lee", PositionID: "p", Salary: &domain.Money{Amount: 15000, Currency: "USD"}, CompaRatio: 1.2}
+
+	tests := map[string]struct {
+		codec    Codec
+		employee domain.Employee
+		expected string
+	}{
+		"v1": {
+			codec:    V1,
+			employee: employee,
+			expected: `{"id":"1","firstname":"Ann","lastname":"Lee","position_id":"p","salary":{"amount":15000,"currency":"USD"},"compa_ratio":1.2}`,
+		},
+		"v1 without salary": {
+			codec:    V1,
+			employee: domain.Employee{ID: "1", FirstName: "Ann", LastName: "Lee", PositionID: "p"},
+			expected: `{"id":"1","firstname":"Ann","lastname":"Lee","position_id":"p"}`,
+		},
+		"v2": {
+			codec:    V2,
+			employee: employee,
+			expected: `{"id":"1","first_name":"Ann","last_name":"Lee","position_id":"p","salary":{"amount":15000,"currency":"USD"},"salary_override":false,"compa_ratio":1.2}`,
+		},
+		"v2 without salary": {
+			codec:    V2,
+			employee: domain.Employee{ID: "1", FirstName: "Ann", LastName: "Lee", PositionID: "p"},
+			expected: `{"id":"1","first_name":"Ann","last_name":"Lee","position_id":"p","salary":null,"salary_override":false,"compa_ratio":null}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := json.Marshal(tc.codec.EncodeEmployee(tc.employee))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestV1_MatchesDomain guards the v1 representation against changes of the domain structs.
+func TestV1_MatchesDomain(t *testing.T) {
+	employee := domain.Employee{ID: "1", FirstName: "Ann", LastName: "Lee", PositionID: "p", Salary: &domain.Money{Amount: 15000, Currency: "USD"}, SalaryOverride: true, CompaRatio: 1.2}
+	position := domain.Position{ID: "p", Name: "Engineer", Salary: domain.Money{Amount: 15000, Currency: "USD"}, Band: &domain.SalaryBand{
+		Min: domain.Money{Amount: 10000, Currency: "USD"},
+		Mid: domain.Money{Amount: 15000, Currency: "USD"},
+		Max: domain.Money{Amount: 20000, Currency: "USD"},
+	}}
+	result := domain.EmployeeSearchResult{Employee: employee, Score: 0.5}
+	assignment := domain.PositionAssignment{EmployeeID: "1", PositionID: "p", EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	change := domain.SalaryChange{PositionID: "p", Salary: domain.Money{Amount: 16000, Currency: "USD"}, EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	for name, tc := range map[string]struct{ domain, dto any }{
+		"employee":            {employee, V1.EncodeEmployee(employee)},
+		"position":            {position, V1.EncodePosition(position)},
+		"search result":       {result, V1.EncodeSearchResult(result)},
+		"position assignment": {assignment, V1.EncodePositionAssignment(assignment)},
+		"salary change":       {change, V1.EncodeSalaryChange(change)},
+	} {
+		want, _ := json.Marshal(tc.domain)
+		got, _ := json.Marshal(tc.dto)
+		if string(got) != string(want) {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+}
+
+func TestCodec_Decode(t *testing.T) {
+	wantEmployee := domain.Employee{FirstName: "Ann", LastName: "Lee", PositionID: "p", Salary: &domain.Money{Amount: 15000, Currency: "USD"}}
+	wantPosition := domain.Position{Name: "Engineer", Salary: domain.Money{Amount: 15000, Currency: "USD"}}
+
+	tests := map[string]struct {
+		codec    Codec
+		employee string
+		position string
+	}{
+		"v1": {
+			codec:    V1,
+			employee: `{"firstname":"Ann","lastname":"Lee","position_id":"p","salary":{"amount":15000,"currency":"USD"}}`,
+			position: `{"name":"Engineer","salary":{"amount":15000,"currency":"USD"}}`,
+		},
+		"v2": {
+			codec:    V2,
+			employee: `{"first_name":"Ann","last_name":"Lee","position_id":"p","salary":{"amount":15000,"currency":"USD"},"compa_ratio":null}`,
+			position: `{"name":"Engineer","salary":{"amount":15000,"currency":"USD"},"band":null}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			employee, err := tc.codec.DecodeEmployee([]byte(tc.employee))
+			if err != nil || !reflect.DeepEqual(employee, wantEmployee) {
+				t.Errorf("expected %+v, got %+v, %v", wantEmployee, employee, err)
+			}
+
+			position, err := tc.codec.DecodePosition([]byte(tc.position))
+			if err != nil || !reflect.DeepEqual(position, wantPosition) {
+				t.Errorf("expected %+v, got %+v, %v", wantPosition, position, err)
+			}
+		})
+	}
+
+	if _, err := V2.DecodeEmployee([]byte(`{"first_name":`)); err == nil {
+		t.Errorf("expected error for invalid JSON")
+	}
+}
+
+func TestCodec_History(t *testing.T) {
+	effective := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assignment := domain.PositionAssignment{EmployeeID: "1", PositionID: "p", EffectiveFrom: effective}
+	change := domain.SalaryChange{PositionID: "p", Salary: domain.Money{Amount: 16000, Currency: "USD"}, EffectiveFrom: effective}
+
+	tests := map[string]struct {
+		codec      Codec
+		assignment string
+		change     string
+	}{
+		"v1": {
+			codec:      V1,
+			assignment: `{"employee_id":"1","position_id":"p","effective_from":"2024-01-01T00:00:00Z"}`,
+			change:     `{"position_id":"p","salary":{"amount":16000,"currency":"USD"},"effective_from":"2024-01-01T00:00:00Z"}`,
+		},
+		"v2": {
+			codec:      V2,
+			assignment: `{"employee_id":"1","position_id":"p","effective_from":"2024-01-01T00:00:00Z"}`,
+			change:     `{"position_id":"p","salary":{"amount":16000,"currency":"USD"},"effective_from":"2024-01-01T00:00:00Z"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, _ := json.Marshal(tc.codec.EncodePositionAssignment(assignment)); string(got) != tc.assignment {
+				t.Errorf("expected %s, got %s", tc.assignment, got)
+			}
+			if got, _ := json.Marshal(tc.codec.EncodeSalaryChange(change)); string(got) != tc.change {
+				t.Errorf("expected %s, got %s", tc.change, got)
+			}
+
+			decodedAssignment, err := tc.codec.DecodePositionAssignment([]byte(tc.assignment))
+			if err != nil || decodedAssignment != assignment {
+				t.Errorf("expected %+v, got %+v, %v", assignment, decodedAssignment, err)
+			}
+			decodedChange, err := tc.codec.DecodeSalaryChange([]byte(tc.change))
+			if err != nil || decodedChange != change {
+				t.Errorf("expected %+v, got %+v, %v", change, decodedChange, err)
+			}
+		})
+	}
+}
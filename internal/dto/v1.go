@@ -0,0 +1,122 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+// V1 is the original representation, also served by the unversioned routes.
+var V1 Codec = v1{}
+
+type EmployeeV1 struct {
+	ID             string  `json:"id"`
+	FirstName      string  `json:"firstname"`
+	LastName       string  `json:"lastname"`
+	PositionID     string  `json:"position_id"`
+	Salary         *Money  `json:"salary,omitempty"`
+	SalaryOverride bool    `json:"salary_override,omitempty"`
+	CompaRatio     float64 `json:"compa_ratio,omitempty"`
+}
+
+type EmployeeSearchResultV1 struct {
+	EmployeeV1
+	Score float64 `json:"score"`
+}
+
+type PositionV1 struct {
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Salary Money       `json:"salary"`
+	Band   *SalaryBand `json:"band,omitempty"`
+}
+
+type PositionAssignmentV1 struct {
+	EmployeeID    string    `json:"employee_id"`
+	PositionID    string    `json:"position_id"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+type SalaryChangeV1 struct {
+	PositionID    string    `json:"position_id"`
+	Salary        Money     `json:"salary"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+type v1 struct{}
+
+func (v1) Version() string {
+	return "v1"
+}
+
+func (v1) EncodeEmployee(e domain.Employee) any {
+	return employeeV1(e)
+}
+
+func employeeV1(e domain.Employee) EmployeeV1 {
+	return EmployeeV1{
+		ID:             e.ID,
+		FirstName:      e.FirstName,
+		LastName:       e.LastName,
+		PositionID:     e.PositionID,
+		Salary:         fromMoneyPtr(e.Salary),
+		SalaryOverride: e.SalaryOverride,
+		CompaRatio:     e.CompaRatio,
+	}
+}
+
+func (v1) EncodeSearchResult(r domain.EmployeeSearchResult) any {
+	return EmployeeSearchResultV1{EmployeeV1: employeeV1(r.Employee), Score: r.Score}
+}
+
+func (v1) DecodeEmployee(data []byte) (domain.Employee, error) {
+	var e EmployeeV1
+	if err := json.Unmarshal(data, &e); err != nil {
+		return domain.Employee{}, err
+	}
+	return domain.Employee{
+		ID:             e.ID,
+		FirstName:      e.FirstName,
+		LastName:       e.LastName,
+		PositionID:     e.PositionID,
+		Salary:         e.Salary.toDomainPtr(),
+		SalaryOverride: e.SalaryOverride,
+	}, nil
+}
+
+func (v1) EncodePosition(p domain.Position) any {
+	return PositionV1{ID: p.ID, Name: p.Name, Salary: fromMoney(p.Salary), Band: fromBand(p.Band)}
+}
+
+func (v1) DecodePosition(data []byte) (domain.Position, error) {
+	var p PositionV1
+	if err := json.Unmarshal(data, &p); err != nil {
+		return domain.Position{}, err
+	}
+	return domain.Position{ID: p.ID, Name: p.Name, Salary: p.Salary.toDomain(), Band: p.Band.toDomain()}, nil
+}
+
+func (v1) EncodePositionAssignment(a domain.PositionAssignment) any {
+	return PositionAssignmentV1{EmployeeID: a.EmployeeID, PositionID: a.PositionID, EffectiveFrom: a.EffectiveFrom}
+}
+
+func (v1) DecodePositionAssignment(data []byte) (domain.PositionAssignment, error) {
+	var a PositionAssignmentV1
+	if err := json.Unmarshal(data, &a); err != nil {
+		return domain.PositionAssignment{}, err
+	}
+	return domain.PositionAssignment{EmployeeID: a.EmployeeID, PositionID: a.PositionID, EffectiveFrom: a.EffectiveFrom}, nil
+}
+
+func (v1) EncodeSalaryChange(c domain.SalaryChange) any {
+	return SalaryChangeV1{PositionID: c.PositionID, Salary: fromMoney(c.Salary), EffectiveFrom: c.EffectiveFrom}
+}
+
+func (v1) DecodeSalaryChange(data []byte) (domain.SalaryChange, error) {
+	var c SalaryChangeV1
+	if err := json.Unmarshal(data, &c); err != nil {
+		return domain.SalaryChange{}, err
+	}
+	return domain.SalaryChange{PositionID: c.PositionID, Salary: c.Salary.toDomain(), EffectiveFrom: c.EffectiveFrom}, nil
+}
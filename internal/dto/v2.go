@@ -0,0 +1,127 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+// V2 names fields in snake case, always returns every field, with null for missing values,
+// and nests the employee of search results instead of embedding it.
+var V2 Codec = v2{}
+
+type EmployeeV2 struct {
+	ID             string   `json:"id"`
+	FirstName      string   `json:"first_name"`
+	LastName       string   `json:"last_name"`
+	PositionID     string   `json:"position_id"`
+	Salary         *Money   `json:"salary"`
+	SalaryOverride bool     `json:"salary_override"`
+	CompaRatio     *float64 `json:"compa_ratio"`
+}
+
+type EmployeeSearchResultV2 struct {
+	Employee EmployeeV2 `json:"employee"`
+	Score    float64    `json:"score"`
+}
+
+type PositionV2 struct {
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Salary Money       `json:"salary"`
+	Band   *SalaryBand `json:"band"`
+}
+
+type PositionAssignmentV2 struct {
+	EmployeeID    string    `json:"employee_id"`
+	PositionID    string    `json:"position_id"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+type SalaryChangeV2 struct {
+	PositionID    string    `json:"position_id"`
+	Salary        Money     `json:"salary"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+type v2 struct{}
+
+func (v2) Version() string {
+	return "v2"
+}
+
+func (v2) EncodeEmployee(e domain.Employee) any {
+	return employeeV2(e)
+}
+
+func employeeV2(e domain.Employee) EmployeeV2 {
+	employee := EmployeeV2{
+		ID:             e.ID,
+		FirstName:      e.FirstName,
+		LastName:       e.LastName,
+		PositionID:     e.PositionID,
+		Salary:         fromMoneyPtr(e.Salary),
+		SalaryOverride: e.SalaryOverride,
+	}
+	if e.CompaRatio != 0 {
+		compaRatio := e.CompaRatio
+		employee.CompaRatio = &compaRatio
+	}
+	return employee
+}
+
+func (v2) EncodeSearchResult(r domain.EmployeeSearchResult) any {
+	return EmployeeSearchResultV2{Employee: employeeV2(r.Employee), Score: r.Score}
+}
+
+func (v2) DecodeEmployee(data []byte) (domain.Employee, error) {
+	var e EmployeeV2
+	if err := json.Unmarshal(data, &e); err != nil {
+		return domain.Employee{}, err
+	}
+	return domain.Employee{
+		ID:             e.ID,
+		FirstName:      e.FirstName,
+		LastName:       e.LastName,
+		PositionID:     e.PositionID,
+		Salary:         e.Salary.toDomainPtr(),
+		SalaryOverride: e.SalaryOverride,
+	}, nil
+}
+
+func (v2) EncodePosition(p domain.Position) any {
+	return PositionV2{ID: p.ID, Name: p.Name, Salary: fromMoney(p.Salary), Band: fromBand(p.Band)}
+}
+
+func (v2) DecodePosition(data []byte) (domain.Position, error) {
+	var p PositionV2
+	if err := json.Unmarshal(data, &p); err != nil {
+		return domain.Position{}, err
+	}
+	return domain.Position{ID: p.ID, Name: p.Name, Salary: p.Salary.toDomain(), Band: p.Band.toDomain()}, nil
+}
+
+func (v2) EncodePositionAssignment(a domain.PositionAssignment) any {
+	return PositionAssignmentV2{EmployeeID: a.EmployeeID, PositionID: a.PositionID, EffectiveFrom: a.EffectiveFrom}
+}
+
+func (v2) DecodePositionAssignment(data []byte) (domain.PositionAssignment, error) {
+	var a PositionAssignmentV2
+	if err := json.Unmarshal(data, &a); err != nil {
+		return domain.PositionAssignment{}, err
+	}
+	return domain.PositionAssignment{EmployeeID: a.EmployeeID, PositionID: a.PositionID, EffectiveFrom: a.EffectiveFrom}, nil
+}
+
+func (v2) EncodeSalaryChange(c domain.SalaryChange) any {
+	return SalaryChangeV2{PositionID: c.PositionID, Salary: fromMoney(c.Salary), EffectiveFrom: c.EffectiveFrom}
+}
+
+func (v2) DecodeSalaryChange(data []byte) (domain.SalaryChange, error) {
+	var c SalaryChangeV2
+	if err := json.Unmarshal(data, &c); err != nil {
+		return domain.SalaryChange{}, err
+	}
+	return domain.SalaryChange{PositionID: c.PositionID, Salary: c.Salary.toDomain(), EffectiveFrom: c.EffectiveFrom}, nil
+}
@@ -0,0 +1,192 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid resume token")
+	// ErrTokenExpired is returned for tokens of events no longer in the log, or of a previous process.
+	ErrTokenExpired = errors.New("resume token expired")
+	// ErrLagged ends a subscription that fell further behind than the log capacity, it can resume from its last token.
+	ErrLagged = errors.New("subscriber fell behind")
+	ErrClosed = errors.New("subscription closed")
+)
+
+// Bus fans out published events to subscribers and keeps the last events in a log,
+// so subscribers can resume after the token of the last event they received.
+type Bus struct {
+	mu       sync.Mutex
+	epoch    string
+	seq      uint64
+	log      []domain.Event
+	capacity int
+	subs     map[*Subscription]struct{}
+}
+
+// NewBus creates a bus keeping the last capacity events.
+func NewBus(capacity int) *Bus {
+	return &Bus{
+		epoch:    strconv.FormatInt(time.Now().UnixNano(), 36),
+		capacity: capacity,
+		subs:     make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event a token and delivers it to the matching subscribers, it doesn't block on slow subscribers.
+func (b *Bus) Publish(event domain.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.Token = b.token(b.seq)
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.log = append(b.log, event)
+	if len(b.log) > 2*b.capacity {
+		b.log = append([]domain.Event(nil), b.log[len(b.log)-b.capacity:]...)
+	}
+
+	for sub := range b.subs {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		if !sub.push(event, b.capacity) {
+			delete(b.subs, sub)
+		}
+	}
+}
+
+// Subscribe returns a subscription to the events after the token matching filter, a nil filter matches every event.
+// An empty token subscribes to new events only.
+func (b *Bus) Subscribe(token string, filter func(domain.Event) bool) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	after := b.seq
+	if token != "" {
+		var err error
+		if after, err = b.parseToken(token); err != nil {
+			return nil, err
+		}
+	}
+
+	oldest := b.seq - uint64(len(b.log)) + 1
+	if after+1 < oldest {
+		return nil, ErrTokenExpired
+	}
+
+	sub := &Subscription{bus: b, filter: filter, notify: make(chan struct{}, 1)}
+	for _, event := range b.log[after+1-oldest:] {
+		if filter == nil || filter(event) {
+			sub.queue = append(sub.queue, event)
+		}
+	}
+
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+func (b *Bus) token(seq uint64) string {
+	return b.epoch + "." + strconv.FormatUint(seq, 10)
+}
+
+func (b *Bus) parseToken(token string) (uint64, error) {
+	epoch, value, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+
+	seq, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	if epoch != b.epoch {
+		return 0, ErrTokenExpired
+	}
+	if seq > b.seq {
+		return 0, ErrInvalidToken
+	}
+	return seq, nil
+}
+
+type Subscription struct {
+	bus    *Bus
+	filter func(domain.Event) bool
+
+	mu     sync.Mutex
+	queue  []domain.Event
+	err    error
+	notify chan struct{}
+}
+
+// Next returns the next event, waiting for it to be published if needed.
+// After the subscription ended the queued events are returned before its error.
+func (s *Subscription) Next(ctx context.Context) (domain.Event, error) {
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			event := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+			return event, nil
+		}
+		err := s.err
+		s.mu.Unlock()
+
+		if err != nil {
+			return domain.Event{}, err
+		}
+
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return domain.Event{}, ctx.Err()
+		}
+	}
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	delete(s.bus.subs, s)
+	s.bus.mu.Unlock()
+
+	s.mu.Lock()
+	s.queue = nil
+	if s.err == nil {
+		s.err = ErrClosed
+	}
+	s.mu.Unlock()
+	s.signal()
+}
+
+// push queues the event, it returns false and ends the subscription when more than limit events are queued.
+func (s *Subscription) push(event domain.Event, limit int) bool {
+	s.mu.Lock()
+	ok := s.err == nil && len(s.queue) < limit
+	if ok {
+		s.queue = append(s.queue, event)
+	} else if s.err == nil {
+		s.err = ErrLagged
+	}
+	s.mu.Unlock()
+
+	s.signal()
+	return ok
+}
+
+func (s *Subscription) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+func publish(bus *Bus, ids ...string) {
+	for _, id := range ids {
+		bus.Publish(domain.Event{Type: domain.EventCreated, Entity: domain.EntityEmployee, EntityID: id})
+	}
+}
+
+func next(t *testing.T, sub *Subscription) domain.Event {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	event, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestBus_Resume(t *testing.T) {
+	bus := NewBus(10)
+
+	sub, err := bus.Subscribe("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	publish(bus, "1", "2", "3")
+
+	first := next(t, sub)
+	if first.EntityID != "1" || first.Token == "" || first.Time.IsZero() {
+		t.Fatalf("unexpected event %+v", first)
+	}
+
+	resumed, err := bus.Subscribe(first.Token, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+
+	for _, expected := range []string{"2", "3"} {
+		if event := next(t, resumed); event.EntityID != expected {
+			t.Fatalf("expected event of %s, got %+v", expected, event)
+		}
+	}
+}
+
+func TestBus_Subscribe_Errors(t *testing.T) {
+	bus := NewBus(2)
+	publish(bus, "1", "2", "3", "4", "5")
+
+	tests := map[string]struct {
+		token    string
+		expected error
+	}{
+		"malformed":     {token: "token", expected: ErrInvalidToken},
+		"future":        {token: bus.token(100), expected: ErrInvalidToken},
+		"other process": {token: "epoch.1", expected: ErrTokenExpired},
+		"trimmed":       {token: bus.token(1), expected: ErrTokenExpired},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := bus.Subscribe(tt.token, nil); !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestBus_FilterAndLag(t *testing.T) {
+	bus := NewBus(2)
+
+	positions, err := bus.Subscribe("", func(event domain.Event) bool {
+		return event.Entity == domain.EntityPosition
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer positions.Close()
+
+	lagging, err := bus.Subscribe("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lagging.Close()
+
+	publish(bus, "1", "2", "3")
+	bus.Publish(domain.Event{Type: domain.EventDeleted, Entity: domain.EntityPosition, EntityID: "p"})
+
+	if event := next(t, positions); event.EntityID != "p" {
+		t.Fatalf("expected only position events, got %+v", event)
+	}
+
+	next(t, lagging)
+	next(t, lagging)
+	if _, err := lagging.Next(context.Background()); !errors.Is(err, ErrLagged) {
+		t.Fatalf("expected %v, got %v", ErrLagged, err)
+	}
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/idempotency"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	pb "github.com/dilyara4949/employees-api/proto"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const bufferSize = 1 << 20
+
+// Dial serves srv on an in-memory listener as well and returns a connection to it, so the gateway calls the
+// services through the same interceptors as remote clients without another round trip over the network.
+func Dial(srv *grpc.Server) (*grpc.ClientConn, error) {
+	listener := bufconn.Listen(bufferSize)
+	go func() {
+		if err := srv.Serve(listener); err != nil {
+			log.Printf("In-process gRPC server stopped: %v", err)
+		}
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error to dial in-process gRPC server: %w", err)
+	}
+	return conn, nil
+}
+
+// New returns a handler transcoding JSON/HTTP requests to the employee and position services of conn,
+// as described by the google.api.http annotations of the protos. Fields keep their proto names, so the
+// bodies match the JSON of the v1 API.
+func New(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithMetadata(correlationID),
+		runtime.WithMetadata(tenantID),
+		runtime.WithMetadata(jwtClaims),
+	)
+
+	if err := pb.RegisterEmployeeServiceHandler(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("error to register employee service handler: %w", err)
+	}
+	if err := pb.RegisterPositionServiceHandler(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("error to register position service handler: %w", err)
+	}
+
+	// the token was verified by the authentication middleware, the services get its claims instead
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		r.Header.Del("Authorization")
+		mux.ServeHTTP(w, r)
+	}), nil
+}
+
+// headerMatcher forwards the Idempotency-Key and X-API-Key headers besides the headers forwarded by default,
+// except the tenant and the JWT claims, which only the gateway sets.
+func headerMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, idempotency.Header) || strings.EqualFold(key, middleware.APIKeyHeader) {
+		return strings.ToLower(key), true
+	}
+	if strings.EqualFold(key, runtime.MetadataHeaderPrefix+tenant.Metadata) ||
+		strings.EqualFold(key, runtime.MetadataHeaderPrefix+middleware.JWTClaimsMetadata) {
+		return "", false
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// correlationID passes the correlation ID of the HTTP request, set by the CorrelationID middleware, to the services.
+func correlationID(ctx context.Context, r *http.Request) metadata.MD {
+	id, ok := r.Context().Value(middleware.CorrelationID).(string)
+	if !ok {
+		return nil
+	}
+	return metadata.Pairs(middleware.CorrelationID, id)
+}
+
+// tenantID passes the tenant of the HTTP request, set by the authentication middleware, to the services.
+func tenantID(ctx context.Context, r *http.Request) metadata.MD {
+	id := tenant.From(r.Context())
+	if id == tenant.Default {
+		return nil
+	}
+	return metadata.Pairs(tenant.Metadata, id)
+}
+
+// jwtClaims passes the claims of the token of the HTTP request, verified by the authentication middleware, to the
+// services.
+func jwtClaims(ctx context.Context, r *http.Request) metadata.MD {
+	claims, ok := r.Context().Value(middleware.JWTClaims).(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		log.Printf("error to encode JWT claims: %v", err)
+		return nil
+	}
+	return metadata.Pairs(middleware.JWTClaimsMetadata, string(encoded))
+}
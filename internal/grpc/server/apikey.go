@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	pb "github.com/dilyara4949/employees-api/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// apiKeyMetadata is the metadata key of API keys, the X-API-Key header forwarded by the gateway.
+var apiKeyMetadata = strings.ToLower(middleware.APIKeyHeader)
+
+// serviceResources are the resources of the API key scopes by gRPC service.
+var serviceResources = map[string]string{
+	pb.EmployeeService_ServiceDesc.ServiceName: "employees",
+	pb.PositionService_ServiceDesc.ServiceName: "positions",
+}
+
+// readMethods only read their resource, they require its read scope and the other methods its write scope.
+var readMethods = map[string]bool{
+	"Get":    true,
+	"GetAll": true,
+	"Search": true,
+	"Export": true,
+	"Watch":  true,
+}
+
+// APIKeyInterceptor authenticates calls with an API key in the x-api-key metadata and checks that the key was
+// granted the scope of the method, see APIKeyStreamInterceptor for streams. Calls without API key are let through,
+// AuthRequiredInterceptor rejects them unless they have a bearer token.
+func APIKeyInterceptor(keys middleware.APIKeys) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticateAPIKey(ctx, keys, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// APIKeyStreamInterceptor is the counterpart of APIKeyInterceptor for streams.
+func APIKeyStreamInterceptor(keys middleware.APIKeys) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateAPIKey(ss.Context(), keys, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticateAPIKey puts the API key of the call and its tenant into the context. Invalid keys fail with Unauthenticated, keys
+// lacking the scope of the method with PermissionDenied.
+func authenticateAPIKey(ctx context.Context, keys middleware.APIKeys, fullMethod string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(apiKeyMetadata)
+	if len(values) == 0 || values[0] == "" {
+		return ctx, nil
+	}
+
+	key, err := keys.Authenticate(values[0])
+	if err != nil {
+		return ctx, statusError(ctx, codes.Unauthenticated, "invalid api key")
+	}
+
+	scope := methodScope(fullMethod)
+	if scope == "" || !key.Allows(scope) {
+		return ctx, statusError(ctx, codes.PermissionDenied, "api key lacks scope for "+fullMethod)
+	}
+	return context.WithValue(tenant.With(ctx, key.TenantID), middleware.APIKey, key), nil
+}
+
+// methodScope returns the scope required by the method, e.g. "employees:read" for EmployeeService.Get,
+// or an empty string for the methods of other services, which API keys can't call.
+func methodScope(fullMethod string) string {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	resource, ok := serviceResources[service]
+	if !ok {
+		return ""
+	}
+	return apikey.Scope(resource, !readMethods[method])
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	pb "github.com/dilyara4949/employees-api/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAPIKeyInterceptor(t *testing.T) {
+	keys := apikey.NewStore()
+	reader := apikey.Key{Owner: "payroll", Scopes: []string{"employees:read"}}
+	readerKey, err := keys.Create(&reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked := apikey.Key{Owner: "payroll", Scopes: []string{"employees:read", "employees:write"}}
+	revokedKey, err := keys.Create(&revoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Revoke(revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+	exporter := bulk.NewExporter(employees, positions)
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(CorrelationIDInterceptor(), APIKeyInterceptor(keys)),
+		grpc.ChainStreamInterceptor(CorrelationIDStreamInterceptor(), APIKeyStreamInterceptor(keys)),
+	)
+	pb.RegisterEmployeeServiceServer(srv, NewEmployeeServer(employees, bulk.NewImporter(employees, positions, uow), exporter, bulk.NewBatcher(uow), bus))
+	pb.RegisterPositionServiceServer(srv, NewPositionServer(positions, uow, exporter, bulk.NewBatcher(uow), bus))
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	employeesClient, positionsClient := pb.NewEmployeeServiceClient(conn), pb.NewPositionServiceClient(conn)
+
+	export := func(ctx context.Context) error {
+		stream, err := employeesClient.Export(ctx, &pb.ExportRequest{Format: "csv"})
+		if err != nil {
+			return err
+		}
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+
+	tests := map[string]struct {
+		key      string
+		call     func(ctx context.Context) error
+		expected codes.Code
+	}{
+		"no key": {
+			call: func(ctx context.Context) error {
+				_, err := employeesClient.GetAll(ctx, &pb.Empty{})
+				return err
+			},
+			expected: codes.OK,
+		},
+		"read in scope": {
+			key: readerKey,
+			call: func(ctx context.Context) error {
+				_, err := employeesClient.GetAll(ctx, &pb.Empty{})
+				return err
+			},
+			expected: codes.OK,
+		},
+		"stream in scope": {
+			key:      readerKey,
+			call:     export,
+			expected: codes.OK,
+		},
+		"write out of scope": {
+			key: readerKey,
+			call: func(ctx context.Context) error {
+				_, err := employeesClient.Delete(ctx, &pb.Id{Value: "1"})
+				return err
+			},
+			expected: codes.PermissionDenied,
+		},
+		"other service": {
+			key: readerKey,
+			call: func(ctx context.Context) error {
+				_, err := positionsClient.GetAll(ctx, &pb.Empty{})
+				return err
+			},
+			expected: codes.PermissionDenied,
+		},
+		"revoked key": {
+			key: revokedKey,
+			call: func(ctx context.Context) error {
+				_, err := employeesClient.GetAll(ctx, &pb.Empty{})
+				return err
+			},
+			expected: codes.Unauthenticated,
+		},
+		"revoked key on stream": {
+			key:      revokedKey,
+			call:     export,
+			expected: codes.Unauthenticated,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", tc.key)
+			}
+
+			if err := tc.call(ctx); status.Code(err) != tc.expected {
+				t.Errorf("expected %s, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	t.Run("tenant of the key", func(t *testing.T) {
+		acme := tenant.With(context.Background(), "acme")
+		developer := &domain.Position{Name: "Developer", Salary: domain.Money{Amount: 100000, Currency: "USD"}}
+		if err := positions.Create(acme, developer); err != nil {
+			t.Fatal(err)
+		}
+		if err := employees.Create(acme, &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: developer.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		acmeReader := apikey.Key{Owner: "payroll", Scopes: []string{"employees:read"}, TenantID: "acme"}
+		acmeKey, err := keys.Create(&acmeReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for key, expected := range map[string]int{acmeKey: 1, readerKey: 0} {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+			list, err := employeesClient.GetAll(ctx, &pb.Empty{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list.Employee) != expected {
+				t.Errorf("expected %d employees, got %d", expected, len(list.Employee))
+			}
+		}
+	})
+}
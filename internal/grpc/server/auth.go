@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// reflectionService is the prefix of the methods of the reflection service, which lists the services to tools
+// like grpcurl and stays open to anonymous callers.
+const reflectionService = "/grpc.reflection."
+
+// AuthRequiredInterceptor rejects calls authenticated by neither a bearer token nor an API key with Unauthenticated,
+// see AuthRequiredStreamInterceptor for streams. It must come after JWTInterceptor and APIKeyInterceptor.
+func AuthRequiredInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := requireAuth(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthRequiredStreamInterceptor is the counterpart of AuthRequiredInterceptor for streams.
+func AuthRequiredStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := requireAuth(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func requireAuth(ctx context.Context, fullMethod string) error {
+	if strings.HasPrefix(fullMethod, reflectionService) || ctx.Value(middleware.JWTClaims) != nil || ctx.Value(middleware.APIKey) != nil {
+		return nil
+	}
+	return statusError(ctx, codes.Unauthenticated, "bearer token or api key required")
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	pb "github.com/dilyara4949/employees-api/proto"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAuthRequiredInterceptor(t *testing.T) {
+	keys := apikey.NewStore()
+	reader := apikey.Key{Owner: "payroll", Scopes: []string{"employees:read"}}
+	readerKey, err := keys.Create(&reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+	exporter := bulk.NewExporter(employees, positions)
+
+	jwtAuth := middleware.NewJWTAuth(middleware.JWTConfig{Secret: jwtSecret}, nil)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(CorrelationIDInterceptor(), JWTInterceptor(jwtAuth), APIKeyInterceptor(keys), AuthRequiredInterceptor()),
+		grpc.ChainStreamInterceptor(CorrelationIDStreamInterceptor(), JWTStreamInterceptor(jwtAuth), APIKeyStreamInterceptor(keys), AuthRequiredStreamInterceptor()),
+	)
+	pb.RegisterEmployeeServiceServer(srv, NewEmployeeServer(employees, bulk.NewImporter(employees, positions, uow), exporter, bulk.NewBatcher(uow), bus))
+	reflection.Register(srv)
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := pb.NewEmployeeServiceClient(conn)
+
+	getAll := func(ctx context.Context) error {
+		_, err := client.GetAll(ctx, &pb.Empty{})
+		return err
+	}
+	export := func(ctx context.Context) error {
+		stream, err := client.Export(ctx, &pb.ExportRequest{Format: "csv"})
+		if err != nil {
+			return err
+		}
+		_, err = stream.Recv()
+		return err
+	}
+	listServices := func(ctx context.Context) error {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		if err != nil {
+			return err
+		}
+		err = stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = stream.Recv()
+		return err
+	}
+
+	tests := map[string]struct {
+		metadata []string
+		call     func(ctx context.Context) error
+		expected codes.Code
+	}{
+		"anonymous": {
+			call:     getAll,
+			expected: codes.Unauthenticated,
+		},
+		"anonymous stream": {
+			call:     export,
+			expected: codes.Unauthenticated,
+		},
+		"bearer token": {
+			metadata: []string{"authorization", "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann"})},
+			call:     getAll,
+			expected: codes.OK,
+		},
+		"bearer token on stream": {
+			metadata: []string{"authorization", "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann"})},
+			call:     export,
+			expected: codes.OK,
+		},
+		"api key": {
+			metadata: []string{apiKeyMetadata, readerKey},
+			call:     getAll,
+			expected: codes.OK,
+		},
+		"anonymous reflection": {
+			call:     listServices,
+			expected: codes.OK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.metadata != nil {
+				ctx = metadata.AppendToOutgoingContext(ctx, tc.metadata...)
+			}
+
+			if err := tc.call(ctx); status.Code(err) != tc.expected {
+				t.Errorf("expected %s, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	pb "github.com/dilyara4949/employees-api/proto"
+	"google.golang.org/grpc/codes"
+)
+
+func (s *EmployeeServer) BatchCreate(ctx context.Context, req *pb.BatchEmployeesRequest) (*pb.BatchResponse, error) {
+	return s.batchEmployees(ctx, req, bulk.OpCreate)
+}
+
+func (s *EmployeeServer) BatchUpdate(ctx context.Context, req *pb.BatchEmployeesRequest) (*pb.BatchResponse, error) {
+	return s.batchEmployees(ctx, req, bulk.OpUpdate)
+}
+
+func (s *EmployeeServer) BatchDelete(ctx context.Context, req *pb.BatchDeleteRequest) (*pb.BatchResponse, error) {
+	if req == nil {
+		return nil, invalidArgument(ctx, "got nil request in batch delete employees")
+	}
+
+	ops := make([]bulk.EmployeeOperation, len(req.GetIds()))
+	for i, id := range req.GetIds() {
+		ops[i] = bulk.EmployeeOperation{Op: bulk.OpDelete, ID: id}
+	}
+
+	result, err := s.Batcher.BatchEmployees(ctx, ops, bulk.BatchOptions{Atomic: !req.GetPartial()})
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	return batchResultToProto(result), nil
+}
+
+func (s *EmployeeServer) batchEmployees(ctx context.Context, req *pb.BatchEmployeesRequest, op bulk.BatchOp) (*pb.BatchResponse, error) {
+	if req == nil {
+		return nil, invalidArgument(ctx, fmt.Sprintf("got nil request in batch %s employees", op))
+	}
+
+	ops := make([]bulk.EmployeeOperation, len(req.GetEmployees()))
+	for i, employee := range req.GetEmployees() {
+		ops[i] = bulk.EmployeeOperation{Op: op, Employee: protoToEmployee(employee)}
+	}
+
+	opts := bulk.BatchOptions{
+		Atomic:              !req.GetPartial(),
+		AllowSalaryOverride: middleware.HasRole(ctx, domain.RoleCompensationAdmin),
+	}
+
+	result, err := s.Batcher.BatchEmployees(ctx, ops, opts)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	return batchResultToProto(result), nil
+}
+
+func (s *PositionServer) BatchCreate(ctx context.Context, req *pb.BatchPositionsRequest) (*pb.BatchResponse, error) {
+	return s.batchPositions(ctx, req, bulk.OpCreate)
+}
+
+func (s *PositionServer) BatchUpdate(ctx context.Context, req *pb.BatchPositionsRequest) (*pb.BatchResponse, error) {
+	return s.batchPositions(ctx, req, bulk.OpUpdate)
+}
+
+func (s *PositionServer) BatchDelete(ctx context.Context, req *pb.BatchDeleteRequest) (*pb.BatchResponse, error) {
+	if req == nil {
+		return nil, invalidArgument(ctx, "got nil request in batch delete positions")
+	}
+
+	ops := make([]bulk.PositionOperation, len(req.GetIds()))
+	for i, id := range req.GetIds() {
+		ops[i] = bulk.PositionOperation{Op: bulk.OpDelete, ID: id}
+	}
+
+	result, err := s.Batcher.BatchPositions(ctx, ops, bulk.BatchOptions{Atomic: !req.GetPartial()})
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	return batchResultToProto(result), nil
+}
+
+func (s *PositionServer) batchPositions(ctx context.Context, req *pb.BatchPositionsRequest, op bulk.BatchOp) (*pb.BatchResponse, error) {
+	if req == nil {
+		return nil, invalidArgument(ctx, fmt.Sprintf("got nil request in batch %s positions", op))
+	}
+
+	ops := make([]bulk.PositionOperation, len(req.GetPositions()))
+	for i, position := range req.GetPositions() {
+		ops[i] = bulk.PositionOperation{Op: op, Position: protoToPosition(position)}
+	}
+
+	result, err := s.Batcher.BatchPositions(ctx, ops, bulk.BatchOptions{Atomic: !req.GetPartial()})
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	return batchResultToProto(result), nil
+}
+
+func batchResultToProto(result bulk.BatchResult) *pb.BatchResponse {
+	results := make([]*pb.BatchItemResult, len(result.Items))
+	for i, item := range result.Items {
+		results[i] = &pb.BatchItemResult{Index: int32(item.Index), Id: item.ID, Code: int32(batchItemCode(item.Err))}
+		if item.Err != nil {
+			results[i].Error = item.Err.Error()
+		}
+	}
+	return &pb.BatchResponse{
+		Succeeded: int32(result.Succeeded),
+		Failed:    int32(result.Failed),
+		Results:   results,
+	}
+}
+
+func batchItemCode(err error) codes.Code {
+	switch {
+	case err == nil:
+		return codes.OK
+	case errors.Is(err, bulk.ErrBatchAborted):
+		return codes.Aborted
+	case errors.Is(err, bulk.ErrSalaryOverrideForbidden):
+		return codes.PermissionDenied
+	case errors.Is(err, domain.ErrBandExcludesEmployees):
+		return codes.FailedPrecondition
+	case errors.Is(err, bulk.ErrInvalidOperation),
+		errors.Is(err, domain.ErrSalaryOutOfBand),
+		errors.Is(err, domain.ErrUnknownCurrency),
+		errors.Is(err, domain.ErrNegativeAmount),
+		errors.Is(err, domain.ErrInvalidBand):
+		return codes.InvalidArgument
+	case errors.Is(err, domain.ErrEmployeeNotFound), errors.Is(err, domain.ErrPositionNotFound):
+		return codes.NotFound
+	case errors.Is(err, domain.ErrPositionInUse):
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
@@ -2,28 +2,41 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 
+	"github.com/dilyara4949/employees-api/internal/bulk"
 	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/middleware"
 	pb "github.com/dilyara4949/employees-api/proto"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type EmployeeServer struct {
-	Repo domain.EmployeesRepository
+	Repo     domain.EmployeesRepository
+	Importer *bulk.Importer
+	Exporter *bulk.Exporter
+	Batcher  *bulk.Batcher
+	Events   *events.Bus
 	pb.UnimplementedEmployeeServiceServer
 }
 
-func NewEmployeeServer(repo domain.EmployeesRepository) *EmployeeServer {
+func NewEmployeeServer(repo domain.EmployeesRepository, importer *bulk.Importer, exporter *bulk.Exporter, batcher *bulk.Batcher, bus *events.Bus) *EmployeeServer {
 	return &EmployeeServer{
-		Repo: repo,
+		Repo:     repo,
+		Importer: importer,
+		Exporter: exporter,
+		Batcher:  batcher,
+		Events:   bus,
 	}
 }
 
 func (s *EmployeeServer) GetAll(ctx context.Context, empty *pb.Empty) (*pb.EmployeesList, error) {
 	employees, err := s.Repo.GetAll(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, internalError(ctx, err)
 	}
 
 	employeeProtos := make([]*pb.Employee, len(employees))
@@ -35,70 +48,174 @@ func (s *EmployeeServer) GetAll(ctx context.Context, empty *pb.Empty) (*pb.Emplo
 
 func (s *EmployeeServer) Get(ctx context.Context, id *pb.Id) (*pb.Employee, error) {
 	if id == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "got nil id in get employee")
+		return nil, invalidArgument(ctx, "got nil id in get employee")
 	}
 
 	employee, err := s.Repo.Get(ctx, id.Value)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, employeeError(ctx, &domain.Employee{ID: id.Value}, err)
 	}
 	return employeeToProto(employee), nil
 }
 
 func (s *EmployeeServer) Create(ctx context.Context, emp *pb.Employee) (*pb.Employee, error) {
 	if emp == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "got nil employee in create employee")
+		return nil, invalidArgument(ctx, "got nil employee in create employee")
 	}
 
 	employee := protoToEmployee(emp)
+	if err := validateSalary(ctx, employee); err != nil {
+		return nil, err
+	}
 
-	err := s.Repo.Create(ctx, employee)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+	if err := s.Repo.Create(ctx, employee); err != nil {
+		return nil, employeeError(ctx, employee, err)
 	}
 	return employeeToProto(employee), nil
 }
 
 func (s *EmployeeServer) Update(ctx context.Context, emp *pb.Employee) (*pb.Employee, error) {
 	if emp == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "got nil employee in update employee")
+		return nil, invalidArgument(ctx, "got nil employee in update employee")
 	}
 
 	employee := protoToEmployee(emp)
+	if err := validateSalary(ctx, employee); err != nil {
+		return nil, err
+	}
 
-	err := s.Repo.Update(ctx, *employee)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+	if err := s.Repo.Update(ctx, *employee); err != nil {
+		return nil, employeeError(ctx, employee, err)
 	}
 	return emp, nil
 }
 
 func (s *EmployeeServer) Delete(ctx context.Context, id *pb.Id) (*pb.Status, error) {
 	if id == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "got nil id in delete employees")
+		return nil, invalidArgument(ctx, "got nil id in delete employees")
 	}
 
-	err := s.Repo.Delete(ctx, id.Value)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+	if err := s.Repo.Delete(ctx, id.Value); err != nil {
+		return nil, employeeError(ctx, &domain.Employee{ID: id.Value}, err)
 	}
 	return &pb.Status{Status: 0}, nil
 }
 
+// validateSalary checks the salary of the request, overriding the position band requires a privileged role.
+func validateSalary(ctx context.Context, employee *domain.Employee) error {
+	if employee.Salary != nil {
+		if err := employee.Salary.Validate(); err != nil {
+			return salaryError(ctx, "salary", err)
+		}
+	}
+
+	if employee.SalaryOverride && !middleware.HasRole(ctx, domain.RoleCompensationAdmin) {
+		return statusError(ctx, codes.PermissionDenied, fmt.Sprintf("salary override requires %s role", domain.RoleCompensationAdmin))
+	}
+	return nil
+}
+
 func employeeToProto(e *domain.Employee) *pb.Employee {
 	if e == nil {
 		return nil
 	}
-	return &pb.Employee{
+	employee := &pb.Employee{
 		Id: e.ID, Firstname: e.FirstName, Lastname: e.LastName, PositionId: e.PositionID,
+		SalaryOverride: e.SalaryOverride, CompaRatio: e.CompaRatio,
+	}
+	if e.Salary != nil {
+		employee.Salary = moneyToProto(*e.Salary)
 	}
+	return employee
 }
 
 func protoToEmployee(e *pb.Employee) *domain.Employee {
 	if e == nil {
 		return nil
 	}
-	return &domain.Employee{
+	employee := &domain.Employee{
 		ID: e.Id, FirstName: e.Firstname, LastName: e.Lastname, PositionID: e.PositionId,
+		SalaryOverride: e.SalaryOverride,
+	}
+	if e.Salary != nil {
+		salary := protoToMoney(e.Salary)
+		employee.Salary = &salary
+	}
+	return employee
+}
+
+func (s *EmployeeServer) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if req == nil || req.Query == "" {
+		return nil, invalidArgument(ctx, "got empty query in search employees", fieldViolation("query", errEmptyQuery))
+	}
+	if req.Limit < 0 {
+		return nil, invalidArgument(ctx, "got negative limit in search employees", fieldViolation("limit", errNegativeLimit))
+	}
+
+	results, err := s.Repo.Search(ctx, req.Query, int(req.Limit))
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+
+	resultProtos := make([]*pb.SearchResult, len(results))
+	for i, result := range results {
+		resultProtos[i] = &pb.SearchResult{Employee: employeeToProto(&result.Employee), Score: result.Score}
+	}
+	return &pb.SearchResponse{Results: resultProtos}, nil
+}
+
+func (s *EmployeeServer) ImportEmployees(stream pb.EmployeeService_ImportEmployeesServer) error {
+	ctx := stream.Context()
+	opts := bulk.ImportOptions{
+		AllowSalaryOverride: middleware.HasRole(ctx, domain.RoleCompensationAdmin),
+	}
+	rows := make([]bulk.EmployeeRow, 0)
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch payload := req.Payload.(type) {
+		case *pb.ImportEmployeesRequest_Options:
+			if len(rows) > 0 {
+				return invalidArgument(ctx, "import options must be sent before the rows")
+			}
+			opts.DryRun = payload.Options.GetDryRun()
+			opts.BestEffort = payload.Options.GetBestEffort()
+		case *pb.ImportEmployeesRequest_Row:
+			row := bulk.EmployeeRow{Row: len(rows) + 1, PositionName: payload.Row.GetPositionName()}
+			if employee := protoToEmployee(payload.Row.GetEmployee()); employee != nil {
+				row.Employee = *employee
+			}
+			rows = append(rows, row)
+		default:
+			return invalidArgument(ctx, "got empty message in import employees")
+		}
+	}
+
+	result, err := s.Importer.ImportEmployees(ctx, rows, nil, opts)
+	if err != nil {
+		return internalError(ctx, err)
+	}
+
+	return stream.SendAndClose(importResultToProto(result))
+}
+
+func importResultToProto(result bulk.ImportResult) *pb.ImportResponse {
+	rowErrors := make([]*pb.ImportRowError, len(result.Errors))
+	for i, rowErr := range result.Errors {
+		rowErrors[i] = &pb.ImportRowError{Row: int32(rowErr.Row), Error: rowErr.Error}
+	}
+	return &pb.ImportResponse{
+		Total:    int32(result.Total),
+		Imported: int32(result.Imported),
+		DryRun:   result.DryRun,
+		Ids:      result.IDs,
+		Errors:   rowErrors,
 	}
 }
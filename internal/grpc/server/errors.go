@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Resource types of the ResourceInfo and PreconditionFailure details.
+const (
+	resourceEmployee = "employee"
+	resourcePosition = "position"
+)
+
+// Violation types of the PreconditionFailure details.
+const (
+	// preconditionPositionExists is violated by an employee referring to a position that doesn't exist.
+	preconditionPositionExists = "POSITION_EXISTS"
+	// preconditionPositionUnused is violated by deleting a position that still has employees.
+	preconditionPositionUnused = "POSITION_UNUSED"
+	// preconditionBandContainsSalaries is violated by a salary band excluding employees of the position.
+	preconditionBandContainsSalaries = "BAND_CONTAINS_SALARIES"
+)
+
+var (
+	errEmptyQuery    = errors.New("must not be empty")
+	errNegativeLimit = errors.New("must not be negative")
+)
+
+// statusError returns an error with the code, message and details, followed by a RequestInfo carrying the
+// correlation ID of the call so clients can report it.
+func statusError(ctx context.Context, code codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, msg)
+
+	details = append(details, &errdetails.RequestInfo{RequestId: correlationID(ctx)})
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		log.Printf("error to attach details to status: %v", err)
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// invalidArgument returns an InvalidArgument error with a BadRequest listing the violations, if there are any.
+func invalidArgument(ctx context.Context, msg string, violations ...*errdetails.BadRequest_FieldViolation) error {
+	if len(violations) == 0 {
+		return statusError(ctx, codes.InvalidArgument, msg)
+	}
+	return statusError(ctx, codes.InvalidArgument, msg, &errdetails.BadRequest{FieldViolations: violations})
+}
+
+// fieldViolation describes the invalid field of the request, the field is named by its proto path like "salary.currency".
+func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{Field: field, Description: err.Error()}
+}
+
+// notFound returns a NotFound error with a ResourceInfo of the missing entity.
+func notFound(ctx context.Context, resourceType, name string) error {
+	return statusError(ctx, codes.NotFound, fmt.Sprintf("%s %s not found", resourceType, name), &errdetails.ResourceInfo{
+		ResourceType: resourceType,
+		ResourceName: name,
+		Description:  fmt.Sprintf("%s does not exist", resourceType),
+	})
+}
+
+// failedPrecondition returns a FailedPrecondition error with a PreconditionFailure of a single violation.
+func failedPrecondition(ctx context.Context, violationType, subject, description string) error {
+	return statusError(ctx, codes.FailedPrecondition, description, &errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{Type: violationType, Subject: subject, Description: description}},
+	})
+}
+
+// internalError logs err and returns an Internal error without its message, clients get the correlation ID
+// of the call to find it in the logs.
+func internalError(ctx context.Context, err error) error {
+	log.Printf("CorrelationID: %s, Error: %v", correlationID(ctx), err)
+	return statusError(ctx, codes.Internal, "internal error")
+}
+
+// salaryError converts the error of validating the money in field.
+func salaryError(ctx context.Context, field string, err error) error {
+	return invalidArgument(ctx, fmt.Sprintf("invalid %s: %v", field, err), fieldViolation(field, err))
+}
+
+// employeeError converts the error of reading or writing the employee to a status error.
+func employeeError(ctx context.Context, employee *domain.Employee, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrEmployeeNotFound):
+		return notFound(ctx, resourceEmployee, employee.ID)
+	case errors.Is(err, domain.ErrPositionNotFound):
+		return failedPrecondition(ctx, preconditionPositionExists, resourcePosition+"/"+employee.PositionID,
+			fmt.Sprintf("position %s of the employee does not exist", employee.PositionID))
+	case errors.Is(err, domain.ErrSalaryOutOfBand):
+		return invalidArgument(ctx, err.Error(), fieldViolation("salary", domain.ErrSalaryOutOfBand))
+	}
+	return internalError(ctx, err)
+}
+
+// positionError converts the error of reading, writing or deleting the position with id to a status error.
+func positionError(ctx context.Context, id string, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrPositionNotFound):
+		return notFound(ctx, resourcePosition, id)
+	case errors.Is(err, domain.ErrPositionInUse):
+		return failedPrecondition(ctx, preconditionPositionUnused, resourcePosition+"/"+id,
+			fmt.Sprintf("position %s has employees assigned, reassign them first", id))
+	case errors.Is(err, domain.ErrBandExcludesEmployees):
+		return failedPrecondition(ctx, preconditionBandContainsSalaries, resourcePosition+"/"+id, err.Error())
+	}
+	return internalError(ctx, err)
+}
+
+func correlationID(ctx context.Context) string {
+	id, _ := ctx.Value(middleware.CorrelationID).(string)
+	return id
+}
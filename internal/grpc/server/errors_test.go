@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	pb "github.com/dilyara4949/employees-api/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorDetails(t *testing.T) {
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+
+	employeeServer := NewEmployeeServer(employees, nil, nil, nil, bus)
+	positionServer := NewPositionServer(positions, uow, nil, nil, bus)
+
+	ctx := context.WithValue(context.Background(), middleware.CorrelationID, "correlation-id")
+
+	engineer, err := positionServer.Create(ctx, &pb.Position{Name: "Engineer", Salary: &pb.Money{Amount: 15000, Currency: "USD"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := employeeServer.Create(ctx, &pb.Employee{Firstname: "Ann", Lastname: "Lee", PositionId: engineer.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		call         func() error
+		expectedCode codes.Code
+		expected     func(detail any) bool
+	}{
+		{
+			name: "missing employee",
+			call: func() error {
+				_, err := employeeServer.Get(ctx, &pb.Id{Value: "missing"})
+				return err
+			},
+			expectedCode: codes.NotFound,
+			expected: func(detail any) bool {
+				info, ok := detail.(*errdetails.ResourceInfo)
+				return ok && info.ResourceType == resourceEmployee && info.ResourceName == "missing"
+			},
+		},
+		{
+			name: "missing position",
+			call: func() error {
+				_, err := positionServer.Delete(ctx, &pb.Id{Value: "missing"})
+				return err
+			},
+			expectedCode: codes.NotFound,
+			expected: func(detail any) bool {
+				info, ok := detail.(*errdetails.ResourceInfo)
+				return ok && info.ResourceType == resourcePosition && info.ResourceName == "missing"
+			},
+		},
+		{
+			name: "dangling position reference",
+			call: func() error {
+				_, err := employeeServer.Create(ctx, &pb.Employee{Firstname: "Bob", Lastname: "Lee", PositionId: "missing"})
+				return err
+			},
+			expectedCode: codes.FailedPrecondition,
+			expected: func(detail any) bool {
+				failure, ok := detail.(*errdetails.PreconditionFailure)
+				return ok && failure.Violations[0].Type == preconditionPositionExists && failure.Violations[0].Subject == "position/missing"
+			},
+		},
+		{
+			name: "position in use",
+			call: func() error {
+				_, err := positionServer.Delete(ctx, &pb.Id{Value: engineer.Id})
+				return err
+			},
+			expectedCode: codes.FailedPrecondition,
+			expected: func(detail any) bool {
+				failure, ok := detail.(*errdetails.PreconditionFailure)
+				return ok && failure.Violations[0].Type == preconditionPositionUnused && failure.Violations[0].Subject == "position/"+engineer.Id
+			},
+		},
+		{
+			name: "invalid salary",
+			call: func() error {
+				_, err := positionServer.Create(ctx, &pb.Position{Name: "Manager", Salary: &pb.Money{Amount: 100, Currency: "XXX"}})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+			expected: func(detail any) bool {
+				badRequest, ok := detail.(*errdetails.BadRequest)
+				return ok && badRequest.FieldViolations[0].Field == "salary"
+			},
+		},
+		{
+			name: "empty search query",
+			call: func() error {
+				_, err := employeeServer.Search(ctx, &pb.SearchRequest{})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+			expected: func(detail any) bool {
+				badRequest, ok := detail.(*errdetails.BadRequest)
+				return ok && badRequest.FieldViolations[0].Field == "query"
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			st, ok := status.FromError(tc.call())
+			if !ok || st.Code() != tc.expectedCode {
+				t.Fatalf("expected %s, got %v", tc.expectedCode, st)
+			}
+
+			details := st.Details()
+			if len(details) != 2 {
+				t.Fatalf("expected a detail and the request info, got %v", details)
+			}
+			if !tc.expected(details[0]) {
+				t.Errorf("unexpected detail %v", details[0])
+			}
+			if info, ok := details[1].(*errdetails.RequestInfo); !ok || info.RequestId != "correlation-id" {
+				t.Errorf("expected request info with the correlation ID, got %v", details[1])
+			}
+		})
+	}
+}
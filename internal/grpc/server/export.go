@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	pb "github.com/dilyara4949/employees-api/proto"
+)
+
+// exportChunkSize is the size above which buffered export data is sent without waiting for a flush.
+const exportChunkSize = 32 * 1024
+
+func (s *EmployeeServer) Export(req *pb.ExportRequest, stream pb.EmployeeService_ExportServer) error {
+	format, columns, err := parseExportRequest(stream.Context(), req, bulk.EmployeeColumns)
+	if err != nil {
+		return err
+	}
+
+	chunks := &chunkWriter{send: stream.Send}
+	writer, err := bulk.NewRowWriter(chunks, format, columns)
+	if err != nil {
+		return internalError(stream.Context(), err)
+	}
+
+	if err := s.Exporter.ExportEmployees(stream.Context(), writer, columns); err != nil {
+		return internalError(stream.Context(), err)
+	}
+	return chunks.Close()
+}
+
+func (s *PositionServer) Export(req *pb.ExportRequest, stream pb.PositionService_ExportServer) error {
+	format, columns, err := parseExportRequest(stream.Context(), req, bulk.PositionColumns)
+	if err != nil {
+		return err
+	}
+
+	chunks := &chunkWriter{send: stream.Send}
+	writer, err := bulk.NewRowWriter(chunks, format, columns)
+	if err != nil {
+		return internalError(stream.Context(), err)
+	}
+
+	if err := s.Exporter.ExportPositions(stream.Context(), writer, columns); err != nil {
+		return internalError(stream.Context(), err)
+	}
+	return chunks.Close()
+}
+
+func parseExportRequest(ctx context.Context, req *pb.ExportRequest, available []string) (bulk.Format, []string, error) {
+	if req == nil {
+		return "", nil, invalidArgument(ctx, "got nil export request")
+	}
+
+	format := bulk.FormatCSV
+	if req.GetFormat() != "" {
+		var err error
+		if format, err = bulk.ParseFormat(req.GetFormat()); err != nil {
+			return "", nil, invalidArgument(ctx, err.Error(), fieldViolation("format", err))
+		}
+	}
+
+	columns := available
+	if len(req.GetColumns()) > 0 {
+		var err error
+		if columns, err = bulk.ParseColumns(strings.Join(req.GetColumns(), ","), available); err != nil {
+			return "", nil, invalidArgument(ctx, err.Error(), fieldViolation("columns", err))
+		}
+	}
+	return format, columns, nil
+}
+
+// chunkWriter buffers the export output and sends it as ExportChunk messages
+// when flushed or when the buffer grows past exportChunkSize.
+type chunkWriter struct {
+	send func(*pb.ExportChunk) error
+	buf  []byte
+	err  error
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= exportChunkSize {
+		c.Flush()
+	}
+	return len(p), c.err
+}
+
+func (c *chunkWriter) Flush() {
+	if c.err != nil || len(c.buf) == 0 {
+		return
+	}
+
+	c.err = c.send(&pb.ExportChunk{Data: c.buf})
+	c.buf = nil
+}
+
+// Close sends the remaining data.
+func (c *chunkWriter) Close() error {
+	c.Flush()
+	return c.err
+}
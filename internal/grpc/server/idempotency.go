@@ -0,0 +1,275 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/idempotency"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// IdempotencyInterceptor replays the stored result of unary calls retried with the same idempotency-key metadata.
+// Reusing a key with a different request fails with InvalidArgument, calls failing with a server side error
+// are not stored so they can be retried.
+func IdempotencyInterceptor(store idempotency.Store, ttl time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		key := idempotencyKey(md)
+		msg, ok := req.(proto.Message)
+		if key == "" || !ok {
+			return handler(ctx, req)
+		}
+		if len(key) > idempotency.MaxKeyLength {
+			return nil, status.Errorf(codes.InvalidArgument, "idempotency key is too long")
+		}
+
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error to marshal request: %v", err)
+		}
+
+		// gRPC calls are not authenticated, so keys are only scoped to the tenant
+		storeKey := tenant.Key(ctx, idempotency.Key("", key))
+		fingerprint := idempotency.Fingerprint([]byte(info.FullMethod), body)
+
+		record, err := check(ctx, store, storeKey, fingerprint)
+		if err != nil {
+			return nil, err
+		}
+
+		if record != nil {
+			grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(idempotency.ReplayedHeader), "true"))
+			return replay(*record)
+		}
+
+		resp, err := handler(ctx, req)
+
+		// the result is stored even if the client went away, that's when it retries
+		storeCtx := context.WithoutCancel(ctx)
+		if serverError(err) {
+			if err := store.Release(storeCtx, storeKey); err != nil {
+				log.Printf("error to release idempotency key: %v", err)
+			}
+			return resp, err
+		}
+
+		if err := complete(storeCtx, store, storeKey, fingerprint, resp, err, ttl); err != nil {
+			log.Printf("error to store idempotency key: %v", err)
+		}
+		return resp, err
+	}
+}
+
+// IdempotencyStreamInterceptor is the counterpart of IdempotencyInterceptor for client streams, which end with
+// a single response. The request messages are received before the handler runs to fingerprint the call, so a
+// retried stream is replayed without being handled again. Server streams only read and are passed through.
+func IdempotencyStreamInterceptor(store idempotency.Store, ttl time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		key := idempotencyKey(md)
+		if key == "" || !info.IsClientStream || info.IsServerStream {
+			return handler(srv, ss)
+		}
+		if len(key) > idempotency.MaxKeyLength {
+			return status.Errorf(codes.InvalidArgument, "idempotency key is too long")
+		}
+
+		stream := &idempotentStream{ServerStream: ss, store: store, key: tenant.Key(ss.Context(), idempotency.Key("", key)), method: info.FullMethod}
+		err := handler(srv, stream)
+
+		switch {
+		case stream.record != nil:
+			resp, err := replay(*stream.record)
+			if err != nil {
+				return err
+			}
+			ss.SetHeader(metadata.Pairs(strings.ToLower(idempotency.ReplayedHeader), "true"))
+			return ss.SendMsg(resp)
+		case !stream.reserved:
+			return err
+		}
+
+		storeCtx := context.WithoutCancel(ss.Context())
+		if serverError(err) {
+			if err := store.Release(storeCtx, stream.key); err != nil {
+				log.Printf("error to release idempotency key: %v", err)
+			}
+			return err
+		}
+
+		if err := complete(storeCtx, store, stream.key, stream.fingerprint, stream.resp, err, ttl); err != nil {
+			log.Printf("error to store idempotency key: %v", err)
+		}
+		return err
+	}
+}
+
+// errReplayed ends the handler of a stream whose stored result is replayed.
+var errReplayed = status.Error(codes.AlreadyExists, "stream is replayed")
+
+// idempotentStream receives all request messages on the first RecvMsg and checks the idempotency key with their
+// fingerprint. If the call is replayed the handler gets errReplayed, otherwise the buffered messages.
+type idempotentStream struct {
+	grpc.ServerStream
+	store  idempotency.Store
+	key    string
+	method string
+
+	drained     bool
+	buffered    []proto.Message
+	fingerprint string
+	reserved    bool
+	record      *idempotency.Record
+	resp        interface{}
+}
+
+func (s *idempotentStream) RecvMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "request is not a proto message")
+	}
+
+	if !s.drained {
+		s.drained = true
+		if err := s.drain(msg); err != nil {
+			return err
+		}
+	}
+	if s.record != nil {
+		return errReplayed
+	}
+	if len(s.buffered) == 0 {
+		return io.EOF
+	}
+
+	proto.Reset(msg)
+	proto.Merge(msg, s.buffered[0])
+	s.buffered = s.buffered[1:]
+	return nil
+}
+
+func (s *idempotentStream) SendMsg(m interface{}) error {
+	s.resp = m
+	return s.ServerStream.SendMsg(m)
+}
+
+// drain receives the messages of the client, they have the type of msg.
+func (s *idempotentStream) drain(msg proto.Message) error {
+	parts := [][]byte{[]byte(s.method)}
+	for {
+		next := msg.ProtoReflect().New().Interface()
+		err := s.ServerStream.RecvMsg(next)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(next)
+		if err != nil {
+			return status.Errorf(codes.Internal, "error to marshal request: %v", err)
+		}
+		parts = append(parts, body)
+		s.buffered = append(s.buffered, next)
+	}
+
+	s.fingerprint = idempotency.Fingerprint(parts...)
+	record, err := check(s.Context(), s.store, s.key, s.fingerprint)
+	if err != nil {
+		return err
+	}
+	s.record = record
+	s.reserved = record == nil
+	return nil
+}
+
+// idempotencyKey returns the idempotency key of the metadata, or "" if there is none.
+func idempotencyKey(md metadata.MD) string {
+	if values := md.Get(idempotency.Header); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// check reserves the key for the call or returns the record to replay, see idempotency.Check.
+func check(ctx context.Context, store idempotency.Store, key, fingerprint string) (*idempotency.Record, error) {
+	record, err := idempotency.Check(ctx, store, key, fingerprint)
+	switch {
+	case errors.Is(err, idempotency.ErrKeyReused):
+		return nil, status.Errorf(codes.InvalidArgument, "idempotency key was already used with a different request")
+	case errors.Is(err, idempotency.ErrInProgress):
+		return nil, status.Errorf(codes.Aborted, "a request with this idempotency key is in progress")
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "error to check idempotency key: %v", err)
+	}
+	return record, nil
+}
+
+// complete stores the result of a call, the status code and either the response wrapped in an Any or the status.
+func complete(ctx context.Context, store idempotency.Store, key, fingerprint string, resp interface{}, callErr error, ttl time.Duration) error {
+	record := idempotency.Record{Fingerprint: fingerprint, Status: int(status.Code(callErr))}
+
+	var msg proto.Message = status.Convert(callErr).Proto()
+	if callErr == nil {
+		respMsg, ok := resp.(proto.Message)
+		if !ok {
+			return errors.New("response is not a proto message")
+		}
+
+		wrapped, err := anypb.New(respMsg)
+		if err != nil {
+			return err
+		}
+		msg = wrapped
+	}
+
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	record.Body = body
+
+	return store.Complete(ctx, key, record, ttl)
+}
+
+// replay returns the stored result of a call.
+func replay(record idempotency.Record) (interface{}, error) {
+	if codes.Code(record.Status) != codes.OK {
+		st := &spb.Status{}
+		if err := proto.Unmarshal(record.Body, st); err != nil {
+			return nil, status.Errorf(codes.Internal, "error to unmarshal stored status: %v", err)
+		}
+		return nil, status.ErrorProto(st)
+	}
+
+	wrapped := &anypb.Any{}
+	if err := proto.Unmarshal(record.Body, wrapped); err != nil {
+		return nil, status.Errorf(codes.Internal, "error to unmarshal stored response: %v", err)
+	}
+	resp, err := wrapped.UnmarshalNew()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error to unmarshal stored response: %v", err)
+	}
+	return resp, nil
+}
+
+// serverError reports whether the call failed on the server side and may succeed if retried.
+func serverError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.DataLoss:
+		return true
+	}
+	return false
+}
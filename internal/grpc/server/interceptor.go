@@ -21,19 +21,86 @@ func LoggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnarySe
 	return h, err
 }
 
+// LoggingStreamInterceptor logs streams like LoggingInterceptor once they end, with the number of messages
+// received and sent instead of the request.
+func LoggingStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	correlationID := ss.Context().Value(middleware.CorrelationID)
+	stream := &countingStream{ServerStream: ss}
+	err := handler(srv, stream)
+
+	log.Printf("Method: %s, CorrelationID: %s, Received: %d, Sent: %d, Duration: %s, Error: %v", info.FullMethod, correlationID, stream.received, stream.sent, time.Since(start), err)
+
+	return err
+}
+
+// CorrelationIDInterceptor puts the correlation ID of the call into the context and sends it back in the
+// response header, a new one is generated if the client didn't send one.
 func CorrelationIDInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		correlationID := getCorrelationIDFromContext(ctx)
+		if err := grpc.SetHeader(ctx, metadata.Pairs(middleware.CorrelationID, correlationID)); err != nil {
+			log.Printf("error to set correlation id header: %v", err)
+		}
+
 		ctx = context.WithValue(ctx, middleware.CorrelationID, correlationID)
 		return handler(ctx, req)
 	}
 }
 
+// CorrelationIDStreamInterceptor is the counterpart of CorrelationIDInterceptor for streams.
+func CorrelationIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		correlationID := getCorrelationIDFromContext(ss.Context())
+		if err := ss.SetHeader(metadata.Pairs(middleware.CorrelationID, correlationID)); err != nil {
+			log.Printf("error to set correlation id header: %v", err)
+		}
+
+		ctx := context.WithValue(ss.Context(), middleware.CorrelationID, correlationID)
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// getCorrelationIDFromContext returns the correlation ID of the incoming metadata. Metadata keys are lowercase,
+// so it is looked up with Get rather than by the canonical header name.
 func getCorrelationIDFromContext(ctx context.Context) string {
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if values := md[middleware.CorrelationID]; len(values) > 0 {
+		if values := md.Get(middleware.CorrelationID); len(values) > 0 && values[0] != "" {
 			return values[0]
 		}
 	}
 	return uuid.New().String()
 }
+
+// contextStream replaces the context of a stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// countingStream counts the messages of a stream.
+type countingStream struct {
+	grpc.ServerStream
+	received int
+	sent     int
+}
+
+func (s *countingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received++
+	}
+	return err
+}
+
+func (s *countingStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent++
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/idempotency"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	pb "github.com/dilyara4949/employees-api/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setUpClients(t *testing.T) (pb.EmployeeServiceClient, pb.PositionServiceClient) {
+	t.Helper()
+
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+	exporter := bulk.NewExporter(employees, positions)
+	batcher := bulk.NewBatcher(uow)
+	idempotencyStore := idempotency.NewMemoryStore()
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			CorrelationIDInterceptor(),
+			LoggingInterceptor,
+			IdempotencyInterceptor(idempotencyStore, time.Hour),
+		),
+		grpc.ChainStreamInterceptor(
+			CorrelationIDStreamInterceptor(),
+			LoggingStreamInterceptor,
+			IdempotencyStreamInterceptor(idempotencyStore, time.Hour),
+		),
+	)
+	pb.RegisterEmployeeServiceServer(srv, NewEmployeeServer(employees, bulk.NewImporter(employees, positions, uow), exporter, batcher, bus))
+	pb.RegisterPositionServiceServer(srv, NewPositionServer(positions, uow, exporter, batcher, bus))
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewEmployeeServiceClient(conn), pb.NewPositionServiceClient(conn)
+}
+
+func TestCorrelationID(t *testing.T) {
+	employees, positions := setUpClients(t)
+
+	tests := []struct {
+		name     string
+		sent     string
+		call     func(ctx context.Context) (metadata.MD, error)
+		expected string
+	}{
+		{
+			name: "unary",
+			sent: "unary-id",
+			call: func(ctx context.Context) (metadata.MD, error) {
+				var header metadata.MD
+				_, err := positions.GetAll(ctx, &pb.Empty{}, grpc.Header(&header))
+				return header, err
+			},
+			expected: "unary-id",
+		},
+		{
+			name: "unary without id",
+			call: func(ctx context.Context) (metadata.MD, error) {
+				var header metadata.MD
+				_, err := positions.GetAll(ctx, &pb.Empty{}, grpc.Header(&header))
+				return header, err
+			},
+		},
+		{
+			name: "stream",
+			sent: "stream-id",
+			call: func(ctx context.Context) (metadata.MD, error) {
+				stream, err := employees.Export(ctx, &pb.ExportRequest{Format: "ndjson"})
+				if err != nil {
+					return nil, err
+				}
+				for err == nil {
+					_, err = stream.Recv()
+				}
+				if err != io.EOF {
+					return nil, err
+				}
+				return stream.Header()
+			},
+			expected: "stream-id",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.sent != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, middleware.CorrelationID, tc.sent)
+			}
+
+			header, err := tc.call(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := header.Get(middleware.CorrelationID)
+			if len(got) != 1 || got[0] == "" || (tc.expected != "" && got[0] != tc.expected) {
+				t.Errorf("expected correlation id %q in the header, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestIdempotencyStreamInterceptor(t *testing.T) {
+	employees, positions := setUpClients(t)
+
+	engineer, err := positions.Create(context.Background(), &pb.Position{Name: "Engineer", Salary: &pb.Money{Amount: 15000, Currency: "USD"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	importEmployees := func(key string, names ...string) (*pb.ImportResponse, metadata.MD, error) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), idempotency.Header, key)
+		stream, err := employees.ImportEmployees(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range names {
+			row := &pb.ImportEmployeeRow{Employee: &pb.Employee{Firstname: name, Lastname: "Lee", PositionId: engineer.Id}}
+			if err := stream.Send(&pb.ImportEmployeesRequest{Payload: &pb.ImportEmployeesRequest_Row{Row: row}}); err != nil {
+				return nil, nil, err
+			}
+		}
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			return nil, nil, err
+		}
+		header, err := stream.Header()
+		return resp, header, err
+	}
+
+	first, _, err := importEmployees("import", "Ann", "Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retried, header, err := importEmployees("import", "Ann", "Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(retried.GetIds(), ",") != strings.Join(first.GetIds(), ",") {
+		t.Errorf("expected the retried import to return %v, got %v", first.GetIds(), retried.GetIds())
+	}
+	if replayed := header.Get(idempotency.ReplayedHeader); len(replayed) != 1 || replayed[0] != "true" {
+		t.Errorf("expected the replayed header, got %v", header)
+	}
+
+	all, err := employees.GetAll(context.Background(), &pb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.GetEmployee()) != 2 {
+		t.Errorf("expected the employees to be imported once, got %d employees", len(all.GetEmployee()))
+	}
+
+	if _, _, err := importEmployees("import", "Cat"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a reused key to fail with InvalidArgument, got %v", err)
+	}
+}
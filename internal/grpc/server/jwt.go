@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// authorizationMetadata is the metadata key of bearer tokens, as sent by gRPC clients.
+const authorizationMetadata = "authorization"
+
+// JWTInterceptor authenticates calls with a bearer token in the authorization metadata and puts its claims and
+// tenant into the context, like the JWT middleware of the REST API, see JWTStreamInterceptor for streams. The
+// in-process gateway passes the claims of the token it verified instead. Calls without token are let through, see
+// AuthRequiredInterceptor.
+func JWTInterceptor(jwtAuth *middleware.JWTAuth) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticateJWT(ctx, jwtAuth)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// JWTStreamInterceptor is the counterpart of JWTInterceptor for streams.
+func JWTStreamInterceptor(jwtAuth *middleware.JWTAuth) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateJWT(ss.Context(), jwtAuth)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticateJWT fails with Unauthenticated if the token isn't accepted and with Internal if it couldn't be checked.
+func authenticateJWT(ctx context.Context, jwtAuth *middleware.JWTAuth) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if claimed, ok := gatewayClaims(ctx, md); ok {
+		return claimed, nil
+	}
+
+	values := md.Get(authorizationMetadata)
+	if len(values) == 0 || values[0] == "" {
+		return ctx, nil
+	}
+
+	tokenString := strings.TrimPrefix(values[0], "Bearer ")
+	if tokenString == values[0] {
+		return ctx, statusError(ctx, codes.Unauthenticated, "bearer token required")
+	}
+
+	authenticated, err := jwtAuth.Authenticate(ctx, tokenString)
+	switch {
+	case errors.Is(err, middleware.ErrInvalidToken):
+		return ctx, statusError(ctx, codes.Unauthenticated, "invalid token")
+	case errors.Is(err, middleware.ErrTokenRevoked):
+		return ctx, statusError(ctx, codes.Unauthenticated, "token revoked")
+	case errors.Is(err, tenant.ErrInvalidTenant):
+		return ctx, statusError(ctx, codes.Unauthenticated, "invalid tenant")
+	case err != nil:
+		log.Printf("error to authenticate token: %v", err)
+		return ctx, statusError(ctx, codes.Internal, "error to authenticate token")
+	}
+	return authenticated, nil
+}
+
+// gatewayClaims returns ctx with the claims of the token the gateway verified, it isn't ok unless the call comes from
+// the gateway and the HTTP request had a token. The tenant is passed by the gateway separately, see TenantInterceptor.
+func gatewayClaims(ctx context.Context, md metadata.MD) (context.Context, bool) {
+	values := md.Get(middleware.JWTClaimsMetadata)
+	if !fromGateway(ctx) || len(values) != 1 {
+		return ctx, false
+	}
+
+	claims := jwt.MapClaims{}
+	if err := json.Unmarshal([]byte(values[0]), &claims); err != nil {
+		log.Printf("error to decode claims of the gateway: %v", err)
+		return ctx, false
+	}
+	return context.WithValue(ctx, middleware.JWTClaims, claims), true
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	pb "github.com/dilyara4949/employees-api/proto"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const jwtSecret = "secret"
+
+type revocationsMock map[string]bool
+
+func (r revocationsMock) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return r[jti], nil
+}
+
+func signedToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWTInterceptor(t *testing.T) {
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+	exporter := bulk.NewExporter(employees, positions)
+
+	jwtAuth := middleware.NewJWTAuth(middleware.JWTConfig{Secret: jwtSecret, TenantClaim: "tenant_id", DefaultTenantFallback: true}, revocationsMock{"revoked": true})
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(CorrelationIDInterceptor(), TenantInterceptor(), JWTInterceptor(jwtAuth)),
+		grpc.ChainStreamInterceptor(CorrelationIDStreamInterceptor(), TenantStreamInterceptor(), JWTStreamInterceptor(jwtAuth)),
+	)
+	pb.RegisterEmployeeServiceServer(srv, NewEmployeeServer(employees, bulk.NewImporter(employees, positions, uow), exporter, bulk.NewBatcher(uow), bus))
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := pb.NewEmployeeServiceClient(conn)
+
+	developers := map[string]*domain.Position{}
+	for _, tenantID := range []string{tenant.Default, "acme"} {
+		developer := &domain.Position{Name: "Developer", Salary: domain.Money{Amount: 100000, Currency: "USD"}}
+		if err := positions.Create(tenant.With(context.Background(), tenantID), developer); err != nil {
+			t.Fatal(err)
+		}
+		developers[tenantID] = developer
+	}
+
+	override := func(positionID string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			_, err := client.Create(ctx, &pb.Employee{
+				Firstname: "John", Lastname: "Smith", PositionId: positionID,
+				Salary: &pb.Money{Amount: 250000, Currency: "USD"}, SalaryOverride: true,
+			})
+			return err
+		}
+	}
+	export := func(ctx context.Context) error {
+		stream, err := client.Export(ctx, &pb.ExportRequest{Format: "csv"})
+		if err != nil {
+			return err
+		}
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+
+	tests := map[string]struct {
+		authorization string
+		call          func(ctx context.Context) error
+		expected      codes.Code
+	}{
+		"override as compensation admin": {
+			authorization: "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann", "role": domain.RoleCompensationAdmin}),
+			call:          override(developers[tenant.Default].ID),
+			expected:      codes.OK,
+		},
+		"override in roles": {
+			authorization: "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann", "roles": []string{"reader", domain.RoleCompensationAdmin}}),
+			call:          override(developers[tenant.Default].ID),
+			expected:      codes.OK,
+		},
+		"override in tenant of the token": {
+			authorization: "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann", "role": domain.RoleCompensationAdmin, "tenant_id": "acme"}),
+			call:          override(developers["acme"].ID),
+			expected:      codes.OK,
+		},
+		"override without role": {
+			authorization: "Bearer " + signedToken(t, jwt.MapClaims{"sub": "bob"}),
+			call:          override(developers[tenant.Default].ID),
+			expected:      codes.PermissionDenied,
+		},
+		"override without token": {
+			call:     override(developers[tenant.Default].ID),
+			expected: codes.PermissionDenied,
+		},
+		"invalid token": {
+			authorization: "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann"}) + "x",
+			call:          override(developers[tenant.Default].ID),
+			expected:      codes.Unauthenticated,
+		},
+		"not a bearer token": {
+			authorization: signedToken(t, jwt.MapClaims{"sub": "ann", "role": domain.RoleCompensationAdmin}),
+			call:          override(developers[tenant.Default].ID),
+			expected:      codes.Unauthenticated,
+		},
+		"revoked token": {
+			authorization: "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann", "role": domain.RoleCompensationAdmin, "jti": "revoked"}),
+			call:          override(developers[tenant.Default].ID),
+			expected:      codes.Unauthenticated,
+		},
+		"invalid tenant": {
+			authorization: "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann", "tenant_id": "acme:employee-1"}),
+			call:          override(developers[tenant.Default].ID),
+			expected:      codes.Unauthenticated,
+		},
+		"stream": {
+			authorization: "Bearer " + signedToken(t, jwt.MapClaims{"sub": "ann"}),
+			call:          export,
+			expected:      codes.OK,
+		},
+		"invalid token on stream": {
+			authorization: "Bearer invalid",
+			call:          export,
+			expected:      codes.Unauthenticated,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tc.authorization)
+			}
+
+			if err := tc.call(ctx); status.Code(err) != tc.expected {
+				t.Errorf("expected %s, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	if list, _ := employees.GetAll(tenant.With(context.Background(), "acme")); len(list) != 1 || !list[0].SalaryOverride {
+		t.Errorf("expected the overridden employee in the tenant of the token, got %+v", list)
+	}
+}
@@ -3,21 +3,25 @@ package server
 import (
 	"context"
 
+	"github.com/dilyara4949/employees-api/internal/bulk"
 	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
 	pb "github.com/dilyara4949/employees-api/proto"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type PositionServer struct {
-	Repo domain.PositionsRepository
+	Repo       domain.PositionsRepository
+	UnitOfWork domain.UnitOfWork
+	Exporter   *bulk.Exporter
+	Batcher    *bulk.Batcher
+	Events     *events.Bus
 	pb.UnimplementedPositionServiceServer
 }
 
 func (s *PositionServer) GetAll(ctx context.Context, empty *pb.Empty) (*pb.PositionsList, error) {
 	positions, err := s.Repo.GetAll(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, internalError(ctx, err)
 	}
 
 	positionProtos := make([]*pb.Position, len(positions))
@@ -27,70 +31,96 @@ func (s *PositionServer) GetAll(ctx context.Context, empty *pb.Empty) (*pb.Posit
 	return &pb.PositionsList{Position: positionProtos}, nil
 }
 
-func NewPositionServer(repo domain.PositionsRepository) *PositionServer {
+func NewPositionServer(repo domain.PositionsRepository, uow domain.UnitOfWork, exporter *bulk.Exporter, batcher *bulk.Batcher, bus *events.Bus) *PositionServer {
 	return &PositionServer{
-		Repo: repo,
+		Repo:       repo,
+		UnitOfWork: uow,
+		Exporter:   exporter,
+		Batcher:    batcher,
+		Events:     bus,
 	}
 }
 
 func (s *PositionServer) Get(ctx context.Context, id *pb.Id) (*pb.Position, error) {
 	if id == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "got nil id in get position")
+		return nil, invalidArgument(ctx, "got nil id in get position")
 	}
 
 	position, err := s.Repo.Get(ctx, id.Value)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "error getting position: %v", err)
+		return nil, positionError(ctx, id.Value, err)
 	}
 	return positionToProto(position), nil
 }
 
 func (s *PositionServer) Create(ctx context.Context, pos *pb.Position) (*pb.Position, error) {
 	if pos == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "got nil position in create position")
+		return nil, invalidArgument(ctx, "got nil position in create position")
 	}
 
 	position := protoToPosition(pos)
+	if err := validatePosition(ctx, position); err != nil {
+		return nil, err
+	}
 
-	err := s.Repo.Create(ctx, position)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+	if err := s.Repo.Create(ctx, position); err != nil {
+		return nil, positionError(ctx, position.ID, err)
 	}
 	return positionToProto(position), nil
 }
 
 func (s *PositionServer) Update(ctx context.Context, pos *pb.Position) (*pb.Position, error) {
 	if pos == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "got nil position in update position")
+		return nil, invalidArgument(ctx, "got nil position in update position")
 	}
 
 	position := protoToPosition(pos)
+	if err := validatePosition(ctx, position); err != nil {
+		return nil, err
+	}
 
-	err := s.Repo.Update(ctx, *position)
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		return domain.UpdatePosition(ctx, tx, *position)
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, positionError(ctx, position.ID, err)
 	}
 	return pos, nil
 }
 
 func (s *PositionServer) Delete(ctx context.Context, id *pb.Id) (*pb.Status, error) {
 	if id == nil {
-		return nil, status.Errorf(codes.InvalidArgument, "got nil id in delete positions")
+		return nil, invalidArgument(ctx, "got nil id in delete positions")
 	}
 
-	err := s.Repo.Delete(ctx, id.Value)
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		return domain.DeletePosition(ctx, tx, id.Value, "")
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, positionError(ctx, id.Value, err)
 	}
 	return &pb.Status{Status: 0}, nil
 }
 
+// validatePosition checks the salary and the salary band of the request.
+func validatePosition(ctx context.Context, position *domain.Position) error {
+	if err := position.Salary.Validate(); err != nil {
+		return salaryError(ctx, "salary", err)
+	}
+	if position.Band != nil {
+		if err := position.Band.Validate(); err != nil {
+			return salaryError(ctx, "band", err)
+		}
+	}
+	return nil
+}
+
 func positionToProto(p *domain.Position) *pb.Position {
 	if p == nil {
 		return nil
 	}
 	return &pb.Position{
-		Id: p.ID, Name: p.Name, Salary: int32(p.Salary),
+		Id: p.ID, Name: p.Name, Salary: moneyToProto(p.Salary), Band: bandToProto(p.Band),
 	}
 }
 
@@ -99,6 +129,31 @@ func protoToPosition(p *pb.Position) *domain.Position {
 		return nil
 	}
 	return &domain.Position{
-		ID: p.Id, Name: p.Name, Salary: int(p.Salary),
+		ID: p.Id, Name: p.Name, Salary: protoToMoney(p.Salary), Band: protoToBand(p.Band),
+	}
+}
+
+func moneyToProto(m domain.Money) *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func protoToMoney(m *pb.Money) domain.Money {
+	if m == nil {
+		return domain.Money{}
+	}
+	return domain.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func bandToProto(b *domain.SalaryBand) *pb.SalaryBand {
+	if b == nil {
+		return nil
+	}
+	return &pb.SalaryBand{Min: moneyToProto(b.Min), Mid: moneyToProto(b.Mid), Max: moneyToProto(b.Max)}
+}
+
+func protoToBand(b *pb.SalaryBand) *domain.SalaryBand {
+	if b == nil {
+		return nil
 	}
+	return &domain.SalaryBand{Min: protoToMoney(b.Min), Mid: protoToMoney(b.Mid), Max: protoToMoney(b.Max)}
 }
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimitInterceptor limits the calls of every method per tenant and client, see RateLimitStreamInterceptor
+// for streams. It has to run after the interceptors setting the tenant.
+func RateLimitInterceptor(limiter ratelimit.Limiter, policy ratelimit.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := allow(ctx, limiter, policy, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor limits the streams opened per method, tenant and client.
+func RateLimitStreamInterceptor(limiter ratelimit.Limiter, policy ratelimit.Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allow(ss.Context(), limiter, policy, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allow takes a token for the call and sets the ratelimit-* headers, calls over the limit fail with ResourceExhausted
+// carrying a RetryInfo. Calls are let through if the limiter fails.
+func allow(ctx context.Context, limiter ratelimit.Limiter, policy ratelimit.Policy, method string) error {
+	result, err := limiter.Allow(ctx, tenant.Key(ctx, "ratelimit:"+method+":"+client(ctx)), policy.For(method, tenant.From(ctx)))
+	if err != nil {
+		log.Printf("error checking rate limit: %v", err)
+		return nil
+	}
+
+	md := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(result.Limit),
+		"ratelimit-remaining", strconv.Itoa(result.Remaining),
+		"ratelimit-reset", strconv.Itoa(int(math.Ceil(result.Reset.Seconds()))),
+	)
+	if !result.Allowed {
+		retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+		md.Set("retry-after", strconv.Itoa(retryAfter))
+		grpc.SetHeader(ctx, md)
+		return statusError(ctx, codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded, retry after %d seconds", retryAfter),
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)})
+	}
+
+	grpc.SetHeader(ctx, md)
+	return nil
+}
+
+// client returns the rate limit key of the caller, the authenticated subject or else the client IP, like the REST API.
+func client(ctx context.Context) string {
+	if sub := middleware.Subject(ctx); sub != "" {
+		return "sub:" + sub
+	}
+	return peerIP(ctx)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "ip:unknown"
+	}
+
+	// calls of the in-process gateway are limited by the address of its HTTP client, which the gateway
+	// appends to x-forwarded-for
+	if p.Addr.Network() == "bufconn" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("x-forwarded-for"); len(values) > 0 {
+			forwarded := strings.Split(values[len(values)-1], ",")
+			return "ip:" + strings.TrimSpace(forwarded[len(forwarded)-1])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return "ip:" + host
+}
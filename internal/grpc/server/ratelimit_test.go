@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	call := func(sub, ip string) context.Context {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}})
+		ctx = context.WithValue(ctx, middleware.CorrelationID, "correlation-id")
+		if sub != "" {
+			ctx = context.WithValue(ctx, middleware.JWTClaims, jwt.MapClaims{"sub": sub})
+		}
+		return ctx
+	}
+
+	tests := map[string]struct {
+		first, second context.Context
+		expectedCode  codes.Code
+	}{
+		"same subject from other ip": {first: call("ann", "10.0.0.1"), second: call("ann", "10.0.0.2"), expectedCode: codes.ResourceExhausted},
+		"other subject from same ip": {first: call("ann", "10.0.0.1"), second: call("bob", "10.0.0.1"), expectedCode: codes.OK},
+		"anonymous from same ip":     {first: call("", "10.0.0.1"), second: call("", "10.0.0.1"), expectedCode: codes.ResourceExhausted},
+		"anonymous from other ip":    {first: call("", "10.0.0.1"), second: call("", "10.0.0.2"), expectedCode: codes.OK},
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/employees.EmployeeService/Get"}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			interceptor := RateLimitInterceptor(ratelimit.NewMemoryLimiter(), ratelimit.Policy{Default: ratelimit.Limit{Requests: 1, Period: time.Minute}})
+
+			if _, err := interceptor(tc.first, nil, info, handler); err != nil {
+				t.Fatal(err)
+			}
+			_, err := interceptor(tc.second, nil, info, handler)
+			st := status.Convert(err)
+			if st.Code() != tc.expectedCode {
+				t.Fatalf("expected %s, got %v", tc.expectedCode, err)
+			}
+			if tc.expectedCode == codes.OK {
+				return
+			}
+
+			var retryInfo *errdetails.RetryInfo
+			var requestInfo *errdetails.RequestInfo
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.RetryInfo:
+					retryInfo = d
+				case *errdetails.RequestInfo:
+					requestInfo = d
+				}
+			}
+			if retryInfo == nil || retryInfo.RetryDelay.AsDuration() <= 0 {
+				t.Errorf("expected a retry delay, got %v", retryInfo)
+			}
+			if requestInfo == nil || requestInfo.RequestId != "correlation-id" {
+				t.Errorf("expected the correlation id, got %v", requestInfo)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// TenantInterceptor puts the tenant passed by the in-process gateway into the context, see TenantStreamInterceptor
+// for streams. The tenant metadata of remote callers is ignored, they act for the tenant of their API key.
+func TenantInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(gatewayTenant(ctx), req)
+	}
+}
+
+// TenantStreamInterceptor is the counterpart of TenantInterceptor for streams.
+func TenantStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: gatewayTenant(ss.Context())})
+	}
+}
+
+// gatewayTenant returns ctx with the tenant of the HTTP request if the call comes from the in-process gateway,
+// which authenticated the request.
+func gatewayTenant(ctx context.Context) context.Context {
+	if !fromGateway(ctx) {
+		return ctx
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(tenant.Metadata)
+	if len(values) != 1 || tenant.Validate(values[0]) != nil {
+		return ctx
+	}
+	return tenant.With(ctx, values[0])
+}
+
+// fromGateway reports whether the call comes from the in-process gateway, which is the only caller on the in-memory
+// listener.
+func fromGateway(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	return ok && p.Addr != nil && p.Addr.Network() == "bufconn"
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	pb "github.com/dilyara4949/employees-api/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *EmployeeServer) Watch(req *pb.WatchRequest, stream pb.EmployeeService_WatchServer) error {
+	sub, err := subscribe(stream.Context(), s.Events, req, domain.EntityEmployee)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		event, err := sub.Next(stream.Context())
+		if err != nil {
+			return watchError(stream.Context(), err)
+		}
+
+		err = stream.Send(&pb.EmployeeEvent{
+			ResumeToken: event.Token,
+			Type:        eventTypeToProto(event.Type),
+			Id:          event.EntityID,
+			Employee:    employeeToProto(event.Employee),
+			Time:        timestamppb.New(event.Time),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *PositionServer) Watch(req *pb.WatchRequest, stream pb.PositionService_WatchServer) error {
+	sub, err := subscribe(stream.Context(), s.Events, req, domain.EntityPosition)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		event, err := sub.Next(stream.Context())
+		if err != nil {
+			return watchError(stream.Context(), err)
+		}
+
+		err = stream.Send(&pb.PositionEvent{
+			ResumeToken: event.Token,
+			Type:        eventTypeToProto(event.Type),
+			Id:          event.EntityID,
+			Position:    positionToProto(event.Position),
+			Time:        timestamppb.New(event.Time),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func subscribe(ctx context.Context, bus *events.Bus, req *pb.WatchRequest, entity domain.EntityType) (*events.Subscription, error) {
+	if req == nil {
+		return nil, invalidArgument(ctx, "got nil watch request")
+	}
+
+	tenantID := tenant.From(ctx)
+	sub, err := bus.Subscribe(req.GetResumeToken(), func(event domain.Event) bool {
+		return event.Entity == entity && event.TenantID == tenantID
+	})
+	switch {
+	case errors.Is(err, events.ErrInvalidToken):
+		return nil, invalidArgument(ctx, err.Error(), fieldViolation("resume_token", err))
+	case errors.Is(err, events.ErrTokenExpired):
+		return nil, statusError(ctx, codes.OutOfRange, fmt.Sprintf("%v, list the entities again and watch without a token", err))
+	case err != nil:
+		return nil, internalError(ctx, err)
+	}
+	return sub, nil
+}
+
+// watchError ends the stream, a lagging subscriber is aborted so it can resume with the token of its last event.
+func watchError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, events.ErrLagged):
+		return statusError(ctx, codes.Aborted, fmt.Sprintf("%v, resume with the last received token", err))
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return internalError(ctx, err)
+}
+
+func eventTypeToProto(eventType domain.EventType) pb.EventType {
+	switch eventType {
+	case domain.EventCreated:
+		return pb.EventType_EVENT_TYPE_CREATED
+	case domain.EventUpdated:
+		return pb.EventType_EVENT_TYPE_UPDATED
+	case domain.EventDeleted:
+		return pb.EventType_EVENT_TYPE_DELETED
+	}
+	return pb.EventType_EVENT_TYPE_UNSPECIFIED
+}
@@ -0,0 +1,183 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Header is the HTTP header and, lowercased, the gRPC metadata key carrying the idempotency key.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from a stored record.
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLength bounds the keys accepted from clients.
+	MaxKeyLength = 255
+
+	// lockTTL bounds how long a request in progress holds its key, so a crashed request doesn't hold it forever.
+	lockTTL = time.Minute
+)
+
+var (
+	ErrKeyReused  = errors.New("idempotency key reused with a different request")
+	ErrInProgress = errors.New("request with the same idempotency key is in progress")
+)
+
+// Record is the stored response of the first request with a key.
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Store keeps the records of idempotency keys.
+type Store interface {
+	// Reserve takes the key for a new request, if it is already taken the existing record is returned instead.
+	Reserve(ctx context.Context, key, fingerprint string) (*Record, error)
+	// Complete stores the response of the request holding the key for ttl.
+	Complete(ctx context.Context, key string, record Record, ttl time.Duration) error
+	// Release frees the key of a request that failed, so it can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// Key scopes a client key to the subject of the request, so different clients can't replay each other's responses.
+func Key(subject, key string) string {
+	return "idempotency:" + subject + ":" + key
+}
+
+// Fingerprint identifies the request the key was first used with.
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Check returns the stored record to replay, or nil if the caller reserved the key and has to handle the request.
+func Check(ctx context.Context, store Store, key, fingerprint string) (*Record, error) {
+	record, err := store.Reserve(ctx, key, fingerprint)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	if record.Fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if !record.Done {
+		return nil, ErrInProgress
+	}
+	return record, nil
+}
+
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Reserve(ctx context.Context, key, fingerprint string) (*Record, error) {
+	pending, err := json.Marshal(Record{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		ok, err := s.client.SetNX(ctx, key, pending, lockTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("error to reserve idempotency key: %w", err)
+		}
+		if ok {
+			return nil, nil
+		}
+
+		value, err := s.client.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// the key expired in between
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error to get idempotency key: %w", err)
+		}
+
+		var record Record
+		if err := json.Unmarshal(value, &record); err != nil {
+			return nil, fmt.Errorf("error to unmarshal idempotency record: %w", err)
+		}
+		return &record, nil
+	}
+}
+
+func (s *RedisStore) Complete(ctx context.Context, key string, record Record, ttl time.Duration) error {
+	record.Done = true
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+// MemoryStore keeps the records in memory, for single instance deployments and tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+}
+
+type memoryRecord struct {
+	Record
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]memoryRecord)}
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, key, fingerprint string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if record, ok := s.records[key]; ok && now.Before(record.expires) {
+		return &record.Record, nil
+	}
+
+	for k, record := range s.records {
+		if !now.Before(record.expires) {
+			delete(s.records, k)
+		}
+	}
+	s.records[key] = memoryRecord{Record: Record{Fingerprint: fingerprint}, expires: now.Add(lockTTL)}
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, record Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.Done = true
+	s.records[key] = memoryRecord{Record: record, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCheck(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	stores := map[string]Store{
+		"redis":  NewRedisStore(client),
+		"memory": NewMemoryStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := Key("ann", name)
+
+			if record, err := Check(ctx, store, key, "a"); record != nil || err != nil {
+				t.Fatalf("expected the key to be reserved, got %+v, %v", record, err)
+			}
+			if _, err := Check(ctx, store, key, "a"); !errors.Is(err, ErrInProgress) {
+				t.Errorf("expected ErrInProgress, got %v", err)
+			}
+
+			if err := store.Complete(ctx, key, Record{Fingerprint: "a", Status: 201, Body: []byte("created")}, time.Hour); err != nil {
+				t.Fatal(err)
+			}
+
+			record, err := Check(ctx, store, key, "a")
+			if err != nil || record == nil || record.Status != 201 || string(record.Body) != "created" {
+				t.Errorf("expected the stored record, got %+v, %v", record, err)
+			}
+			if _, err := Check(ctx, store, key, "b"); !errors.Is(err, ErrKeyReused) {
+				t.Errorf("expected ErrKeyReused, got %v", err)
+			}
+
+			if err := store.Release(ctx, key); err != nil {
+				t.Fatal(err)
+			}
+			if record, err := Check(ctx, store, key, "b"); record != nil || err != nil {
+				t.Errorf("expected the released key to be reserved again, got %+v, %v", record, err)
+			}
+		})
+	}
+}
+
+func TestRedisStore_Expires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := NewRedisStore(client)
+	ctx := context.Background()
+
+	if _, err := store.Reserve(ctx, "k", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Complete(ctx, "k", Record{Fingerprint: "a", Status: 200}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	mr.FastForward(time.Hour + time.Second)
+
+	if record, err := store.Reserve(ctx, "k", "b"); record != nil || err != nil {
+		t.Errorf("expected the expired key to be reserved again, got %+v, %v", record, err)
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+)
+
+const (
+	// APIKeyHeader carries the API key of service-to-service callers, instead of a JWT.
+	APIKeyHeader = "X-API-Key"
+	// APIKey is the context key of the apikey.Key of the caller.
+	APIKey = "api-key"
+)
+
+// APIKeys authenticates API keys and records their use, it is implemented by apikey.Store.
+type APIKeys interface {
+	Authenticate(raw string) (apikey.Key, error)
+}
+
+// Authenticate accepts requests with an API key in the X-API-Key header if the key was granted scope, and the
+// other requests if jwtAuth accepts their token. An empty scope accepts any valid key, for handlers checking
+// the scope themselves such as the gRPC gateway. API key callers act for the tenant of their key.
+func Authenticate(keys APIKeys, scope string, jwtAuth *JWTAuth) Middleware {
+	bearer := jwtAuth.Auth()
+	return func(h http.Handler) http.HandlerFunc {
+		withJWT := bearer(h)
+		return func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get(APIKeyHeader)
+			if raw == "" {
+				withJWT(w, r)
+				return
+			}
+
+			key, err := keys.Authenticate(raw)
+			if err != nil {
+				http.Error(w, "Invalid API key", http.StatusUnauthorized)
+				return
+			}
+			if scope != "" && !key.Allows(scope) {
+				http.Error(w, "API key lacks scope "+scope, http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(tenant.With(r.Context(), key.TenantID), APIKey, key)
+			h.ServeHTTP(w, r.WithContext(ctx))
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"github.com/dilyara4949/employees-api/internal/tenant"
 	"github.com/redis/go-redis/v9"
 	"log"
 	"net/http"
@@ -11,22 +12,30 @@ import (
 func Cache(cache *redis.Client, ttl time.Duration) Middleware {
 	return func(h http.Handler) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/history") {
+				rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+				h.ServeHTTP(rec, r)
+
+				// a scheduled change moves the date the cached entity goes stale
+				if rec.statusCode < http.StatusMultipleChoices {
+					invalidate(r, cache)
+				}
+				return
+			}
+
 			if r.Method != http.MethodGet {
 				h.ServeHTTP(w, r)
 				return
 			}
 
 			id := r.PathValue("id")
-			if id == "" {
+			if id == "" || !strings.HasSuffix(r.URL.Path, "/"+id) {
 				h.ServeHTTP(w, r)
 				return
 			}
 
-			if strings.Contains(r.URL.Path, "/positions/") {
-				id = "position-" + id
-			} else if strings.Contains(r.URL.Path, "/employees/") {
-				id = "employee-" + id
-			} else {
+			id, ok := cacheKey(r, apiVersion(r.URL.Path))
+			if !ok {
 				h.ServeHTTP(w, r)
 				return
 			}
@@ -45,11 +54,56 @@ func Cache(cache *redis.Client, ttl time.Duration) Middleware {
 			rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
 			h.ServeHTTP(rec, r)
 
-			if rec.statusCode == http.StatusOK {
-				cache.Set(r.Context(), id, rec.body.String(), ttl)
+			if rec.statusCode != http.StatusOK {
+				return
+			}
+
+			// the entity changes when the next scheduled change takes effect
+			expiration := ttl
+			if expires, err := http.ParseTime(rec.Header().Get("Expires")); err == nil {
+				expiration = min(expiration, time.Until(expires))
 			}
+			if expiration > 0 {
+				cache.Set(r.Context(), id, rec.body.String(), expiration)
+			}
+		}
+	}
+}
+
+// cacheKey returns the key of the entity of the request, e.g. "employee-1" or "v2:employee-1" for the v2 representation,
+// and false for paths of other resources.
+func cacheKey(r *http.Request, version string) (string, bool) {
+	id := r.PathValue("id")
+	if strings.Contains(r.URL.Path, "/positions/") {
+		id = "position-" + id
+	} else if strings.Contains(r.URL.Path, "/employees/") {
+		id = "employee-" + id
+	} else {
+		return "", false
+	}
+
+	// versions after v1 represent entities differently
+	if version != "" && version != "v1" {
+		id = version + ":" + id
+	}
+	// the same ID is not found in other tenants, their responses mustn't be shared
+	return tenant.Key(r.Context(), id), true
+}
+
+// invalidate removes every cached representation of the entity of the request.
+func invalidate(r *http.Request, cache *redis.Client) {
+	keys := make([]string, 0)
+	for _, version := range []string{"v1", "v2"} {
+		if key, ok := cacheKey(r, version); ok {
+			keys = append(keys, key)
 		}
 	}
+	if len(keys) == 0 {
+		return
+	}
+	if err := cache.Del(r.Context(), keys...).Err(); err != nil {
+		log.Println("error invalidating cache:", err)
+	}
 }
 
 type responseRecorder struct {
@@ -67,3 +121,22 @@ func (rec *responseRecorder) Write(b []byte) (int, error) {
 	rec.body.Write(b)
 	return rec.ResponseWriter.Write(b)
 }
+
+// Unwrap lets http.ResponseController flush the recorded response.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// apiVersion returns the version prefix of the path, e.g. v2 for /v2/employees, or an empty string.
+func apiVersion(path string) string {
+	version, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if len(version) < 2 || version[0] != 'v' {
+		return ""
+	}
+	for _, c := range version[1:] {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	return version
+}
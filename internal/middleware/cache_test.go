@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCache_Expires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { cache.Close() })
+
+	tests := map[string]struct {
+		expires  string
+		expected time.Duration
+	}{
+		"no scheduled change": {
+			expected: time.Hour,
+		},
+		"scheduled change": {
+			expires:  time.Now().Add(10 * time.Minute).UTC().Format(http.TimeFormat),
+			expected: 10 * time.Minute,
+		},
+		"scheduled change after ttl": {
+			expires:  time.Now().Add(2 * time.Hour).UTC().Format(http.TimeFormat),
+			expected: time.Hour,
+		},
+		"stale": {
+			expires: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mr.FlushAll()
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /employees/{id}", Chain(func(w http.ResponseWriter, r *http.Request) {
+				if tc.expires != "" {
+					w.Header().Set("Expires", tc.expires)
+				}
+				w.Write([]byte(`{"id":"1"}`))
+			}, Cache(cache, time.Hour)))
+
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/employees/1", nil))
+
+			// the expiration is rounded to seconds, the request may take one
+			if ttl := mr.TTL("employee-1"); ttl > tc.expected || ttl < tc.expected-time.Second {
+				t.Errorf("expected ttl %v, got %v", tc.expected, ttl)
+			}
+		})
+	}
+}
+
+func TestCache_ScheduleInvalidates(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { cache.Close() })
+
+	status := http.StatusCreated
+	mux := http.NewServeMux()
+	for _, pattern := range []string{"GET /employees/{id}", "GET /v2/employees/{id}", "POST /employees/{id}/history"} {
+		mux.HandleFunc(pattern, Chain(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.WriteHeader(status)
+				return
+			}
+			w.Write([]byte(`{"id":"1"}`))
+		}, Cache(cache, time.Hour)))
+	}
+
+	fill := func() {
+		for _, target := range []string{"/employees/1", "/v2/employees/1"} {
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+		}
+	}
+
+	fill()
+	status = http.StatusBadRequest
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/employees/1/history", nil))
+	if !mr.Exists("employee-1") || !mr.Exists("v2:employee-1") {
+		t.Fatalf("expected a rejected change to keep the cached employee, got keys %v", mr.Keys())
+	}
+
+	status = http.StatusCreated
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/employees/1/history", nil))
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("expected the scheduled change to invalidate every cached representation, got keys %v", keys)
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidDeprecation = errors.New("invalid deprecation")
+
+// Deprecation announces that a route is deprecated since a date and, if Sunset is set, when it will be removed.
+type Deprecation struct {
+	Since  time.Time
+	Sunset time.Time
+}
+
+// Deprecate sets the Deprecation (RFC 9745) and Sunset (RFC 8594) headers of the route.
+func Deprecate(d Deprecation) Middleware {
+	return func(h http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+			if !d.Sunset.IsZero() {
+				w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+			}
+
+			h.ServeHTTP(w, r)
+		}
+	}
+}
+
+// ParseDeprecations parses deprecations of route patterns like "GET /employees=2025-01-01,2025-07-01;GET /positions=2025-01-01",
+// the date after the comma is the sunset. Dates are plain dates or RFC 3339 timestamps.
+func ParseDeprecations(value string) (map[string]Deprecation, error) {
+	deprecations := make(map[string]Deprecation)
+	for _, part := range strings.Split(value, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		route, dates, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q, expected route=deprecation[,sunset]", ErrInvalidDeprecation, part)
+		}
+		since, sunset, _ := strings.Cut(dates, ",")
+
+		var d Deprecation
+		var err error
+		if d.Since, err = parseDeprecationDate(since); err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrInvalidDeprecation, part, err)
+		}
+		if sunset != "" {
+			if d.Sunset, err = parseDeprecationDate(sunset); err != nil {
+				return nil, fmt.Errorf("%w: %q: %w", ErrInvalidDeprecation, part, err)
+			}
+			if d.Sunset.Before(d.Since) {
+				return nil, fmt.Errorf("%w: %q, sunset before deprecation", ErrInvalidDeprecation, part)
+			}
+		}
+		deprecations[strings.TrimSpace(route)] = d
+	}
+	return deprecations, nil
+}
+
+func parseDeprecationDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDeprecate(t *testing.T) {
+	tests := map[string]struct {
+		deprecation    Deprecation
+		expectedSunset string
+	}{
+		"with sunset": {
+			deprecation:    Deprecation{Since: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Sunset: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+			expectedSunset: "Tue, 01 Jul 2025 00:00:00 GMT",
+		},
+		"without sunset": {
+			deprecation: Deprecation{Since: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}, Deprecate(tc.deprecation))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/employees", nil))
+
+			if got := rr.Header().Get("Deprecation"); got != "@1735689600" {
+				t.Errorf("expected Deprecation @1735689600, got %q", got)
+			}
+			if got := rr.Header().Get("Sunset"); got != tc.expectedSunset {
+				t.Errorf("expected Sunset %q, got %q", tc.expectedSunset, got)
+			}
+		})
+	}
+}
+
+func TestParseDeprecations(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    map[string]Deprecation
+		wantErr bool
+	}{
+		"routes": {
+			value: "GET /employees=2025-01-01,2025-07-01T12:00:00Z; GET /positions=2025-01-01",
+			want: map[string]Deprecation{
+				"GET /employees": {Since: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Sunset: time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)},
+				"GET /positions": {Since: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		"empty":                {value: "", want: map[string]Deprecation{}},
+		"missing date":         {value: "GET /employees", wantErr: true},
+		"invalid date":         {value: "GET /employees=soon", wantErr: true},
+		"sunset before":        {value: "GET /employees=2025-07-01,2025-01-01", wantErr: true},
+		"invalid sunset value": {value: "GET /employees=2025-01-01,later", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseDeprecations(tc.value)
+			if tc.wantErr != errors.Is(err, ErrInvalidDeprecation) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
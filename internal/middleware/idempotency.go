@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/idempotency"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+)
+
+// Idempotency replays the stored response of POST and PATCH requests retried with the same Idempotency-Key.
+// Keys are scoped to the JWT subject, so it has to run after Auth. Reusing a key with a different request
+// is rejected with 422, responses with a 5xx status are not stored so the request can be retried.
+func Idempotency(store idempotency.Store, ttl time.Duration) Middleware {
+	return func(h http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotency.Header)
+			if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > idempotency.MaxKeyLength {
+				http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "error reading request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := tenant.Key(r.Context(), idempotency.Key(Subject(r.Context()), key))
+			fingerprint := idempotency.Fingerprint([]byte(r.Method), []byte(r.URL.RequestURI()), body)
+
+			record, err := idempotency.Check(r.Context(), store, storeKey, fingerprint)
+			switch {
+			case errors.Is(err, idempotency.ErrKeyReused):
+				http.Error(w, "Idempotency-Key was already used with a different request", http.StatusUnprocessableEntity)
+				return
+			case errors.Is(err, idempotency.ErrInProgress):
+				http.Error(w, "a request with this Idempotency-Key is in progress", http.StatusConflict)
+				return
+			case err != nil:
+				log.Printf("error checking idempotency key: %v", err)
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if record != nil {
+				if record.ContentType != "" {
+					w.Header().Set("Content-Type", record.ContentType)
+				}
+				w.Header().Set(idempotency.ReplayedHeader, "true")
+				w.WriteHeader(record.Status)
+				w.Write(record.Body)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			h.ServeHTTP(rec, r)
+
+			// the response is stored even if the client went away, that's when it retries
+			ctx := context.WithoutCancel(r.Context())
+			if rec.statusCode >= http.StatusInternalServerError {
+				err = store.Release(ctx, storeKey)
+			} else {
+				err = store.Complete(ctx, storeKey, idempotency.Record{
+					Fingerprint: fingerprint,
+					Status:      rec.statusCode,
+					ContentType: rec.Header().Get("Content-Type"),
+					Body:        []byte(rec.body.String()),
+				}, ttl)
+			}
+			if err != nil {
+				log.Printf("error storing idempotency key: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/idempotency"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+func TestIdempotency(t *testing.T) {
+	type request struct {
+		method  string
+		key     string
+		subject string
+		body    string
+	}
+
+	tests := map[string]struct {
+		status       int
+		requests     []request
+		expectedCode []int
+		expectedBody []string
+		expectedRuns int
+	}{
+		"replayed": {
+			status:       http.StatusCreated,
+			requests:     []request{{method: http.MethodPost, key: "k", body: `{"a":1}`}, {method: http.MethodPost, key: "k", body: `{"a":1}`}},
+			expectedCode: []int{201, 201},
+			expectedBody: []string{`{"run":1}`, `{"run":1}`},
+			expectedRuns: 1,
+		},
+		"different payload": {
+			status:       http.StatusCreated,
+			requests:     []request{{method: http.MethodPost, key: "k", body: `{"a":1}`}, {method: http.MethodPost, key: "k", body: `{"a":2}`}},
+			expectedCode: []int{201, 422},
+			expectedBody: []string{`{"run":1}`, "Idempotency-Key was already used with a different request\n"},
+			expectedRuns: 1,
+		},
+		"other subject": {
+			status:       http.StatusCreated,
+			requests:     []request{{method: http.MethodPost, key: "k", subject: "ann"}, {method: http.MethodPost, key: "k", subject: "bob"}},
+			expectedCode: []int{201, 201},
+			expectedBody: []string{`{"run":1}`, `{"run":2}`},
+			expectedRuns: 2,
+		},
+		"without key": {
+			status:       http.StatusCreated,
+			requests:     []request{{method: http.MethodPost}, {method: http.MethodPost}},
+			expectedCode: []int{201, 201},
+			expectedBody: []string{`{"run":1}`, `{"run":2}`},
+			expectedRuns: 2,
+		},
+		"put ignored": {
+			status:       http.StatusOK,
+			requests:     []request{{method: http.MethodPut, key: "k"}, {method: http.MethodPut, key: "k"}},
+			expectedCode: []int{200, 200},
+			expectedBody: []string{`{"run":1}`, `{"run":2}`},
+			expectedRuns: 2,
+		},
+		"server error retried": {
+			status:       http.StatusInternalServerError,
+			requests:     []request{{method: http.MethodPatch, key: "k"}, {method: http.MethodPatch, key: "k"}},
+			expectedCode: []int{500, 500},
+			expectedBody: []string{`{"run":1}`, `{"run":2}`},
+			expectedRuns: 2,
+		},
+		"key too long": {
+			status:       http.StatusCreated,
+			requests:     []request{{method: http.MethodPost, key: strings.Repeat("k", idempotency.MaxKeyLength+1)}},
+			expectedCode: []int{400},
+			expectedBody: []string{"Idempotency-Key is too long\n"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			runs := 0
+			handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+				runs++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				fmt.Fprintf(w, `{"run":%d}`, runs)
+			}, Idempotency(idempotency.NewMemoryStore(), time.Hour))
+
+			for i, req := range tc.requests {
+				r := httptest.NewRequest(req.method, "/employees", strings.NewReader(req.body))
+				if req.key != "" {
+					r.Header.Set(idempotency.Header, req.key)
+				}
+				if req.subject != "" {
+					r = r.WithContext(context.WithValue(r.Context(), JWTClaims, jwt.MapClaims{"sub": req.subject}))
+				}
+
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, r)
+
+				if rr.Code != tc.expectedCode[i] {
+					t.Errorf("request %d: expected code %d, got %d", i, tc.expectedCode[i], rr.Code)
+				}
+				if rr.Body.String() != tc.expectedBody[i] {
+					t.Errorf("request %d: expected body %q, got %q", i, tc.expectedBody[i], rr.Body.String())
+				}
+			}
+
+			if runs != tc.expectedRuns {
+				t.Errorf("expected %d handler runs, got %d", tc.expectedRuns, runs)
+			}
+		})
+	}
+}
@@ -1,18 +1,76 @@
 package middleware
 
 import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/tenant"
 	jwt "github.com/golang-jwt/jwt/v4"
 )
 
+const JWTClaims = "jwt-claims"
+
+// JWTClaimsMetadata is the gRPC metadata key the in-process gateway passes the JSON encoded claims of the
+// verified token of the HTTP request in, binary as claims may not be ASCII.
+const JWTClaimsMetadata = "x-jwt-claims-bin"
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenRevoked = errors.New("token revoked")
+)
+
+// Revocations reports whether the token with the "jti" claim was revoked.
+type Revocations interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// KeySet returns the public keys that may verify a token signed with the algorithm, only the key with the
+// "kid" unless it is empty.
+type KeySet interface {
+	Keys(ctx context.Context, kid, alg string) ([]crypto.PublicKey, error)
+}
+
+// JWTConfig configures the tokens accepted by JWTAuth.
+type JWTConfig struct {
+	// Secret verifies HMAC signed tokens, such as the tokens issued at /auth/token.
+	Secret string
+	// Keys verifies RS256, ES256 and EdDSA signed tokens, e.g. of an identity provider. Only HMAC signed tokens
+	// are accepted if it is nil.
+	Keys KeySet
+	// Issuers are the accepted "iss" claims, and Audience must be in the "aud" claim. Neither is checked if empty.
+	Issuers  []string
+	Audience string
+	// TenantClaim is the claim carrying the tenant of the caller, e.g. "tenant_id". Tokens without it are rejected
+	// unless DefaultTenantFallback is set, every token acts for the default tenant if it is empty.
+	TenantClaim string
+	// DefaultTenantFallback lets tokens without TenantClaim act for the default tenant.
+	DefaultTenantFallback bool
+}
+
+var validMethods = []string{
+	"HS256", "HS384", "HS512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
 type JWTAuth struct {
-	secret string
+	config      JWTConfig
+	revocations Revocations
+	parser      *jwt.Parser
 }
 
-func NewJWTAuth(secret string) *JWTAuth {
-	return &JWTAuth{secret}
+// NewJWTAuth verifies tokens as configured. Tokens with a "jti" claim are checked against revocations, unless
+// it is nil.
+func NewJWTAuth(config JWTConfig, revocations Revocations) *JWTAuth {
+	return &JWTAuth{config: config, revocations: revocations, parser: jwt.NewParser(jwt.WithValidMethods(validMethods))}
 }
 
 func (j *JWTAuth) Auth() Middleware {
@@ -32,20 +90,165 @@ func (j *JWTAuth) Auth() Middleware {
 				return
 			}
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.NewValidationError("unexpected signing method", jwt.ValidationErrorSignatureInvalid)
-				}
-
-				return []byte(j.secret), nil
-			})
-
-			if err != nil || !token.Valid {
+			ctx, err := j.Authenticate(r.Context(), tokenString)
+			switch {
+			case errors.Is(err, ErrInvalidToken):
 				http.Error(w, "Invalid token", http.StatusUnauthorized)
 				return
+			case errors.Is(err, ErrTokenRevoked):
+				http.Error(w, "Token revoked", http.StatusUnauthorized)
+				return
+			case errors.Is(err, tenant.ErrInvalidTenant):
+				http.Error(w, "Invalid tenant", http.StatusUnauthorized)
+				return
+			case err != nil:
+				log.Printf("Error to authenticate token: %v", err)
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
 			}
 
-			h.ServeHTTP(w, r)
+			h.ServeHTTP(w, r.WithContext(ctx))
 		}
 	}
 }
+
+// Authenticate verifies the token and returns ctx with its claims under JWTClaims and its tenant. It fails with
+// ErrInvalidToken, ErrTokenRevoked or tenant.ErrInvalidTenant if the token isn't accepted, other errors mean the
+// token couldn't be checked.
+func (j *JWTAuth) Authenticate(ctx context.Context, tokenString string) (context.Context, error) {
+	token, err := j.parse(ctx, tokenString)
+	if err != nil || !token.Valid {
+		return ctx, ErrInvalidToken
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+	if !j.validClaims(claims) {
+		return ctx, ErrInvalidToken
+	}
+
+	if jti, _ := claims["jti"].(string); jti != "" && j.revocations != nil {
+		revoked, err := j.revocations.IsRevoked(ctx, jti)
+		if err != nil {
+			return ctx, fmt.Errorf("error to check token revocation: %w", err)
+		}
+		if revoked {
+			return ctx, ErrTokenRevoked
+		}
+	}
+
+	tenantID, ok := j.tenant(claims)
+	if !ok {
+		return ctx, tenant.ErrInvalidTenant
+	}
+
+	ctx = tenant.With(ctx, tenantID)
+	if claims != nil {
+		ctx = context.WithValue(ctx, JWTClaims, claims)
+	}
+	return ctx, nil
+}
+
+// parse verifies the token with the secret if it is HMAC signed, otherwise with the keys matching its "kid".
+// A token without "kid" is tried with every key of its algorithm.
+func (j *JWTAuth) parse(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	unverified, _, err := j.parser.ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := unverified.Method.(*jwt.SigningMethodHMAC); ok {
+		return j.parser.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+			return []byte(j.config.Secret), nil
+		})
+	}
+
+	if j.config.Keys == nil {
+		return nil, jwt.NewValidationError("unexpected signing method", jwt.ValidationErrorSignatureInvalid)
+	}
+	kid, _ := unverified.Header["kid"].(string)
+	keys, err := j.config.Keys.Keys(ctx, kid, unverified.Method.Alg())
+	if err != nil {
+		return nil, err
+	}
+
+	err = jwt.NewValidationError("no key matches the token", jwt.ValidationErrorUnverifiable)
+	for _, key := range keys {
+		var token *jwt.Token
+		token, err = j.parser.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+			return key, nil
+		})
+		if err == nil {
+			return token, nil
+		}
+	}
+	return nil, err
+}
+
+func (j *JWTAuth) validClaims(claims jwt.MapClaims) bool {
+	if j.config.Audience != "" && !claims.VerifyAudience(j.config.Audience, true) {
+		return false
+	}
+	if len(j.config.Issuers) == 0 {
+		return true
+	}
+
+	iss, _ := claims["iss"].(string)
+	for _, issuer := range j.config.Issuers {
+		if iss == issuer {
+			return true
+		}
+	}
+	return false
+}
+
+// tenant returns the tenant of the claims, it isn't ok if the tenant claim isn't a valid tenant ID or is missing
+// without DefaultTenantFallback.
+func (j *JWTAuth) tenant(claims jwt.MapClaims) (string, bool) {
+	if j.config.TenantClaim == "" {
+		return tenant.Default, true
+	}
+	value, present := claims[j.config.TenantClaim]
+	if !present {
+		return tenant.Default, j.config.DefaultTenantFallback
+	}
+
+	id, _ := value.(string)
+	return id, tenant.Validate(id) == nil
+}
+
+// HasRole reports whether the JWT claims in the context grant the role,
+// either as the "role" claim or as an element of the "roles" claim.
+func HasRole(ctx context.Context, role string) bool {
+	claims, ok := ctx.Value(JWTClaims).(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+
+	if r, ok := claims["role"].(string); ok && r == role {
+		return true
+	}
+
+	roles, _ := claims["roles"].([]interface{})
+	for _, r := range roles {
+		if r, ok := r.(string); ok && r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Subject returns the "sub" claim of the JWT claims in the context, or "apikey:" followed by the ID of the
+// API key of the caller, or an empty string.
+func Subject(ctx context.Context) string {
+	if key, ok := ctx.Value(APIKey).(apikey.Key); ok {
+		return "apikey:" + key.ID
+	}
+
+	claims, ok := ctx.Value(JWTClaims).(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+
+	sub, _ := claims["sub"].(string)
+	return sub
+}
@@ -0,0 +1,364 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+func TestHasRole(t *testing.T) {
+	const secret = "secret"
+
+	tests := map[string]struct {
+		claims   jwt.MapClaims
+		expected bool
+	}{
+		"role claim": {
+			claims:   jwt.MapClaims{"role": "admin"},
+			expected: true,
+		},
+		"roles claim": {
+			claims:   jwt.MapClaims{"roles": []string{"viewer", "admin"}},
+			expected: true,
+		},
+		"other role": {
+			claims:   jwt.MapClaims{"role": "viewer"},
+			expected: false,
+		},
+		"no roles": {
+			claims:   jwt.MapClaims{"sub": "user"},
+			expected: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString([]byte(secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got bool
+			handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+				got = HasRole(r.Context(), "admin")
+			}, NewJWTAuth(JWTConfig{Secret: secret}, nil).Auth())
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+type revocations map[string]bool
+
+func (r revocations) IsRevoked(_ context.Context, jti string) (bool, error) {
+	if jti == "broken" {
+		return false, errors.New("connection refused")
+	}
+	return r[jti], nil
+}
+
+func TestJWTAuth_Revocations(t *testing.T) {
+	const secret = "secret"
+
+	tests := map[string]struct {
+		claims         jwt.MapClaims
+		expectedStatus int
+	}{
+		"not revoked": {
+			claims:         jwt.MapClaims{"jti": "1"},
+			expectedStatus: http.StatusOK,
+		},
+		"revoked": {
+			claims:         jwt.MapClaims{"jti": "2"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"no jti": {
+			claims:         jwt.MapClaims{"sub": "user"},
+			expectedStatus: http.StatusOK,
+		},
+		"revocations unavailable": {
+			claims:         jwt.MapClaims{"jti": "broken"},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	auth := NewJWTAuth(JWTConfig{Secret: secret}, revocations{"2": true}).Auth()
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString([]byte(secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler := Chain(func(w http.ResponseWriter, r *http.Request) {}, auth)
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+// keySet is a KeySet of the keys by kid.
+type keySet map[string]crypto.PublicKey
+
+func (k keySet) Keys(_ context.Context, kid, _ string) ([]crypto.PublicKey, error) {
+	if kid != "" {
+		if key, ok := k[kid]; ok {
+			return []crypto.PublicKey{key}, nil
+		}
+		return nil, nil
+	}
+
+	keys := make([]crypto.PublicKey, 0, len(k))
+	for _, key := range k {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func TestJWTAuth_Keys(t *testing.T) {
+	const secret = "secret"
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotatedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknownKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := keySet{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey, "ed": edPublic, "rotated": &rotatedKey.PublicKey}
+	claims := jwt.MapClaims{"sub": "user", "iss": "https://idp.example.com/", "aud": "employees"}
+
+	tests := map[string]struct {
+		method         jwt.SigningMethod
+		key            interface{}
+		kid            string
+		claims         jwt.MapClaims
+		config         JWTConfig
+		expectedStatus int
+	}{
+		"RS256": {
+			method:         jwt.SigningMethodRS256,
+			key:            rsaKey,
+			kid:            "rsa",
+			expectedStatus: http.StatusOK,
+		},
+		"ES256": {
+			method:         jwt.SigningMethodES256,
+			key:            ecKey,
+			kid:            "ec",
+			expectedStatus: http.StatusOK,
+		},
+		"EdDSA": {
+			method:         jwt.SigningMethodEdDSA,
+			key:            edKey,
+			kid:            "ed",
+			expectedStatus: http.StatusOK,
+		},
+		"rotated key": {
+			method:         jwt.SigningMethodES256,
+			key:            rotatedKey,
+			kid:            "rotated",
+			expectedStatus: http.StatusOK,
+		},
+		"no kid": {
+			method:         jwt.SigningMethodES256,
+			key:            rotatedKey,
+			expectedStatus: http.StatusOK,
+		},
+		"wrong kid": {
+			method:         jwt.SigningMethodES256,
+			key:            rotatedKey,
+			kid:            "ec",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"unknown key": {
+			method:         jwt.SigningMethodRS256,
+			key:            unknownKey,
+			kid:            "unknown",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"no key set": {
+			method:         jwt.SigningMethodRS256,
+			key:            rsaKey,
+			kid:            "rsa",
+			config:         JWTConfig{Secret: secret},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"HMAC": {
+			method:         jwt.SigningMethodHS256,
+			key:            []byte(secret),
+			expectedStatus: http.StatusOK,
+		},
+		"other issuer": {
+			method:         jwt.SigningMethodRS256,
+			key:            rsaKey,
+			kid:            "rsa",
+			claims:         jwt.MapClaims{"sub": "user", "iss": "https://other.example.com/", "aud": "employees"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"other audience": {
+			method:         jwt.SigningMethodRS256,
+			key:            rsaKey,
+			kid:            "rsa",
+			claims:         jwt.MapClaims{"sub": "user", "iss": "https://idp.example.com/", "aud": []string{"payroll"}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"no issuer or audience": {
+			method:         jwt.SigningMethodRS256,
+			key:            rsaKey,
+			kid:            "rsa",
+			claims:         jwt.MapClaims{"sub": "user"},
+			config:         JWTConfig{Secret: secret, Keys: keys},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := tt.config
+			if config.Secret == "" {
+				config = JWTConfig{Secret: secret, Keys: keys, Issuers: []string{"employees-api", "https://idp.example.com/"}, Audience: "employees"}
+			}
+			if tt.claims == nil {
+				tt.claims = claims
+			}
+
+			token := jwt.NewWithClaims(tt.method, tt.claims)
+			if tt.kid != "" {
+				token.Header["kid"] = tt.kid
+			}
+			signed, err := token.SignedString(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var subject string
+			handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+				subject = Subject(r.Context())
+			}, NewJWTAuth(config, nil).Auth())
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", signed))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if tt.expectedStatus == http.StatusOK && subject != "user" {
+				t.Errorf("expected subject user, got %q", subject)
+			}
+		})
+	}
+}
+
+func TestJWTAuth_Tenant(t *testing.T) {
+	const secret = "secret"
+
+	tests := map[string]struct {
+		tenantClaim    string
+		fallback       bool
+		claims         jwt.MapClaims
+		expectedStatus int
+		expectedTenant string
+	}{
+		"tenant claim": {
+			tenantClaim:    "tenant_id",
+			claims:         jwt.MapClaims{"sub": "user", "tenant_id": "acme"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "acme",
+		},
+		"no tenant claim": {
+			tenantClaim:    "tenant_id",
+			claims:         jwt.MapClaims{"sub": "user"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"no tenant claim with fallback": {
+			tenantClaim:    "tenant_id",
+			fallback:       true,
+			claims:         jwt.MapClaims{"sub": "user"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: tenant.Default,
+		},
+		"tenancy disabled": {
+			claims:         jwt.MapClaims{"sub": "user", "tenant_id": "acme"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: tenant.Default,
+		},
+		"invalid tenant": {
+			tenantClaim:    "tenant_id",
+			claims:         jwt.MapClaims{"sub": "user", "tenant_id": "acme:employee-1"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"tenant not a string": {
+			tenantClaim:    "tenant_id",
+			claims:         jwt.MapClaims{"sub": "user", "tenant_id": 1},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString([]byte(secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tenantID := "unset"
+			handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+				tenantID = tenant.From(r.Context())
+			}, NewJWTAuth(JWTConfig{Secret: secret, TenantClaim: tt.tenantClaim, DefaultTenantFallback: tt.fallback}, nil).Auth())
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if tt.expectedStatus == http.StatusOK && tenantID != tt.expectedTenant {
+				t.Errorf("expected tenant %q, got %q", tt.expectedTenant, tenantID)
+			}
+		})
+	}
+}
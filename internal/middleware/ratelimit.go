@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+)
+
+// RateLimit limits the requests to route per client, identified by its tenant and the JWT subject or else the
+// client IP, so it has to run after Auth. The limit of the route for the tenant is taken from policy.
+// Responses carry RateLimit-* headers, rejected requests get 429 and Retry-After.
+// Requests are let through if the limiter fails, so an outage of Redis doesn't take the API down.
+func RateLimit(limiter ratelimit.Limiter, route string, policy ratelimit.Policy) Middleware {
+	return func(h http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			result, err := limiter.Allow(ctx, tenant.Key(ctx, "ratelimit:"+route+":"+client(r)), policy.For(route, tenant.From(ctx)))
+			if err != nil {
+				log.Printf("error checking rate limit: %v", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", seconds(result.Reset))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", seconds(result.RetryAfter))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		}
+	}
+}
+
+// client returns the rate limit key of the caller.
+func client(r *http.Request) string {
+	if sub := Subject(r.Context()); sub != "" {
+		return "sub:" + sub
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds formats the duration in whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
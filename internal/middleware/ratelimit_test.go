@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+func TestRateLimit(t *testing.T) {
+	handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, RateLimit(ratelimit.NewMemoryLimiter(), "GET /employees", ratelimit.Policy{Default: ratelimit.Limit{Requests: 1, Period: time.Minute}}))
+
+	request := func(subject, remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/employees", nil)
+		r.RemoteAddr = remoteAddr
+		if subject != "" {
+			r = r.WithContext(context.WithValue(r.Context(), JWTClaims, jwt.MapClaims{"sub": subject}))
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	rr := request("ann", "10.0.0.1:1234")
+	if rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != "1" || rr.Header().Get("RateLimit-Remaining") != "0" || rr.Header().Get("RateLimit-Reset") != "60" {
+		t.Errorf("expected allowed request with rate limit headers, got %d %v", rr.Code, rr.Header())
+	}
+
+	rr = request("ann", "10.0.0.2:1234")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "60" || rr.Body.String() != "rate limit exceeded\n" {
+		t.Errorf("expected 429 for the same subject, got %d %v %q", rr.Code, rr.Header(), rr.Body.String())
+	}
+
+	if rr = request("bob", "10.0.0.1:1234"); rr.Code != http.StatusOK {
+		t.Errorf("expected another subject to be allowed, got %d", rr.Code)
+	}
+
+	if rr = request("", "10.0.0.3:1234"); rr.Code != http.StatusOK {
+		t.Errorf("expected first request of an IP to be allowed, got %d", rr.Code)
+	}
+	if rr = request("", "10.0.0.3:5678"); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for the same IP, got %d", rr.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/employees", nil)
+	r = r.WithContext(context.WithValue(tenant.With(r.Context(), "acme"), JWTClaims, jwt.MapClaims{"sub": "ann"}))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected the same subject of another tenant to be allowed, got %d", rr.Code)
+	}
+}
+
+func TestRateLimit_Tenants(t *testing.T) {
+	policy := ratelimit.Policy{
+		Default: ratelimit.Limit{Requests: 1, Period: time.Minute},
+		Tenants: map[string]ratelimit.Limit{"acme": {Requests: 100, Period: time.Minute}},
+	}
+	handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, RateLimit(ratelimit.NewMemoryLimiter(), "GET /employees", policy))
+
+	for tenantID, expected := range map[string]string{"acme": "100", "globex": "1"} {
+		r := httptest.NewRequest(http.MethodGet, "/employees", nil)
+		r = r.WithContext(tenant.With(r.Context(), tenantID))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Limit") != expected {
+			t.Errorf("expected the limit of %s to be %s, got %d %v", tenantID, expected, rr.Code, rr.Header())
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+func init() {
+	// imports accept NDJSON, the rows are validated by the importer
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/jsonl", openapi3filter.FileBodyDecoder)
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Validate checks the requests of the route, a pattern like "GET /employees/{id}", against its operation in the
+// OpenAPI document and rejects invalid requests with 400. JSON responses are validated as well, a response that
+// doesn't match is still sent and only logged. Authentication is left to Auth, so Validate should run after it.
+func Validate(doc *openapi3.T, route string) Middleware {
+	method, path, _ := strings.Cut(route, " ")
+
+	var operation *routers.Route
+	if item := doc.Paths.Find(path); item != nil {
+		if op := item.GetOperation(method); op != nil {
+			operation = &routers.Route{Spec: doc, Path: path, PathItem: item, Method: method, Operation: op}
+		}
+	}
+
+	params := make([]string, 0)
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		params = append(params, match[1])
+	}
+
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc, IncludeResponseStatus: true}
+
+	return func(h http.Handler) http.HandlerFunc {
+		if operation == nil {
+			log.Printf("route %s is not documented in the openapi spec, its requests are not validated", route)
+			return h.ServeHTTP
+		}
+
+		return func(w http.ResponseWriter, r *http.Request) {
+			pathValues := make(map[string]string, len(params))
+			for _, name := range params {
+				pathValues[name] = r.PathValue(name)
+			}
+
+			input := &openapi3filter.RequestValidationInput{Request: r, PathParams: pathValues, Route: operation, Options: options}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				http.Error(w, validationDetail(err), http.StatusBadRequest)
+				return
+			}
+
+			rec := &validationRecorder{ResponseWriter: w}
+			h.ServeHTTP(rec, r)
+			if !rec.buffered {
+				return
+			}
+
+			err := openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 rec.statusCode,
+				Header:                 rec.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+				Options:                options,
+			})
+			if err != nil {
+				log.Printf("response of %s does not match the openapi spec: %s", route, validationDetail(err))
+			}
+
+			w.WriteHeader(rec.statusCode)
+			w.Write(rec.body.Bytes())
+		}
+	}
+}
+
+// validationDetail describes the validation error without the dump of the schema that schema errors include.
+func validationDetail(err error) string {
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return err.Error()
+	}
+
+	detail := schemaErr.Reason
+	if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+		detail = strings.Join(pointer, ".") + ": " + detail
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		switch {
+		case requestErr.Parameter != nil:
+			return fmt.Sprintf("parameter %q in %s has an error: %s", requestErr.Parameter.Name, requestErr.Parameter.In, detail)
+		case requestErr.RequestBody != nil:
+			return "request body has an error: " + detail
+		}
+	}
+	return detail
+}
+
+// validationRecorder holds back JSON responses until they were validated, other responses like exports and
+// event streams are passed through.
+type validationRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	buffered    bool
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *validationRecorder) WriteHeader(code int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.wroteHeader = true
+	rec.statusCode = code
+
+	if mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type")); mediaType == "application/json" {
+		rec.buffered = true
+		return
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *validationRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if rec.buffered {
+		return rec.body.Write(b)
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController flush event streams.
+func (rec *validationRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
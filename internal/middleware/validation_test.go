@@ -0,0 +1,225 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const validationSpec = `
+openapi: "3.0.0"
+info:
+  title: test
+  version: "1.0.0"
+paths:
+  /export:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            text/csv:
+              schema:
+                type: string
+  /events:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            text/event-stream:
+              schema:
+                type: string
+  /items/{id}:
+    parameters:
+      - in: path
+        name: id
+        required: true
+        schema:
+          type: string
+          minLength: 3
+    put:
+      parameters:
+        - in: query
+          name: limit
+          schema:
+            type: integer
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: string
+        default:
+          description: error
+          content:
+            text/plain:
+              schema:
+                type: string
+`
+
+func TestValidate(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(validationSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		target           string
+		body             string
+		response         string
+		contentType      string
+		expectedStatus   int
+		expectedBody     string
+		expectedMismatch bool
+	}{
+		"valid": {
+			target:         "/items/abc",
+			body:           `{"name":"item"}`,
+			response:       `{"id":"abc"}`,
+			contentType:    "application/json",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"abc"}`,
+		},
+		"invalid path parameter": {
+			target:         "/items/ab",
+			body:           `{"name":"item"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `parameter "id" in path has an error: minimum string length is 3` + "\n",
+		},
+		"invalid query parameter": {
+			target:         "/items/abc?limit=many",
+			body:           `{"name":"item"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"missing property": {
+			target:         "/items/abc",
+			body:           `{"title":"item"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `request body has an error: name: property "name" is missing` + "\n",
+		},
+		"invalid property": {
+			target:         "/items/abc",
+			body:           `{"name":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "request body has an error: name: value must be a string\n",
+		},
+		"response not matching is sent": {
+			target:           "/items/abc",
+			body:             `{"name":"item"}`,
+			response:         `{"name":"item"}`,
+			contentType:      "application/json",
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `{"name":"item"}`,
+			expectedMismatch: true,
+		},
+		"error response is passed through": {
+			target:         "/items/abc",
+			body:           `{"name":"item"}`,
+			response:       "not found",
+			contentType:    "text/plain; charset=utf-8",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "not found",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("PUT /items/{id}", Chain(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.Write([]byte(tc.response))
+			}, Validate(doc, "PUT /items/{id}")))
+
+			r := httptest.NewRequest(http.MethodPut, tc.target, strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, r)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d %s", tc.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, rr.Body.String())
+			}
+			if mismatch := strings.Contains(logs.String(), "does not match the openapi spec"); mismatch != tc.expectedMismatch {
+				t.Errorf("expected mismatch logged %v, got %q", tc.expectedMismatch, logs.String())
+			}
+		})
+	}
+}
+
+func TestValidate_Streams(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(validationSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("data: 1\n\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("expected event stream to be flushed, got %v", err)
+		}
+	}, Validate(doc, "GET /events"))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if !rr.Flushed || rr.Body.String() != "data: 1\n\n" {
+		t.Errorf("expected flushed event stream, got %q", rr.Body.String())
+	}
+}
+
+func TestValidate_Export(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(validationSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := Chain(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", bulk.ContentType(bulk.FormatCSV))
+		writer, err := bulk.NewRowWriter(w, bulk.FormatCSV, []string{"id"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]any{"1"})
+		if err := writer.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if !rr.Flushed || rr.Body.String() != "id\n1\n" {
+			t.Errorf("expected the rows to be flushed before the export finished, got %q", rr.Body.String())
+		}
+		writer.Close()
+	}, Validate(doc, "GET /export"))
+
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/export", nil))
+}
@@ -0,0 +1,76 @@
+package outbox
+
+import (
+	"sync"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Entry is an event waiting in the outbox to be relayed.
+type Entry struct {
+	// ID identifies the event for consumers deduplicating redelivered messages.
+	ID    string
+	Event domain.Event
+	seq   uint64
+}
+
+// Outbox keeps the events of committed repository writes until the relay acknowledges them.
+// The store publishes to it with its lock held, so an event is enqueued if and only if its write is committed.
+type Outbox struct {
+	mu      sync.Mutex
+	seq     uint64
+	entries []Entry
+	notify  chan struct{}
+}
+
+func New() *Outbox {
+	return &Outbox{notify: make(chan struct{}, 1)}
+}
+
+// Publish enqueues the event, it implements domain.EventPublisher.
+func (o *Outbox) Publish(event domain.Event) {
+	o.mu.Lock()
+	o.seq++
+	o.entries = append(o.entries, Entry{ID: uuid.New().String(), Event: event, seq: o.seq})
+	o.mu.Unlock()
+
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns up to limit of the oldest entries that were not acknowledged, they stay in the outbox.
+func (o *Outbox) Pending(limit int) []Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := min(limit, len(o.entries))
+	return append([]Entry(nil), o.entries[:n]...)
+}
+
+// Ack removes the entry and the entries enqueued before it.
+func (o *Outbox) Ack(entry Entry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := 0
+	for n < len(o.entries) && o.entries[n].seq <= entry.seq {
+		n++
+	}
+	o.entries = append([]Entry(nil), o.entries[n:]...)
+}
+
+// Len returns the number of entries waiting to be relayed.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.entries)
+}
+
+// Notify is signaled when an entry is enqueued.
+func (o *Outbox) Notify() <-chan struct{} {
+	return o.notify
+}
@@ -0,0 +1,109 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultRetryInterval = time.Second
+	defaultMaxLen        = 100000
+)
+
+// Relay publishes the outbox to a Redis Stream. Entries are acknowledged only after all their messages were added,
+// so every event is published at least once and consumers deduplicate on the event_id and event fields.
+type Relay struct {
+	Outbox *Outbox
+	Client *redis.Client
+	Stream string
+	// MaxLen approximately caps the length of the stream, 0 keeps every message.
+	MaxLen        int64
+	BatchSize     int
+	RetryInterval time.Duration
+}
+
+func NewRelay(outbox *Outbox, client *redis.Client, stream string) *Relay {
+	return &Relay{
+		Outbox:        outbox,
+		Client:        client,
+		Stream:        stream,
+		MaxLen:        defaultMaxLen,
+		BatchSize:     defaultBatchSize,
+		RetryInterval: defaultRetryInterval,
+	}
+}
+
+// Run relays the outbox until ctx is done, failed publishes are retried after RetryInterval.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		err := r.Flush(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		wait := r.Outbox.Notify()
+		if err != nil {
+			log.Printf("error relaying outbox to %s, %d events pending: %v", r.Stream, r.Outbox.Len(), err)
+			wait = nil
+		} else if r.Outbox.Len() > 0 {
+			continue
+		}
+
+		timer := time.NewTimer(r.RetryInterval)
+		select {
+		case <-wait:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+		timer.Stop()
+	}
+}
+
+// Flush publishes one batch of pending entries in order and acknowledges the published ones.
+func (r *Relay) Flush(ctx context.Context) error {
+	for _, entry := range r.Outbox.Pending(r.BatchSize) {
+		if err := r.publish(ctx, entry); err != nil {
+			return err
+		}
+		r.Outbox.Ack(entry)
+	}
+	return nil
+}
+
+// publish adds a message for every name of the event, e.g. employee.updated and employee.position_changed.
+func (r *Relay) publish(ctx context.Context, entry Entry) error {
+	event := entry.Event
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error to marshal event %s: %w", entry.ID, err)
+	}
+
+	for _, name := range event.Names() {
+		err := r.Client.XAdd(ctx, &redis.XAddArgs{
+			Stream: r.Stream,
+			MaxLen: r.MaxLen,
+			Approx: r.MaxLen > 0,
+			Values: map[string]any{
+				"event_id":  entry.ID,
+				"event":     name,
+				"entity":    string(event.Entity),
+				"entity_id": event.EntityID,
+				"time":      event.Time.UTC().Format(time.RFC3339Nano),
+				"data":      data,
+			},
+		}).Err()
+		if err != nil {
+			return fmt.Errorf("error to add event %s to stream: %w", entry.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/outbox"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	"github.com/redis/go-redis/v9"
+)
+
+const stream = "employees-api:events"
+
+func setup(t *testing.T) (*outbox.Outbox, *repository.UnitOfWork, *miniredis.Miniredis, *outbox.Relay) {
+	t.Helper()
+
+	ob := outbox.New()
+	store := repository.NewStore(ob)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	relay := outbox.NewRelay(ob, client, stream)
+	relay.RetryInterval = 10 * time.Millisecond
+	return ob, uow, mr, relay
+}
+
+func TestOutbox_EnqueuesCommittedWrites(t *testing.T) {
+	ob, uow, _, _ := setup(t)
+	ctx := context.Background()
+
+	errRollback := errors.New("rollback")
+	err := uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		if err := tx.Positions().Create(ctx, &domain.Position{Name: "Engineer"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("expected rollback, got %v", err)
+	}
+	if ob.Len() != 0 {
+		t.Fatalf("expected no events of the rolled back transaction, got %d", ob.Len())
+	}
+
+	err = uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		return tx.Positions().Create(ctx, &domain.Position{Name: "Engineer"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending := ob.Pending(10)
+	if len(pending) != 1 || pending[0].Event.Type != domain.EventCreated || pending[0].Event.Time.IsZero() {
+		t.Fatalf("expected the created event, got %+v", pending)
+	}
+}
+
+func TestRelay_Flush(t *testing.T) {
+	ob, uow, mr, relay := setup(t)
+	ctx := context.Background()
+
+	var engineer, manager domain.Position
+	var ann domain.Employee
+	err := uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		engineer, manager = domain.Position{Name: "Engineer"}, domain.Position{Name: "Manager"}
+		if err := tx.Positions().Create(ctx, &engineer); err != nil {
+			return err
+		}
+		if err := tx.Positions().Create(ctx, &manager); err != nil {
+			return err
+		}
+		ann = domain.Employee{FirstName: "Ann", LastName: "Lee", PositionID: engineer.ID}
+		return tx.Employees().Create(ctx, &ann)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ann.PositionID = manager.ID
+	if err := uow.Employees().Update(ctx, ann); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := relay.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ob.Len() != 0 {
+		t.Errorf("expected empty outbox, got %d entries", ob.Len())
+	}
+
+	messages, err := mr.Stream(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"position.created", "position.created", "employee.created", "employee.updated", domain.EventEmployeePositionChanged}
+	if len(messages) != len(want) {
+		t.Fatalf("expected %d messages, got %d", len(want), len(messages))
+	}
+	eventIDs := make([]string, len(messages))
+	for i, msg := range messages {
+		fields := make(map[string]string)
+		for j := 0; j+1 < len(msg.Values); j += 2 {
+			fields[msg.Values[j]] = msg.Values[j+1]
+		}
+		eventIDs[i] = fields["event_id"]
+		if fields["event"] != want[i] {
+			t.Errorf("message %d: expected event %s, got %s", i, want[i], fields["event"])
+		}
+		if fields["event_id"] == "" {
+			t.Errorf("message %d: expected event_id", i)
+		}
+
+		var event domain.Event
+		if err := json.Unmarshal([]byte(fields["data"]), &event); err != nil || event.EntityID != fields["entity_id"] {
+			t.Errorf("message %d: unexpected data %s: %v", i, fields["data"], err)
+		}
+	}
+
+	// both messages of an update carry the ID of the same event
+	if eventIDs[3] != eventIDs[4] {
+		t.Errorf("expected the same event_id, got %s and %s", eventIDs[3], eventIDs[4])
+	}
+}
+
+func TestRelay_RetriesUntilPublished(t *testing.T) {
+	ob, uow, mr, relay := setup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mr.SetError("LOADING redis is loading the dataset")
+
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	if err := uow.Positions().Create(ctx, &domain.Position{Name: "Engineer"}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if ob.Len() != 1 {
+		t.Fatalf("expected the event to stay in the outbox while redis fails, got %d entries", ob.Len())
+	}
+
+	mr.SetError("")
+	deadline := time.Now().Add(2 * time.Second)
+	for ob.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if messages, _ := mr.Stream(stream); ob.Len() != 0 || len(messages) != 1 {
+		t.Errorf("expected the event to be relayed, got %d pending and %d messages", ob.Len(), len(messages))
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected nil after cancel, got %v", err)
+	}
+}
+
+func TestRelay_ScheduledChanges(t *testing.T) {
+	ob, uow, mr, relay := setup(t)
+	ctx := context.Background()
+
+	engineer := domain.Position{Name: "Engineer", Salary: domain.Money{Amount: 15000, Currency: "USD"}}
+	manager := domain.Position{Name: "Manager", Salary: domain.Money{Amount: 20000, Currency: "USD"}}
+	ann := domain.Employee{FirstName: "Ann", LastName: "Lee"}
+	err := uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		if err := tx.Positions().Create(ctx, &engineer); err != nil {
+			return err
+		}
+		if err := tx.Positions().Create(ctx, &manager); err != nil {
+			return err
+		}
+		ann.PositionID = engineer.ID
+		return tx.Employees().Create(ctx, &ann)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	effective := time.Now().Add(50 * time.Millisecond)
+	if err := uow.Employees().SchedulePositionChange(ctx, domain.PositionAssignment{EmployeeID: ann.ID, PositionID: manager.ID, EffectiveFrom: effective}); err != nil {
+		t.Fatal(err)
+	}
+	salary := domain.Money{Amount: 17000, Currency: "USD"}
+	if err := uow.Positions().ScheduleSalaryChange(ctx, domain.SalaryChange{PositionID: engineer.ID, Salary: salary, EffectiveFrom: effective}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a change scheduled by a rolled back transaction never takes effect
+	errRollback := errors.New("rollback")
+	err = uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		change := domain.SalaryChange{PositionID: manager.ID, Salary: salary, EffectiveFrom: effective}
+		if err := tx.Positions().ScheduleSalaryChange(ctx, change); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("expected rollback, got %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for ob.Len() < 5 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := relay.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := mr.Stream(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// three creates and the two messages of each update
+	if len(messages) != 7 {
+		t.Fatalf("expected 7 messages, got %d", len(messages))
+	}
+
+	scheduled := make(map[string]domain.Event)
+	for _, msg := range messages[3:] {
+		fields := make(map[string]string)
+		for j := 0; j+1 < len(msg.Values); j += 2 {
+			fields[msg.Values[j]] = msg.Values[j+1]
+		}
+		var event domain.Event
+		if err := json.Unmarshal([]byte(fields["data"]), &event); err != nil {
+			t.Fatal(err)
+		}
+		scheduled[fields["event"]] = event
+	}
+
+	if event, ok := scheduled[domain.EventEmployeePositionChanged]; !ok || event.PreviousEmployee.PositionID != engineer.ID || event.Employee.PositionID != manager.ID {
+		t.Errorf("expected the position change of the employee, got %+v", event)
+	}
+	if event, ok := scheduled[domain.EventPositionSalaryChanged]; !ok || event.EntityID != engineer.ID || event.PreviousPosition.Salary != engineer.Salary || event.Position.Salary != salary {
+		t.Errorf("expected the salary change of the engineer, got %+v", event)
+	}
+}
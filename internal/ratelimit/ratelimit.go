@@ -0,0 +1,226 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Limit is a token bucket refilled with Requests tokens every Period and holding up to Burst tokens.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	// Burst is the number of requests allowed at once, it defaults to Requests.
+	Burst int
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// perMilli returns the refill rate in tokens per millisecond.
+func (l Limit) perMilli() float64 {
+	return float64(l.Requests) / float64(l.Period.Milliseconds())
+}
+
+// Result is the outcome of taking a token, the durations are rounded up to whole seconds for headers.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, it is zero if the request was allowed.
+	RetryAfter time.Duration
+}
+
+func newResult(limit Limit, allowed bool, tokens float64) Result {
+	rate := limit.perMilli()
+	result := Result{
+		Allowed:   allowed,
+		Limit:     int(limit.capacity()),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration(math.Ceil((limit.capacity()-tokens)/rate)) * time.Millisecond,
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1-tokens)/rate)) * time.Millisecond
+	}
+	return result
+}
+
+// Limiter takes a token from the bucket of a key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Policy is the default limit and the overrides of routes, keyed by their pattern, e.g. "GET /employees".
+type Policy struct {
+	Default Limit
+	Routes  map[string]Limit
+	// Tenants replace Default for the clients of a tenant, keyed by tenant ID. Route overrides still apply.
+	Tenants map[string]Limit
+}
+
+// For returns the limit of the route for the clients of the tenant.
+func (p Policy) For(route, tenantID string) Limit {
+	if limit, ok := p.Routes[route]; ok {
+		return limit
+	}
+	if limit, ok := p.Tenants[tenantID]; ok {
+		return limit
+	}
+	return p.Default
+}
+
+// ParseLimits parses overrides of requests per minute keyed by route or tenant, like
+// "GET /employees=120:20;POST /employees:batch=10", the number after the colon following the rate is the burst.
+func ParseLimits(value string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, part := range strings.Split(value, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		key, spec, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q, expected key=requests[:burst]", ErrInvalidLimit, part)
+		}
+		rate, burst, _ := strings.Cut(spec, ":")
+
+		limit := Limit{Period: time.Minute}
+		var err error
+		if limit.Requests, err = strconv.Atoi(rate); err != nil || limit.Requests <= 0 {
+			return nil, fmt.Errorf("%w: %q, requests must be a positive number", ErrInvalidLimit, part)
+		}
+		if burst != "" {
+			if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst <= 0 {
+				return nil, fmt.Errorf("%w: %q, burst must be a positive number", ErrInvalidLimit, part)
+			}
+		}
+		limits[strings.TrimSpace(key)] = limit
+	}
+	return limits, nil
+}
+
+// MemoryLimiter keeps the buckets in memory, limits apply per instance.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	calls   int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time
+}
+
+// sweepEvery is the number of calls between removals of buckets that are full again.
+const sweepEvery = 1000
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (m *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.capacity(), last: now}
+		m.buckets[key] = b
+	}
+
+	elapsed := float64(now.Sub(b.last).Milliseconds())
+	b.tokens = math.Min(limit.capacity(), b.tokens+max(0, elapsed)*limit.perMilli())
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	result := newResult(limit, allowed, b.tokens)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+func (m *MemoryLimiter) sweep(now time.Time) {
+	if m.calls++; m.calls < sweepEvery {
+		return
+	}
+	m.calls = 0
+
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// tokenBucket refills and takes a token atomically, buckets expire once they are full again.
+var tokenBucket = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisLimiter keeps the buckets in Redis, limits apply across all instances.
+type RedisLimiter struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{client: client, now: time.Now}
+}
+
+func (r *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	values, err := tokenBucket.Run(ctx, r.client, []string{key}, limit.perMilli(), limit.capacity(), r.now().UnixMilli()).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("error to take rate limit token: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("error to take rate limit token: unexpected result %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(remaining, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("error to take rate limit token: %w", err)
+	}
+	return newResult(limit, allowed == 1, tokens), nil
+}
@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+
+	memory := NewMemoryLimiter()
+	memory.now = clock
+	distributed := NewRedisLimiter(client)
+	distributed.now = clock
+
+	// 60 requests per minute with a burst of 2 refill a token every second
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 2}
+
+	for name, limiter := range map[string]Limiter{"memory": memory, "redis": distributed} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now = time.Unix(1700000000, 0)
+
+			steps := []struct {
+				advance time.Duration
+				want    Result
+			}{
+				{want: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+				{want: Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+				{want: Result{Allowed: false, Limit: 2, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second}},
+				{advance: 500 * time.Millisecond, want: Result{Allowed: false, Limit: 2, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+				{advance: 500 * time.Millisecond, want: Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+				{advance: time.Hour, want: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+			}
+
+			for i, step := range steps {
+				now = now.Add(step.advance)
+				got, err := limiter.Allow(ctx, "client", limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != step.want {
+					t.Errorf("step %d: expected %+v, got %+v", i, step.want, got)
+				}
+			}
+
+			if got, _ := limiter.Allow(ctx, "other client", limit); !got.Allowed || got.Remaining != 1 {
+				t.Errorf("expected a separate bucket for another key, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    map[string]Limit
+		wantErr bool
+	}{
+		"empty": {
+			value: "",
+			want:  map[string]Limit{},
+		},
+		"routes": {
+			value: "GET /employees=120:20; POST /employees:batch=10",
+			want: map[string]Limit{
+				"GET /employees":        {Requests: 120, Period: time.Minute, Burst: 20},
+				"POST /employees:batch": {Requests: 10, Period: time.Minute},
+			},
+		},
+		"tenants": {
+			value: "acme=6000:500;globex=60",
+			want: map[string]Limit{
+				"acme":   {Requests: 6000, Period: time.Minute, Burst: 500},
+				"globex": {Requests: 60, Period: time.Minute},
+			},
+		},
+		"missing rate":   {value: "GET /employees", wantErr: true},
+		"invalid rate":   {value: "GET /employees=0", wantErr: true},
+		"invalid burst":  {value: "GET /employees=10:x", wantErr: true},
+		"negative burst": {value: "GET /employees=10:-1", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseLimits(tc.value)
+			if tc.wantErr != errors.Is(err, ErrInvalidLimit) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPolicy_For(t *testing.T) {
+	policy := Policy{
+		Default: Limit{Requests: 600, Period: time.Minute},
+		Routes:  map[string]Limit{"GET /employees": {Requests: 60, Period: time.Minute}},
+		Tenants: map[string]Limit{"acme": {Requests: 6000, Period: time.Minute}},
+	}
+
+	tests := map[string]struct {
+		route    string
+		tenant   string
+		expected int
+	}{
+		"route override":             {route: "GET /employees", expected: 60},
+		"route override of a tenant": {route: "GET /employees", tenant: "acme", expected: 60},
+		"tenant override":            {route: "GET /positions", tenant: "acme", expected: 6000},
+		"default":                    {route: "GET /positions", tenant: "globex", expected: 600},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := policy.For(tc.route, tc.tenant); got.Requests != tc.expected {
+				t.Errorf("expected %d requests, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
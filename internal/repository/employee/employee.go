@@ -2,92 +2,519 @@ package employee
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"sync"
+	"sort"
+	"time"
 
 	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/search"
+	"github.com/dilyara4949/employees-api/internal/tenant"
 
 	"github.com/google/uuid"
 )
 
+// positionMatchWeight lowers the score of employees found by their position name compared to their own name.
+const positionMatchWeight = 0.7
+
 type PositionsRepository interface {
 	Get(ctx context.Context, id string) (*domain.Position, error)
+	SearchName(ctx context.Context, token string) (domain.SearchHits, error)
 }
 
 type employeeRepository struct {
-	mu            sync.RWMutex
+	store         *repository.Store
 	storage       map[string]domain.Employee
+	history       map[string][]domain.PositionAssignment
+	index         *search.Index
+	byPosition    map[string]map[string]struct{}
 	positionsRepo PositionsRepository
 }
 
-func NewEmployeesRepository(positionsRepo PositionsRepository) domain.EmployeesRepository {
-	return &employeeRepository{
+// NewEmployeesRepository creates a repository sharing the store with positionsRepo.
+func NewEmployeesRepository(store *repository.Store, positionsRepo PositionsRepository) domain.EmployeesRepository {
+	e := &employeeRepository{
+		store:         store,
 		storage:       make(map[string]domain.Employee),
+		history:       make(map[string][]domain.PositionAssignment),
+		index:         search.NewIndex(),
+		byPosition:    make(map[string]map[string]struct{}),
 		positionsRepo: positionsRepo,
 	}
+	return e
 }
 
+// Create checks the position and inserts the employee under the same lock, so the position can't be deleted in between.
+// The employee belongs to the tenant of ctx.
 func (e *employeeRepository) Create(ctx context.Context, employee *domain.Employee) error {
-	if _, err := e.positionsRepo.Get(ctx, employee.PositionID); err != nil {
+	ctx, unlock := e.store.Lock(ctx)
+	defer unlock()
+
+	position, err := e.positionsRepo.Get(ctx, employee.PositionID)
+	if err != nil {
+		return fmt.Errorf("error to create employee: %w", err)
+	}
+
+	if err := position.CheckSalary(*employee); err != nil {
 		return fmt.Errorf("error to create employee: %w", err)
 	}
 
 	employee.ID = uuid.New().String()
+	employee.CompaRatio = 0
+	employee.TenantID = tenant.From(ctx)
 
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	e.remember(ctx, employee.ID)
 	e.storage[employee.ID] = *employee
+	e.index.Put(employee.ID, employee.FirstName, employee.LastName)
+	e.addAssignment(domain.PositionAssignment{EmployeeID: employee.ID, PositionID: employee.PositionID, EffectiveFrom: time.Now()})
+	*employee = withCompaRatio(*employee, position)
+	e.publish(ctx, domain.EventCreated, *employee, nil)
 
 	return nil
 }
 
-func (e *employeeRepository) Get(_ context.Context, id string) (*domain.Employee, error) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-
-	if employee, ok := e.storage[id]; ok {
-		return &employee, nil
-	}
-	return nil, errors.New("employee not found")
+func (e *employeeRepository) Get(ctx context.Context, id string) (*domain.Employee, error) {
+	return e.GetAsOf(ctx, id, time.Now())
 }
 
 func (e *employeeRepository) Update(ctx context.Context, employee domain.Employee) error {
-	if _, err := e.positionsRepo.Get(ctx, employee.PositionID); err != nil {
+	ctx, unlock := e.store.Lock(ctx)
+	defer unlock()
+
+	position, err := e.positionsRepo.Get(ctx, employee.PositionID)
+	if err != nil {
 		return fmt.Errorf("error to update employee: %w", err)
 	}
 
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	if err := position.CheckSalary(employee); err != nil {
+		return fmt.Errorf("error to update employee: %w", err)
+	}
 
-	if _, ok := e.storage[employee.ID]; !ok {
-		return errors.New("employee not found")
+	employee.CompaRatio = 0
+
+	previous, ok := e.lookup(ctx, employee.ID)
+	if !ok {
+		return domain.ErrEmployeeNotFound
+	}
+	employee.TenantID = previous.TenantID
+	e.remember(ctx, employee.ID)
+
+	// the stored position is stale once a scheduled change took effect, the history has the current one
+	now := time.Now()
+	assignment, ok := e.assignmentAt(employee.ID, now)
+	if ok {
+		previous.PositionID = assignment.PositionID
+	}
+	if !ok || assignment.PositionID != employee.PositionID {
+		e.addAssignment(domain.PositionAssignment{EmployeeID: employee.ID, PositionID: employee.PositionID, EffectiveFrom: now})
 	}
 
 	e.storage[employee.ID] = employee
+	e.index.Put(employee.ID, employee.FirstName, employee.LastName)
+	e.publish(ctx, domain.EventUpdated, withCompaRatio(employee, position), &previous)
 	return nil
 }
 
-func (e *employeeRepository) Delete(_ context.Context, id string) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+func (e *employeeRepository) Delete(ctx context.Context, id string) error {
+	ctx, unlock := e.store.Lock(ctx)
+	defer unlock()
 
-	if _, ok := e.storage[id]; !ok {
-		return errors.New("employee not found")
+	employee, ok := e.lookup(ctx, id)
+	if !ok {
+		return domain.ErrEmployeeNotFound
+	}
+	e.remember(ctx, id)
+	if assignment, ok := e.assignmentAt(id, time.Now()); ok {
+		employee.PositionID = assignment.PositionID
+	}
+
+	for _, assignment := range e.history[id] {
+		delete(e.byPosition[assignment.PositionID], id)
 	}
 
 	delete(e.storage, id)
+	delete(e.history, id)
+	e.index.Remove(id)
+	e.publish(ctx, domain.EventDeleted, employee, nil)
 	return nil
 }
 
-func (e *employeeRepository) GetAll(_ context.Context) ([]domain.Employee, error) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
+func (e *employeeRepository) GetAll(ctx context.Context) ([]domain.Employee, error) {
+	ctx, unlock := e.store.RLock(ctx)
+	defer unlock()
 
+	now := time.Now()
 	employees := make([]domain.Employee, 0)
 
-	for _, employee := range e.storage {
+	for id, employee := range e.storage {
+		if employee.TenantID != tenant.From(ctx) {
+			continue
+		}
+		if assignment, ok := e.assignmentAt(id, now); ok {
+			employee.PositionID = assignment.PositionID
+		}
+		if position, err := e.positionsRepo.Get(ctx, employee.PositionID); err == nil {
+			employee = withCompaRatio(employee, position)
+		}
 		employees = append(employees, employee)
 	}
 	return employees, nil
 }
+
+func (e *employeeRepository) GetAsOf(ctx context.Context, id string, date time.Time) (*domain.Employee, error) {
+	ctx, unlock := e.store.RLock(ctx)
+	defer unlock()
+
+	employee, ok := e.lookup(ctx, id)
+	if !ok {
+		return nil, domain.ErrEmployeeNotFound
+	}
+
+	assignment, ok := e.assignmentAt(id, date)
+	if !ok {
+		return nil, domain.ErrEmployeeNotFoundAsOf
+	}
+
+	employee.PositionID = assignment.PositionID
+	if position, err := e.positionsRepo.Get(ctx, employee.PositionID); err == nil {
+		employee = withCompaRatio(employee, position)
+	}
+	return &employee, nil
+}
+
+func (e *employeeRepository) PositionHistory(ctx context.Context, id string) ([]domain.PositionAssignment, error) {
+	_, unlock := e.store.RLock(ctx)
+	defer unlock()
+
+	if _, ok := e.lookup(ctx, id); !ok {
+		return nil, domain.ErrEmployeeNotFound
+	}
+
+	history := make([]domain.PositionAssignment, len(e.history[id]))
+	copy(history, e.history[id])
+	return history, nil
+}
+
+func (e *employeeRepository) SchedulePositionChange(ctx context.Context, assignment domain.PositionAssignment) error {
+	if !assignment.EffectiveFrom.After(time.Now()) {
+		return domain.ErrEffectiveDateNotInFuture
+	}
+
+	ctx, unlock := e.store.Lock(ctx)
+	defer unlock()
+
+	position, err := e.positionsRepo.Get(ctx, assignment.PositionID)
+	if err != nil {
+		return fmt.Errorf("error to schedule position change: %w", err)
+	}
+
+	employee, ok := e.lookup(ctx, assignment.EmployeeID)
+	if !ok {
+		return domain.ErrEmployeeNotFound
+	}
+
+	if err := position.CheckSalary(employee); err != nil {
+		return fmt.Errorf("error to schedule position change: %w", err)
+	}
+
+	e.remember(ctx, assignment.EmployeeID)
+	e.addAssignment(assignment)
+	e.store.At(ctx, assignment.EffectiveFrom, func(locked context.Context) {
+		e.publishAssignment(tenant.With(locked, tenant.From(ctx)), assignment)
+	})
+	return nil
+}
+
+// publishAssignment publishes the update of the employee when the scheduled assignment takes effect, unless the
+// employee was deleted, the assignment replaced or the position is the same as before.
+func (e *employeeRepository) publishAssignment(ctx context.Context, assignment domain.PositionAssignment) {
+	employee, ok := e.lookup(ctx, assignment.EmployeeID)
+	if !ok {
+		return
+	}
+	current, ok := e.assignmentAt(assignment.EmployeeID, assignment.EffectiveFrom)
+	if !ok || current != assignment {
+		return
+	}
+	before, ok := e.assignmentAt(assignment.EmployeeID, assignment.EffectiveFrom.Add(-time.Nanosecond))
+	if !ok || before.PositionID == assignment.PositionID {
+		return
+	}
+
+	previous := employee
+	previous.PositionID = before.PositionID
+	employee.PositionID = assignment.PositionID
+	if position, err := e.positionsRepo.Get(ctx, employee.PositionID); err == nil {
+		employee = withCompaRatio(employee, position)
+	}
+	e.publish(ctx, domain.EventUpdated, employee, &previous)
+}
+
+// Search matches every token of the query against the employee names and their current position name,
+// an employee has to match all tokens and its score is the sum of the best match of each token.
+func (e *employeeRepository) Search(ctx context.Context, query string, limit int) ([]domain.EmployeeSearchResult, error) {
+	tokens := search.Tokenize(query)
+	results := make([]domain.EmployeeSearchResult, 0)
+	if len(tokens) == 0 {
+		return results, nil
+	}
+
+	ctx, unlock := e.store.RLock(ctx)
+	defer unlock()
+
+	now := time.Now()
+	var scores map[string]float64
+
+	for i, token := range tokens {
+		tokenScores := e.index.Match(token)
+
+		positions, err := e.positionsRepo.SearchName(ctx, token)
+		if err != nil {
+			return nil, fmt.Errorf("error to search positions: %w", err)
+		}
+
+		for positionID, score := range positions {
+			score *= positionMatchWeight
+			for id := range e.byPosition[positionID] {
+				if assignment, ok := e.assignmentAt(id, now); ok && assignment.PositionID == positionID && tokenScores[id] < score {
+					tokenScores[id] = score
+				}
+			}
+		}
+
+		if i == 0 {
+			scores = tokenScores
+			continue
+		}
+		for id, score := range scores {
+			if tokenScore, ok := tokenScores[id]; ok {
+				scores[id] = score + tokenScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	for id, score := range scores {
+		employee, ok := e.lookup(ctx, id)
+		if !ok {
+			continue
+		}
+		if assignment, ok := e.assignmentAt(id, now); ok {
+			employee.PositionID = assignment.PositionID
+		}
+		if position, err := e.positionsRepo.Get(ctx, employee.PositionID); err == nil {
+			employee = withCompaRatio(employee, position)
+		}
+		results = append(results, domain.EmployeeSearchResult{Employee: employee, Score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].LastName != results[j].LastName {
+			return results[i].LastName < results[j].LastName
+		}
+		if results[i].FirstName != results[j].FirstName {
+			return results[i].FirstName < results[j].FirstName
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if limit <= 0 {
+		limit = domain.DefaultSearchLimit
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (e *employeeRepository) ListByPosition(ctx context.Context, positionID string) ([]domain.Employee, error) {
+	ctx, unlock := e.store.RLock(ctx)
+	defer unlock()
+
+	now := time.Now()
+	employees := make([]domain.Employee, 0)
+
+	for id := range e.byPosition[positionID] {
+		assignment, ok := e.assignmentAt(id, now)
+		if !ok || assignment.PositionID != positionID {
+			continue
+		}
+
+		employee, ok := e.lookup(ctx, id)
+		if !ok {
+			continue
+		}
+		employee.PositionID = positionID
+		if position, err := e.positionsRepo.Get(ctx, positionID); err == nil {
+			employee = withCompaRatio(employee, position)
+		}
+		employees = append(employees, employee)
+	}
+
+	sort.Slice(employees, func(i, j int) bool {
+		return employees[i].ID < employees[j].ID
+	})
+	return employees, nil
+}
+
+func (e *employeeRepository) ListScheduledForPosition(ctx context.Context, positionID string) ([]domain.PositionAssignment, error) {
+	ctx, unlock := e.store.RLock(ctx)
+	defer unlock()
+
+	now := time.Now()
+	scheduled := make([]domain.PositionAssignment, 0)
+
+	for id := range e.byPosition[positionID] {
+		if _, ok := e.lookup(ctx, id); !ok {
+			continue
+		}
+		for _, assignment := range e.history[id] {
+			if assignment.PositionID == positionID && assignment.EffectiveFrom.After(now) {
+				scheduled = append(scheduled, assignment)
+			}
+		}
+	}
+
+	sort.Slice(scheduled, func(i, j int) bool {
+		if scheduled[i].EmployeeID != scheduled[j].EmployeeID {
+			return scheduled[i].EmployeeID < scheduled[j].EmployeeID
+		}
+		return scheduled[i].EffectiveFrom.Before(scheduled[j].EffectiveFrom)
+	})
+	return scheduled, nil
+}
+
+// Iterate calls fn for the employees of the tenant ordered by ID, the lock isn't held while fn runs
+// so employees deleted in the meantime are skipped.
+func (e *employeeRepository) Iterate(ctx context.Context, fn func(domain.Employee) error) error {
+	_, unlock := e.store.RLock(ctx)
+	ids := make([]string, 0, len(e.storage))
+	for id, employee := range e.storage {
+		if employee.TenantID == tenant.From(ctx) {
+			ids = append(ids, id)
+		}
+	}
+	unlock()
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		employee, err := e.Get(ctx, id)
+		if err != nil {
+			continue
+		}
+		if err := fn(*employee); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish publishes a change of employee, previous is the employee before an update.
+func (e *employeeRepository) publish(ctx context.Context, eventType domain.EventType, employee domain.Employee, previous *domain.Employee) {
+	e.store.Publish(ctx, domain.Event{
+		Type:             eventType,
+		Entity:           domain.EntityEmployee,
+		EntityID:         employee.ID,
+		Employee:         &employee,
+		PreviousEmployee: previous,
+		TenantID:         employee.TenantID,
+	})
+}
+
+// lookup returns the employee if it belongs to the tenant of ctx, the employees of other tenants are not found.
+func (e *employeeRepository) lookup(ctx context.Context, id string) (domain.Employee, bool) {
+	employee, ok := e.storage[id]
+	if !ok || employee.TenantID != tenant.From(ctx) {
+		return domain.Employee{}, false
+	}
+	return employee, true
+}
+
+// remember records the state of the employee before a write for the rollback of the transaction of ctx.
+// Restoring the history re-adds the employee to the positions it was assigned to, positions only assigned
+// within the transaction may keep it, search checks the current assignment anyway.
+func (e *employeeRepository) remember(ctx context.Context, id string) {
+	employee, existed := e.storage[id]
+	history := append([]domain.PositionAssignment(nil), e.history[id]...)
+
+	e.store.Undo(ctx, func() {
+		for _, assignment := range e.history[id] {
+			delete(e.byPosition[assignment.PositionID], id)
+		}
+
+		if !existed {
+			delete(e.storage, id)
+			delete(e.history, id)
+			e.index.Remove(id)
+			return
+		}
+
+		e.storage[id] = employee
+		e.history[id] = history
+		for _, assignment := range history {
+			e.indexPosition(assignment)
+		}
+		e.index.Put(id, employee.FirstName, employee.LastName)
+	})
+}
+
+// assignmentAt returns the position assignment in effect at the given date.
+func (e *employeeRepository) assignmentAt(id string, date time.Time) (domain.PositionAssignment, bool) {
+	history := e.history[id]
+
+	i := sort.Search(len(history), func(i int) bool {
+		return history[i].EffectiveFrom.After(date)
+	})
+	if i == 0 {
+		return domain.PositionAssignment{}, false
+	}
+	return history[i-1], true
+}
+
+// addAssignment inserts the assignment keeping the history ordered by effective date,
+// an assignment with the same effective date replaces the existing one.
+func (e *employeeRepository) addAssignment(assignment domain.PositionAssignment) {
+	history := e.history[assignment.EmployeeID]
+
+	i := sort.Search(len(history), func(i int) bool {
+		return !history[i].EffectiveFrom.Before(assignment.EffectiveFrom)
+	})
+	if i < len(history) && history[i].EffectiveFrom.Equal(assignment.EffectiveFrom) {
+		history[i] = assignment
+		e.indexPosition(assignment)
+		return
+	}
+
+	history = append(history, domain.PositionAssignment{})
+	copy(history[i+1:], history[i:])
+	history[i] = assignment
+	e.history[assignment.EmployeeID] = history
+	e.indexPosition(assignment)
+}
+
+// indexPosition keeps track of every employee ever assigned to a position, so search can find them by position name.
+func (e *employeeRepository) indexPosition(assignment domain.PositionAssignment) {
+	if _, ok := e.byPosition[assignment.PositionID]; !ok {
+		e.byPosition[assignment.PositionID] = make(map[string]struct{})
+	}
+	e.byPosition[assignment.PositionID][assignment.EmployeeID] = struct{}{}
+}
+
+func withCompaRatio(employee domain.Employee, position *domain.Position) domain.Employee {
+	if employee.Salary == nil || position.Band == nil {
+		return employee
+	}
+	if ratio, ok := position.Band.CompaRatio(*employee.Salary); ok {
+		employee.CompaRatio = ratio
+	}
+	return employee
+}
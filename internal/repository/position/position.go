@@ -2,75 +2,305 @@ package position
 
 import (
 	"context"
-	"errors"
-	"sync"
+	"sort"
+	"time"
 
 	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/search"
+	"github.com/dilyara4949/employees-api/internal/tenant"
 
 	"github.com/google/uuid"
 )
 
 type positionsRepository struct {
-	mu      sync.RWMutex
+	store   *repository.Store
 	storage map[string]domain.Position
+	history map[string][]domain.SalaryChange
+	index   *search.Index
 }
 
-func NewPositionsRepository() domain.PositionsRepository {
-	return &positionsRepository{storage: make(map[string]domain.Position)}
+func NewPositionsRepository(store *repository.Store) domain.PositionsRepository {
+	p := &positionsRepository{
+		store:   store,
+		storage: make(map[string]domain.Position),
+		history: make(map[string][]domain.SalaryChange),
+		index:   search.NewIndex(),
+	}
+	return p
 }
 
+// Create inserts the position, it belongs to the tenant of ctx.
 func (p *positionsRepository) Create(ctx context.Context, position *domain.Position) error {
 	position.ID = uuid.New().String()
+	position.TenantID = tenant.From(ctx)
 
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	_, unlock := p.store.Lock(ctx)
+	defer unlock()
 
+	p.remember(ctx, position.ID)
 	p.storage[position.ID] = *position
+	p.index.Put(position.ID, position.Name)
+	p.addSalaryChange(domain.SalaryChange{PositionID: position.ID, Salary: position.Salary, EffectiveFrom: time.Now()})
+	p.publish(ctx, domain.EventCreated, *position, nil)
 	return nil
 }
 
 func (p *positionsRepository) Get(ctx context.Context, id string) (*domain.Position, error) {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
-	if position, ok := p.storage[id]; ok {
-		return &position, nil
-	}
-	return nil, errors.New("position not found")
+	return p.GetAsOf(ctx, id, time.Now())
 }
 
 func (p *positionsRepository) Update(ctx context.Context, position domain.Position) error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	_, unlock := p.store.Lock(ctx)
+	defer unlock()
+
+	previous, ok := p.lookup(ctx, position.ID)
+	if !ok {
+		return domain.ErrPositionNotFound
+	}
+	position.TenantID = previous.TenantID
+	p.remember(ctx, position.ID)
 
-	if _, ok := p.storage[position.ID]; !ok {
-		return errors.New("position not found")
+	// the stored salary is stale once a scheduled change took effect, the history has the current one
+	now := time.Now()
+	change, ok := p.salaryAt(position.ID, now)
+	if ok {
+		previous.Salary = change.Salary
+	}
+	if !ok || change.Salary != position.Salary {
+		p.addSalaryChange(domain.SalaryChange{PositionID: position.ID, Salary: position.Salary, EffectiveFrom: now})
 	}
 
 	p.storage[position.ID] = position
+	p.index.Put(position.ID, position.Name)
+	p.publish(ctx, domain.EventUpdated, position, &previous)
 	return nil
 }
 
 func (p *positionsRepository) Delete(ctx context.Context, id string) error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	_, unlock := p.store.Lock(ctx)
+	defer unlock()
 
-	if _, ok := p.storage[id]; !ok {
-		return errors.New("position not found")
+	position, ok := p.lookup(ctx, id)
+	if !ok {
+		return domain.ErrPositionNotFound
+	}
+	p.remember(ctx, id)
+	if change, ok := p.salaryAt(id, time.Now()); ok {
+		position.Salary = change.Salary
 	}
 
 	delete(p.storage, id)
+	delete(p.history, id)
+	p.index.Remove(id)
+	p.publish(ctx, domain.EventDeleted, position, nil)
 	return nil
 }
 
 func (p *positionsRepository) GetAll(ctx context.Context) ([]domain.Position, error) {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+	_, unlock := p.store.RLock(ctx)
+	defer unlock()
 
+	now := time.Now()
 	positions := make([]domain.Position, 0)
 
-	for _, position := range p.storage {
+	for id, position := range p.storage {
+		if position.TenantID != tenant.From(ctx) {
+			continue
+		}
+		if change, ok := p.salaryAt(id, now); ok {
+			position.Salary = change.Salary
+		}
 		positions = append(positions, position)
 	}
 	return positions, nil
 }
+
+func (p *positionsRepository) GetAsOf(ctx context.Context, id string, date time.Time) (*domain.Position, error) {
+	_, unlock := p.store.RLock(ctx)
+	defer unlock()
+
+	position, ok := p.lookup(ctx, id)
+	if !ok {
+		return nil, domain.ErrPositionNotFound
+	}
+
+	change, ok := p.salaryAt(id, date)
+	if !ok {
+		return nil, domain.ErrPositionNotFoundAsOf
+	}
+
+	position.Salary = change.Salary
+	return &position, nil
+}
+
+func (p *positionsRepository) SalaryHistory(ctx context.Context, id string) ([]domain.SalaryChange, error) {
+	_, unlock := p.store.RLock(ctx)
+	defer unlock()
+
+	if _, ok := p.lookup(ctx, id); !ok {
+		return nil, domain.ErrPositionNotFound
+	}
+
+	history := make([]domain.SalaryChange, len(p.history[id]))
+	copy(history, p.history[id])
+	return history, nil
+}
+
+func (p *positionsRepository) ScheduleSalaryChange(ctx context.Context, change domain.SalaryChange) error {
+	if !change.EffectiveFrom.After(time.Now()) {
+		return domain.ErrEffectiveDateNotInFuture
+	}
+
+	_, unlock := p.store.Lock(ctx)
+	defer unlock()
+
+	if _, ok := p.lookup(ctx, change.PositionID); !ok {
+		return domain.ErrPositionNotFound
+	}
+
+	p.remember(ctx, change.PositionID)
+	p.addSalaryChange(change)
+	p.store.At(ctx, change.EffectiveFrom, func(locked context.Context) {
+		p.publishSalaryChange(tenant.With(locked, tenant.From(ctx)), change)
+	})
+	return nil
+}
+
+// publishSalaryChange publishes the update of the position when the scheduled salary change takes effect, unless
+// the position was deleted, the change replaced or the salary is the same as before.
+func (p *positionsRepository) publishSalaryChange(ctx context.Context, change domain.SalaryChange) {
+	position, ok := p.lookup(ctx, change.PositionID)
+	if !ok {
+		return
+	}
+	current, ok := p.salaryAt(change.PositionID, change.EffectiveFrom)
+	if !ok || current != change {
+		return
+	}
+	before, ok := p.salaryAt(change.PositionID, change.EffectiveFrom.Add(-time.Nanosecond))
+	if !ok || before.Salary == change.Salary {
+		return
+	}
+
+	previous := position
+	previous.Salary = before.Salary
+	position.Salary = change.Salary
+	p.publish(ctx, domain.EventUpdated, position, &previous)
+}
+
+func (p *positionsRepository) SearchName(ctx context.Context, token string) (domain.SearchHits, error) {
+	_, unlock := p.store.RLock(ctx)
+	defer unlock()
+
+	hits := p.index.Match(token)
+	for id := range hits {
+		if _, ok := p.lookup(ctx, id); !ok {
+			delete(hits, id)
+		}
+	}
+	return hits, nil
+}
+
+// Iterate calls fn for the positions of the tenant ordered by ID, the lock isn't held while fn runs
+// so positions deleted in the meantime are skipped.
+func (p *positionsRepository) Iterate(ctx context.Context, fn func(domain.Position) error) error {
+	_, unlock := p.store.RLock(ctx)
+	ids := make([]string, 0, len(p.storage))
+	for id, position := range p.storage {
+		if position.TenantID == tenant.From(ctx) {
+			ids = append(ids, id)
+		}
+	}
+	unlock()
+
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		position, err := p.Get(ctx, id)
+		if err != nil {
+			continue
+		}
+		if err := fn(*position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish publishes a change of position, previous is the position before an update.
+func (p *positionsRepository) publish(ctx context.Context, eventType domain.EventType, position domain.Position, previous *domain.Position) {
+	p.store.Publish(ctx, domain.Event{
+		Type:             eventType,
+		Entity:           domain.EntityPosition,
+		EntityID:         position.ID,
+		Position:         &position,
+		PreviousPosition: previous,
+		TenantID:         position.TenantID,
+	})
+}
+
+// lookup returns the position if it belongs to the tenant of ctx, the positions of other tenants are not found.
+func (p *positionsRepository) lookup(ctx context.Context, id string) (domain.Position, bool) {
+	position, ok := p.storage[id]
+	if !ok || position.TenantID != tenant.From(ctx) {
+		return domain.Position{}, false
+	}
+	return position, true
+}
+
+// remember records the state of the position before a write for the rollback of the transaction of ctx, the history
+// is copied as it is modified in place.
+func (p *positionsRepository) remember(ctx context.Context, id string) {
+	position, existed := p.storage[id]
+	history := append([]domain.SalaryChange(nil), p.history[id]...)
+
+	p.store.Undo(ctx, func() {
+		if !existed {
+			delete(p.storage, id)
+			delete(p.history, id)
+			p.index.Remove(id)
+			return
+		}
+
+		p.storage[id] = position
+		p.history[id] = history
+		p.index.Put(id, position.Name)
+	})
+}
+
+// salaryAt returns the salary change in effect at the given date.
+func (p *positionsRepository) salaryAt(id string, date time.Time) (domain.SalaryChange, bool) {
+	history := p.history[id]
+
+	i := sort.Search(len(history), func(i int) bool {
+		return history[i].EffectiveFrom.After(date)
+	})
+	if i == 0 {
+		return domain.SalaryChange{}, false
+	}
+	return history[i-1], true
+}
+
+// addSalaryChange inserts the change keeping the history ordered by effective date,
+// a change with the same effective date replaces the existing one.
+func (p *positionsRepository) addSalaryChange(change domain.SalaryChange) {
+	history := p.history[change.PositionID]
+
+	i := sort.Search(len(history), func(i int) bool {
+		return !history[i].EffectiveFrom.Before(change.EffectiveFrom)
+	})
+	if i < len(history) && history[i].EffectiveFrom.Equal(change.EffectiveFrom) {
+		history[i] = change
+		return
+	}
+
+	history = append(history, domain.SalaryChange{})
+	copy(history[i+1:], history[i:])
+	history[i] = change
+	p.history[change.PositionID] = history
+}
@@ -0,0 +1,43 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+func TestSearch_Limit(t *testing.T) {
+	uow, developer := newTestUnitOfWork(t)
+	ctx := context.Background()
+	employees := uow.Employees()
+
+	for i := 0; i < domain.DefaultSearchLimit+10; i++ {
+		if err := employees.Create(ctx, &domain.Employee{FirstName: "John", LastName: fmt.Sprintf("Smith %d", i), PositionID: developer.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		limit    int
+		expected int
+	}{
+		"limit":         {limit: 5, expected: 5},
+		"above matches": {limit: 100, expected: domain.DefaultSearchLimit + 10},
+		"default":       {limit: 0, expected: domain.DefaultSearchLimit},
+		"negative":      {limit: -1, expected: domain.DefaultSearchLimit},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := employees.Search(ctx, "john", tc.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != tc.expected {
+				t.Errorf("expected %d results, got %d", tc.expected, len(results))
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+)
+
+type lockMode int
+
+const (
+	readLocked lockMode = iota + 1
+	writeLocked
+)
+
+// heldKey marks a context whose caller holds the lock of the store.
+type heldKey struct {
+	store *Store
+}
+
+// txKey holds the transaction of the context.
+type txKey struct {
+	store *Store
+}
+
+// transaction holds the events published within a transaction until it commits, and the undo log of its writes.
+type transaction struct {
+	pending []domain.Event
+	undo    []func()
+}
+
+// rollback undoes the writes, in reverse order, and drops the events added since the undo log and the pending
+// events had the given lengths.
+func (tx *transaction) rollback(undone, pending int) {
+	for i := len(tx.undo) - 1; i >= undone; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = tx.undo[:undone]
+	tx.pending = tx.pending[:pending]
+}
+
+// Store is the lock shared by the in-memory repositories, so operations reading one repository
+// and writing the other one, and transactions spanning both of them, are atomic.
+type Store struct {
+	mu         sync.RWMutex
+	publishers []domain.EventPublisher
+}
+
+// NewStore creates a store publishing the changes of its repositories to publishers, e.g. the event bus and the outbox.
+func NewStore(publishers ...domain.EventPublisher) *Store {
+	return &Store{publishers: publishers}
+}
+
+// Lock acquires the write lock unless ctx already holds it. The returned context is marked as holding the lock
+// and has to be passed to nested repository calls, it must not be used after unlock.
+func (s *Store) Lock(ctx context.Context) (context.Context, func()) {
+	switch ctx.Value(heldKey{s}) {
+	case writeLocked:
+		return ctx, func() {}
+	case readLocked:
+		panic("repository: write lock requested while holding the read lock")
+	}
+
+	s.mu.Lock()
+	return context.WithValue(ctx, heldKey{s}, writeLocked), s.mu.Unlock
+}
+
+// RLock acquires the read lock unless ctx already holds a lock, see Lock.
+func (s *Store) RLock(ctx context.Context) (context.Context, func()) {
+	if ctx.Value(heldKey{s}) != nil {
+		return ctx, func() {}
+	}
+
+	s.mu.RLock()
+	return context.WithValue(ctx, heldKey{s}, readLocked), s.mu.RUnlock
+}
+
+// Publish publishes the event of a write, within a transaction it is held back until the transaction commits.
+// Repositories publish with the lock held so events are published in the order of the writes,
+// and publishers like the outbox receive exactly the events of committed writes.
+func (s *Store) Publish(ctx context.Context, event domain.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if tx, ok := ctx.Value(txKey{s}).(*transaction); ok {
+		tx.pending = append(tx.pending, event)
+		return
+	}
+	for _, publisher := range s.publishers {
+		if publisher != nil {
+			publisher.Publish(event)
+		}
+	}
+}
+
+// Undo records the inverse of a write made within the transaction of ctx, the writes of a transaction that
+// doesn't commit are undone in reverse order. Repositories record the state of the entity they are about to change,
+// with the write lock held. Writes outside of transactions aren't recorded.
+func (s *Store) Undo(ctx context.Context, undo func()) {
+	if tx, ok := ctx.Value(txKey{s}).(*transaction); ok {
+		tx.undo = append(tx.undo, undo)
+	}
+}
+
+// At runs fn with the write lock held once the time has come, e.g. to publish a scheduled change when it takes effect.
+// fn is passed a new context holding the lock, as ctx may be done by then. Within a transaction the run is cancelled
+// if the transaction doesn't commit, fn has to check that the change still exists as it may already be waiting for the lock.
+func (s *Store) At(ctx context.Context, at time.Time, fn func(ctx context.Context)) {
+	timer := time.AfterFunc(time.Until(at), func() {
+		ctx, unlock := s.Lock(context.Background())
+		defer unlock()
+		fn(ctx)
+	})
+	s.Undo(ctx, func() { timer.Stop() })
+}
+
+// UnitOfWork runs transactions over the in-memory repositories of a store. Transactions hold the write lock
+// of the store until they finish and are rolled back by undoing their writes, see Store.Undo.
+type UnitOfWork struct {
+	store     *Store
+	employees domain.EmployeesRepository
+	positions domain.PositionsRepository
+}
+
+func NewUnitOfWork(store *Store, employees domain.EmployeesRepository, positions domain.PositionsRepository) *UnitOfWork {
+	return &UnitOfWork{store: store, employees: employees, positions: positions}
+}
+
+// Do runs fn in a transaction. Called within a transaction, Do joins it: the writes of fn are undone and its events
+// dropped if fn fails, otherwise they are committed or rolled back with the outer transaction.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, tx domain.Tx) error) error {
+	parent, unlock := u.store.Lock(ctx)
+	defer unlock()
+
+	if outer, ok := parent.Value(txKey{u.store}).(*transaction); ok {
+		undone, pending := len(outer.undo), len(outer.pending)
+		if err := fn(parent, u); err != nil {
+			outer.rollback(undone, pending)
+			return err
+		}
+		return nil
+	}
+
+	tx := &transaction{}
+	ctx = context.WithValue(parent, txKey{u.store}, tx)
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.rollback(0, 0)
+		}
+	}()
+
+	if err := fn(ctx, u); err != nil {
+		return err
+	}
+
+	committed = true
+	for _, event := range tx.pending {
+		u.store.Publish(parent, event)
+	}
+	return nil
+}
+
+func (u *UnitOfWork) Employees() domain.EmployeesRepository {
+	return u.employees
+}
+
+func (u *UnitOfWork) Positions() domain.PositionsRepository {
+	return u.positions
+}
@@ -0,0 +1,528 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+)
+
+func newTestUnitOfWork(t *testing.T) (*repository.UnitOfWork, *domain.Position) {
+	t.Helper()
+
+	store := repository.NewStore(nil)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+
+	developer := &domain.Position{Name: "Developer", Salary: domain.Money{Amount: 100000, Currency: "USD"}}
+	if err := positions.Create(context.Background(), developer); err != nil {
+		t.Fatal(err)
+	}
+	return repository.NewUnitOfWork(store, employees, positions), developer
+}
+
+func TestUnitOfWork_Rollback(t *testing.T) {
+	uow, developer := newTestUnitOfWork(t)
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	err := uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		if err := tx.Employees().Create(ctx, &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: developer.ID}); err != nil {
+			return err
+		}
+		if err := tx.Positions().Delete(ctx, developer.ID); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected %v, got %v", errFailed, err)
+	}
+
+	if _, err := uow.Positions().Get(ctx, developer.ID); err != nil {
+		t.Fatalf("expected the deleted position to be restored, got %v", err)
+	}
+	if employees, _ := uow.Employees().GetAll(ctx); len(employees) != 0 {
+		t.Fatalf("expected the created employee to be discarded, got %+v", employees)
+	}
+	if results, _ := uow.Employees().Search(ctx, "john", 10); len(results) != 0 {
+		t.Fatalf("expected the search index to be restored, got %+v", results)
+	}
+}
+
+// repositoryState is what the repositories return for the given employees and positions.
+type repositoryState struct {
+	Employees       []domain.Employee
+	PositionHistory map[string][]domain.PositionAssignment
+	Positions       []domain.Position
+	SalaryHistory   map[string][]domain.SalaryChange
+	Search          map[string][]domain.EmployeeSearchResult
+	ByPosition      map[string][]domain.Employee
+}
+
+func captureState(t *testing.T, ctx context.Context, uow *repository.UnitOfWork, employeeIDs, positionIDs []string) repositoryState {
+	t.Helper()
+
+	state := repositoryState{
+		PositionHistory: make(map[string][]domain.PositionAssignment),
+		SalaryHistory:   make(map[string][]domain.SalaryChange),
+		Search:          make(map[string][]domain.EmployeeSearchResult),
+		ByPosition:      make(map[string][]domain.Employee),
+	}
+	state.Employees, _ = uow.Employees().GetAll(ctx)
+	sort.Slice(state.Employees, func(i, j int) bool { return state.Employees[i].ID < state.Employees[j].ID })
+	state.Positions, _ = uow.Positions().GetAll(ctx)
+	sort.Slice(state.Positions, func(i, j int) bool { return state.Positions[i].ID < state.Positions[j].ID })
+
+	for _, id := range employeeIDs {
+		state.PositionHistory[id], _ = uow.Employees().PositionHistory(ctx, id)
+	}
+	for _, id := range positionIDs {
+		state.SalaryHistory[id], _ = uow.Positions().SalaryHistory(ctx, id)
+		state.ByPosition[id], _ = uow.Employees().ListByPosition(ctx, id)
+	}
+	for _, query := range []string{"john", "jane", "bob", "developer", "tester", "manager"} {
+		state.Search[query], _ = uow.Employees().Search(ctx, query, 10)
+	}
+	return state
+}
+
+func TestUnitOfWork_RollbackWrites(t *testing.T) {
+	uow, developer := newTestUnitOfWork(t)
+	ctx := context.Background()
+	future := time.Now().Add(24 * time.Hour)
+
+	tester := &domain.Position{Name: "Tester", Salary: domain.Money{Amount: 80000, Currency: "USD"}}
+	if err := uow.Positions().Create(ctx, tester); err != nil {
+		t.Fatal(err)
+	}
+	john := &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: developer.ID}
+	jane := &domain.Employee{FirstName: "Jane", LastName: "Doe", PositionID: tester.ID}
+	for _, employee := range []*domain.Employee{john, jane} {
+		if err := uow.Employees().Create(ctx, employee); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := uow.Employees().SchedulePositionChange(ctx, domain.PositionAssignment{EmployeeID: jane.ID, PositionID: developer.ID, EffectiveFrom: future}); err != nil {
+		t.Fatal(err)
+	}
+
+	employeeIDs, positionIDs := []string{john.ID, jane.ID}, []string{developer.ID, tester.ID}
+	before := captureState(t, ctx, uow, employeeIDs, positionIDs)
+
+	errFailed := errors.New("failed")
+	err := uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		manager := &domain.Position{Name: "Manager", Salary: domain.Money{Amount: 150000, Currency: "USD"}}
+		writes := []func() error{
+			func() error { return tx.Positions().Create(ctx, manager) },
+			func() error {
+				return tx.Employees().Update(ctx, domain.Employee{ID: john.ID, FirstName: "Johnny", LastName: "Smith", PositionID: manager.ID})
+			},
+			func() error {
+				return tx.Employees().Update(ctx, domain.Employee{ID: john.ID, FirstName: "Johnny", LastName: "Smythe", PositionID: tester.ID})
+			},
+			func() error {
+				return tx.Employees().SchedulePositionChange(ctx, domain.PositionAssignment{EmployeeID: john.ID, PositionID: manager.ID, EffectiveFrom: future})
+			},
+			func() error { return tx.Employees().Delete(ctx, jane.ID) },
+			func() error {
+				return tx.Employees().Create(ctx, &domain.Employee{FirstName: "Bob", LastName: "Brown", PositionID: developer.ID})
+			},
+			func() error {
+				return tx.Positions().Update(ctx, domain.Position{ID: developer.ID, Name: "Senior Developer", Salary: domain.Money{Amount: 120000, Currency: "USD"}})
+			},
+			func() error {
+				return tx.Positions().ScheduleSalaryChange(ctx, domain.SalaryChange{PositionID: tester.ID, Salary: domain.Money{Amount: 90000, Currency: "USD"}, EffectiveFrom: future})
+			},
+			func() error { return tx.Positions().Delete(ctx, tester.ID) },
+		}
+		for _, write := range writes {
+			if err := write(); err != nil {
+				return err
+			}
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected %v, got %v", errFailed, err)
+	}
+
+	if after := captureState(t, ctx, uow, employeeIDs, positionIDs); !reflect.DeepEqual(before, after) {
+		t.Errorf("expected the state before the transaction\n%+v\ngot\n%+v", before, after)
+	}
+}
+
+func TestUnitOfWork_DeletePosition(t *testing.T) {
+	uow, developer := newTestUnitOfWork(t)
+	ctx := context.Background()
+
+	tester := &domain.Position{Name: "Tester", Salary: domain.Money{Amount: 100000, Currency: "USD"}}
+	if err := uow.Positions().Create(ctx, tester); err != nil {
+		t.Fatal(err)
+	}
+	john := &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: developer.ID}
+	if err := uow.Employees().Create(ctx, john); err != nil {
+		t.Fatal(err)
+	}
+
+	err := uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		return domain.DeletePosition(ctx, tx, developer.ID, "")
+	})
+	if !errors.Is(err, domain.ErrPositionInUse) {
+		t.Fatalf("expected %v, got %v", domain.ErrPositionInUse, err)
+	}
+
+	err = uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		return domain.DeletePosition(ctx, tx, developer.ID, tester.ID)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reassigned, err := uow.Employees().Get(ctx, john.ID)
+	if err != nil || reassigned.PositionID != tester.ID {
+		t.Fatalf("expected the employee to be moved to %s, got %+v %v", tester.ID, reassigned, err)
+	}
+}
+
+func TestUnitOfWork_DeletePositionScheduled(t *testing.T) {
+	uow, developer := newTestUnitOfWork(t)
+	ctx := context.Background()
+
+	manager := &domain.Position{Name: "Manager", Salary: domain.Money{Amount: 150000, Currency: "USD"}}
+	tester := &domain.Position{Name: "Tester", Salary: domain.Money{Amount: 100000, Currency: "USD"}}
+	for _, p := range []*domain.Position{manager, tester} {
+		if err := uow.Positions().Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	john := &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: developer.ID}
+	if err := uow.Employees().Create(ctx, john); err != nil {
+		t.Fatal(err)
+	}
+	promotion := domain.PositionAssignment{EmployeeID: john.ID, PositionID: manager.ID, EffectiveFrom: time.Now().Add(time.Hour)}
+	if err := uow.Employees().SchedulePositionChange(ctx, promotion); err != nil {
+		t.Fatal(err)
+	}
+
+	// nobody holds the position yet, but the scheduled change would leave john without one
+	err := uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		return domain.DeletePosition(ctx, tx, manager.ID, "")
+	})
+	if !errors.Is(err, domain.ErrPositionInUse) {
+		t.Fatalf("expected %v, got %v", domain.ErrPositionInUse, err)
+	}
+
+	err = uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		return domain.DeletePosition(ctx, tx, manager.ID, tester.ID)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduled, err := uow.Employees().GetAsOf(ctx, john.ID, promotion.EffectiveFrom)
+	if err != nil || scheduled.PositionID != tester.ID {
+		t.Fatalf("expected the scheduled change to move the employee to %s, got %+v %v", tester.ID, scheduled, err)
+	}
+}
+
+func TestUnitOfWork_UpdatePositionBand(t *testing.T) {
+	usd := func(amount int64) domain.Money { return domain.Money{Amount: amount, Currency: "USD"} }
+	band := func(min, max int64) *domain.SalaryBand {
+		return &domain.SalaryBand{Min: usd(min), Mid: usd((min + max) / 2), Max: usd(max)}
+	}
+
+	tests := map[string]struct {
+		band      *domain.SalaryBand
+		scheduled bool
+		override  bool
+		err       error
+	}{
+		"contains salary":           {band: band(80000, 120000)},
+		"excludes salary":           {band: band(110000, 130000), err: domain.ErrBandExcludesEmployees},
+		"excludes scheduled salary": {band: band(110000, 130000), scheduled: true, err: domain.ErrBandExcludesEmployees},
+		"excludes overridden":       {band: band(110000, 130000), override: true},
+		"removed band":              {band: nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			uow, developer := newTestUnitOfWork(t)
+			ctx := context.Background()
+
+			manager := &domain.Position{Name: "Manager", Salary: usd(100000), Band: band(50000, 150000)}
+			if err := uow.Positions().Create(ctx, manager); err != nil {
+				t.Fatal(err)
+			}
+
+			salary := usd(100000)
+			john := &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: manager.ID, Salary: &salary, SalaryOverride: tc.override}
+			if tc.scheduled {
+				john.PositionID = developer.ID
+			}
+			if err := uow.Employees().Create(ctx, john); err != nil {
+				t.Fatal(err)
+			}
+			if tc.scheduled {
+				promotion := domain.PositionAssignment{EmployeeID: john.ID, PositionID: manager.ID, EffectiveFrom: time.Now().Add(time.Hour)}
+				if err := uow.Employees().SchedulePositionChange(ctx, promotion); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			updated := *manager
+			updated.Band = tc.band
+			err := uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+				return domain.UpdatePosition(ctx, tx, updated)
+			})
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+// TestStore_NoOrphans creates employees while their position is deleted, an employee must either
+// fail to be created or the position must refuse to be deleted.
+func TestStore_NoOrphans(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		uow, developer := newTestUnitOfWork(t)
+		ctx := context.Background()
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			uow.Employees().Create(ctx, &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: developer.ID})
+		}()
+		go func() {
+			defer wg.Done()
+			uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+				return domain.DeletePosition(ctx, tx, developer.ID, "")
+			})
+		}()
+		wg.Wait()
+
+		employees, err := uow.Employees().GetAll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = uow.Positions().Get(ctx, developer.ID)
+		if len(employees) > 0 && errors.Is(err, domain.ErrPositionNotFound) {
+			t.Fatalf("employee %+v references a deleted position", employees[0])
+		}
+	}
+}
+
+type publisherMock struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (p *publisherMock) Publish(event domain.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+// published returns the events published so far, scheduled changes are published from timers.
+func (p *publisherMock) published() []domain.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]domain.Event(nil), p.events...)
+}
+
+func TestUnitOfWork_PublishOnCommit(t *testing.T) {
+	publisher := &publisherMock{}
+	store := repository.NewStore(publisher)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+	ctx := context.Background()
+
+	create := func(ctx context.Context, tx domain.Tx) error {
+		return tx.Positions().Create(ctx, &domain.Position{Name: "Developer", Salary: domain.Money{Amount: 100000, Currency: "USD"}})
+	}
+
+	uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+		if err := create(ctx, tx); err != nil {
+			return err
+		}
+		if len(publisher.events) != 0 {
+			t.Fatalf("expected events to be held back until commit, got %+v", publisher.events)
+		}
+		return errors.New("rollback")
+	})
+	if len(publisher.events) != 0 {
+		t.Fatalf("expected no events of a rolled back transaction, got %+v", publisher.events)
+	}
+
+	if err := uow.Do(ctx, create); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != domain.EventCreated || publisher.events[0].Entity != domain.EntityPosition {
+		t.Fatalf("expected a position created event, got %+v", publisher.events)
+	}
+}
+
+func TestUpdate_AfterScheduledChange(t *testing.T) {
+	publisher := &publisherMock{}
+	store := repository.NewStore(publisher)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	ctx := context.Background()
+
+	developer := &domain.Position{Name: "Developer", Salary: domain.Money{Amount: 100000, Currency: "USD"}}
+	manager := &domain.Position{Name: "Manager", Salary: domain.Money{Amount: 150000, Currency: "USD"}}
+	for _, p := range []*domain.Position{developer, manager} {
+		if err := positions.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ann := &domain.Employee{FirstName: "Ann", LastName: "Lee", PositionID: developer.ID}
+	if err := employees.Create(ctx, ann); err != nil {
+		t.Fatal(err)
+	}
+
+	effective := time.Now().Add(20 * time.Millisecond)
+	raise := domain.Money{Amount: 120000, Currency: "USD"}
+	if err := employees.SchedulePositionChange(ctx, domain.PositionAssignment{EmployeeID: ann.ID, PositionID: manager.ID, EffectiveFrom: effective}); err != nil {
+		t.Fatal(err)
+	}
+	if err := positions.ScheduleSalaryChange(ctx, domain.SalaryChange{PositionID: developer.ID, Salary: raise, EffectiveFrom: effective}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Until(effective) + 50*time.Millisecond)
+
+	// the updates keep the position and the salary that took effect
+	renamed := *ann
+	renamed.PositionID, renamed.LastName = manager.ID, "Park"
+	if err := employees.Update(ctx, renamed); err != nil {
+		t.Fatal(err)
+	}
+	renamedPosition := *developer
+	renamedPosition.Salary, renamedPosition.Name = raise, "Senior Developer"
+	if err := positions.Update(ctx, renamedPosition); err != nil {
+		t.Fatal(err)
+	}
+
+	events := publisher.published()
+	for _, event := range events[len(events)-2:] {
+		if event.Type != domain.EventUpdated {
+			t.Fatalf("expected an update, got %+v", event)
+		}
+		if names := event.Names(); len(names) != 1 {
+			t.Errorf("expected only %s.updated, got %v", event.Entity, names)
+		}
+	}
+}
+
+func TestSchedulePositionChange_SalaryBand(t *testing.T) {
+	store := repository.NewStore(nil)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	ctx := context.Background()
+
+	usd := func(amount int64) domain.Money { return domain.Money{Amount: amount, Currency: "USD"} }
+	developer := &domain.Position{Name: "Developer", Salary: usd(100000)}
+	intern := &domain.Position{Name: "Intern", Salary: usd(30000), Band: &domain.SalaryBand{Min: usd(20000), Mid: usd(30000), Max: usd(40000)}}
+	for _, p := range []*domain.Position{developer, intern} {
+		if err := positions.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	salary := usd(100000)
+	tests := map[string]struct {
+		override bool
+		err      error
+	}{
+		"out of band": {err: domain.ErrSalaryOutOfBand},
+		"override":    {override: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ann := &domain.Employee{FirstName: "Ann", LastName: "Lee", PositionID: developer.ID, Salary: &salary, SalaryOverride: tt.override}
+			if err := employees.Create(ctx, ann); err != nil {
+				t.Fatal(err)
+			}
+
+			change := domain.PositionAssignment{EmployeeID: ann.ID, PositionID: intern.ID, EffectiveFrom: time.Now().Add(time.Hour)}
+			if err := employees.SchedulePositionChange(ctx, change); !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestUnitOfWork_Nested(t *testing.T) {
+	create := func(name string) func(ctx context.Context, tx domain.Tx) error {
+		return func(ctx context.Context, tx domain.Tx) error {
+			return tx.Positions().Create(ctx, &domain.Position{Name: name, Salary: domain.Money{Amount: 100000, Currency: "USD"}})
+		}
+	}
+	fail := func(fn func(ctx context.Context, tx domain.Tx) error) func(ctx context.Context, tx domain.Tx) error {
+		return func(ctx context.Context, tx domain.Tx) error {
+			if err := fn(ctx, tx); err != nil {
+				return err
+			}
+			return errors.New("rollback")
+		}
+	}
+
+	tests := map[string]struct {
+		inner, outer func(ctx context.Context, tx domain.Tx) error
+		expected     []string
+	}{
+		"both commit":      {inner: create("Inner"), outer: create("Outer"), expected: []string{"Inner", "Outer"}},
+		"outer rolls back": {inner: create("Inner"), outer: fail(create("Outer")), expected: []string{}},
+		"inner rolls back": {inner: fail(create("Inner")), outer: create("Outer"), expected: []string{"Outer"}},
+		"both roll back":   {inner: fail(create("Inner")), outer: fail(create("Outer")), expected: []string{}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			publisher := &publisherMock{}
+			store := repository.NewStore(publisher)
+			positions := position.NewPositionsRepository(store)
+			employees := employee.NewEmployeesRepository(store, positions)
+			uow := repository.NewUnitOfWork(store, employees, positions)
+			ctx := context.Background()
+
+			uow.Do(ctx, func(ctx context.Context, tx domain.Tx) error {
+				uow.Do(ctx, tc.inner)
+				return tc.outer(ctx, tx)
+			})
+
+			all, err := positions.GetAll(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored := make([]string, 0)
+			for _, p := range all {
+				stored = append(stored, p.Name)
+			}
+			sort.Strings(stored)
+			if !reflect.DeepEqual(stored, tc.expected) {
+				t.Errorf("expected positions %v, got %v", tc.expected, stored)
+			}
+
+			published := make([]string, 0)
+			for _, event := range publisher.published() {
+				published = append(published, event.Position.Name)
+			}
+			sort.Strings(published)
+			if !reflect.DeepEqual(published, tc.expected) {
+				t.Errorf("expected events of %v, got %v", tc.expected, published)
+			}
+		})
+	}
+}
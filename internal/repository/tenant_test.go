@@ -0,0 +1,180 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	"github.com/dilyara4949/employees-api/internal/tenant"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	store := repository.NewStore(nil)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+
+	acme, globex := tenant.With(context.Background(), "acme"), tenant.With(context.Background(), "globex")
+	create := func(ctx context.Context) (*domain.Position, *domain.Employee) {
+		t.Helper()
+		developer := &domain.Position{Name: "Developer", Salary: domain.Money{Amount: 100000, Currency: "USD"}}
+		if err := positions.Create(ctx, developer); err != nil {
+			t.Fatal(err)
+		}
+		john := &domain.Employee{FirstName: "John", LastName: "Smith", PositionID: developer.ID}
+		if err := employees.Create(ctx, john); err != nil {
+			t.Fatal(err)
+		}
+		return developer, john
+	}
+	acmeDeveloper, acmeJohn := create(acme)
+	globexDeveloper, globexJohn := create(globex)
+	future := time.Now().Add(24 * time.Hour)
+
+	writes := map[string]struct {
+		write    func() error
+		expected error
+	}{
+		"update employee": {
+			write: func() error {
+				return employees.Update(acme, domain.Employee{ID: globexJohn.ID, FirstName: "Jane", LastName: "Smith", PositionID: acmeDeveloper.ID})
+			},
+			expected: domain.ErrEmployeeNotFound,
+		},
+		"delete employee": {
+			write:    func() error { return employees.Delete(acme, globexJohn.ID) },
+			expected: domain.ErrEmployeeNotFound,
+		},
+		"schedule position change": {
+			write: func() error {
+				return employees.SchedulePositionChange(acme, domain.PositionAssignment{EmployeeID: globexJohn.ID, PositionID: acmeDeveloper.ID, EffectiveFrom: future})
+			},
+			expected: domain.ErrEmployeeNotFound,
+		},
+		"assign position of other tenant": {
+			write: func() error {
+				return employees.Update(acme, domain.Employee{ID: acmeJohn.ID, FirstName: "John", LastName: "Smith", PositionID: globexDeveloper.ID})
+			},
+			expected: domain.ErrPositionNotFound,
+		},
+		"create employee in position of other tenant": {
+			write: func() error {
+				return employees.Create(acme, &domain.Employee{FirstName: "Jane", LastName: "Doe", PositionID: globexDeveloper.ID})
+			},
+			expected: domain.ErrPositionNotFound,
+		},
+		"update position": {
+			write: func() error {
+				return positions.Update(acme, domain.Position{ID: globexDeveloper.ID, Name: "Manager", Salary: domain.Money{Amount: 1, Currency: "USD"}})
+			},
+			expected: domain.ErrPositionNotFound,
+		},
+		"delete position": {
+			write:    func() error { return positions.Delete(acme, globexDeveloper.ID) },
+			expected: domain.ErrPositionNotFound,
+		},
+		"schedule salary change": {
+			write: func() error {
+				return positions.ScheduleSalaryChange(acme, domain.SalaryChange{PositionID: globexDeveloper.ID, Salary: domain.Money{Amount: 1, Currency: "USD"}, EffectiveFrom: future})
+			},
+			expected: domain.ErrPositionNotFound,
+		},
+	}
+
+	for name, tc := range writes {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.write(); !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	reads := map[string]func(ctx context.Context) error{
+		"get employee": func(ctx context.Context) error {
+			_, err := employees.Get(ctx, globexJohn.ID)
+			return err
+		},
+		"get employee as of": func(ctx context.Context) error {
+			_, err := employees.GetAsOf(ctx, globexJohn.ID, time.Now())
+			return err
+		},
+		"position history": func(ctx context.Context) error {
+			_, err := employees.PositionHistory(ctx, globexJohn.ID)
+			return err
+		},
+		"get position": func(ctx context.Context) error {
+			_, err := positions.Get(ctx, globexDeveloper.ID)
+			return err
+		},
+		"salary history": func(ctx context.Context) error {
+			_, err := positions.SalaryHistory(ctx, globexDeveloper.ID)
+			return err
+		},
+	}
+
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			if err := read(acme); err == nil {
+				t.Error("expected the entity of the other tenant not to be found")
+			}
+			if err := read(globex); err != nil {
+				t.Errorf("expected the entity to be found by its tenant, got %v", err)
+			}
+			if err := read(context.Background()); err == nil {
+				t.Error("expected the entity not to be found by the default tenant")
+			}
+		})
+	}
+
+	t.Run("lists", func(t *testing.T) {
+		if all, _ := employees.GetAll(acme); len(all) != 1 || all[0].ID != acmeJohn.ID {
+			t.Errorf("expected only the employee of the tenant, got %+v", all)
+		}
+		if all, _ := positions.GetAll(acme); len(all) != 1 || all[0].ID != acmeDeveloper.ID {
+			t.Errorf("expected only the position of the tenant, got %+v", all)
+		}
+		if results, _ := employees.Search(acme, "john developer", 10); len(results) != 1 || results[0].ID != acmeJohn.ID {
+			t.Errorf("expected to find only the employee of the tenant, got %+v", results)
+		}
+		if hits, _ := positions.SearchName(acme, "developer"); len(hits) != 1 {
+			t.Errorf("expected to find only the position of the tenant, got %v", hits)
+		}
+		if listed, _ := employees.ListByPosition(acme, globexDeveloper.ID); len(listed) != 0 {
+			t.Errorf("expected no employees of the position of the other tenant, got %+v", listed)
+		}
+
+		ids := make([]string, 0)
+		employees.Iterate(acme, func(e domain.Employee) error {
+			ids = append(ids, e.ID)
+			return nil
+		})
+		if len(ids) != 1 || ids[0] != acmeJohn.ID {
+			t.Errorf("expected to iterate only the employee of the tenant, got %v", ids)
+		}
+
+		if all, _ := employees.GetAll(context.Background()); len(all) != 0 {
+			t.Errorf("expected the default tenant to see no employees, got %+v", all)
+		}
+	})
+
+	t.Run("other tenant unchanged", func(t *testing.T) {
+		john, err := employees.Get(globex, globexJohn.ID)
+		if err != nil || john.FirstName != "John" || john.PositionID != globexDeveloper.ID {
+			t.Fatalf("expected the employee unchanged, got %+v, %v", john, err)
+		}
+		if history, _ := employees.PositionHistory(globex, globexJohn.ID); len(history) != 1 {
+			t.Errorf("expected no scheduled position change, got %+v", history)
+		}
+		developer, err := positions.Get(globex, globexDeveloper.ID)
+		if err != nil || developer.Name != "Developer" || developer.Salary.Amount != 100000 {
+			t.Fatalf("expected the position unchanged, got %+v, %v", developer, err)
+		}
+		if history, _ := positions.SalaryHistory(globex, globexDeveloper.ID); len(history) != 1 {
+			t.Errorf("expected no scheduled salary change, got %+v", history)
+		}
+	})
+}
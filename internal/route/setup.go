@@ -1,38 +1,191 @@
 package route
 
 import (
+	"github.com/dilyara4949/employees-api/internal/apikey"
 	conf "github.com/dilyara4949/employees-api/internal/config"
+	"github.com/dilyara4949/employees-api/internal/idempotency"
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
 	"github.com/redis/go-redis/v9"
 	"net/http"
+	"strings"
 
 	"github.com/dilyara4949/employees-api/internal/controller"
+	"github.com/dilyara4949/employees-api/internal/dto"
 	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
-func SetUpRouter(employeesController *controller.EmployeesController, positionsController *controller.PositionsController, config conf.Config, mux *http.ServeMux, cache *redis.Client) {
+// Mux registers the handlers of route patterns, it is implemented by http.ServeMux.
+type Mux interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// SetUpRouter registers the routes of every API version. The v1 routes are also served without the version prefix,
+// as aliases of the original unversioned API. v2 only changes the representation of employees and positions,
+// so the other resources are served by v1. If spec is not nil, requests and responses are validated against it.
+func SetUpRouter(employeesController *controller.EmployeesController, positionsController *controller.PositionsController, bulkController *controller.BulkController, eventsController *controller.EventsController, webhooksController *controller.WebhooksController, docsController *controller.DocsController, authController *controller.AuthController, apiKeysController *controller.APIKeysController, config conf.Config, mux Mux, jwtAuth *middleware.JWTAuth, cache *redis.Client, limiter ratelimit.Limiter, spec *openapi3.T) {
+	handle := func(pattern string, endpoint http.HandlerFunc) {
+		authenticate := middleware.Authenticate(apiKeysController.Store, scope(pattern), jwtAuth)
+		mux.HandleFunc(pattern, logCorrelationIDTimer(pattern, endpoint, config, authenticate, cache, limiter, spec))
+	}
+	v1 := func(pattern string, endpoint http.HandlerFunc) {
+		handle(pattern, endpoint)
+		handle(versioned("v1", pattern), endpoint)
+	}
+	v2 := func(pattern string, endpoint http.HandlerFunc) {
+		handle(versioned("v2", pattern), endpoint)
+	}
+
+	for _, v := range []struct {
+		handle    func(pattern string, endpoint http.HandlerFunc)
+		employees *controller.EmployeesController
+		positions *controller.PositionsController
+	}{
+		{handle: v1, employees: employeesController.WithCodec(dto.V1), positions: positionsController.WithCodec(dto.V1)},
+		{handle: v2, employees: employeesController.WithCodec(dto.V2), positions: positionsController.WithCodec(dto.V2)},
+	} {
+		v.handle("GET /positions/{id}", v.positions.GetPosition)
+		v.handle("POST /positions", v.positions.CreatePosition)
+		v.handle("DELETE /positions/{id}", v.positions.DeletePosition)
+		v.handle("PUT /positions/{id}", v.positions.UpdatePosition)
+		v.handle("GET /positions", v.positions.GetAllPositions)
+		v.handle("GET /positions/{id}/as-of", v.positions.GetPositionAsOf)
+		v.handle("GET /positions/{id}/history", v.positions.GetSalaryHistory)
+		v.handle("POST /positions/{id}/history", v.positions.ScheduleSalaryChange)
+
+		v.handle("GET /employees/{id}", v.employees.GetEmployee)
+		v.handle("POST /employees", v.employees.CreateEmployee)
+		v.handle("DELETE /employees/{id}", v.employees.DeleteEmployee)
+		v.handle("PUT /employees/{id}", v.employees.UpdateEmployee)
+		v.handle("GET /employees", v.employees.GetAllEmployees)
+		v.handle("GET /employees/search", v.employees.SearchEmployees)
+		v.handle("GET /employees/{id}/as-of", v.employees.GetEmployeeAsOf)
+		v.handle("GET /employees/{id}/history", v.employees.GetPositionHistory)
+		v.handle("POST /employees/{id}/history", v.employees.SchedulePositionChange)
+	}
+
+	v1("POST /positions/import", bulkController.ImportPositions)
+	v1("GET /positions/export", bulkController.ExportPositions)
+	v1("POST /positions:batch", bulkController.BatchPositions)
+	v1("POST /employees/import", bulkController.ImportEmployees)
+	v1("GET /employees/export", bulkController.ExportEmployees)
+	v1("POST /employees:batch", bulkController.BatchEmployees)
 
-	mux.HandleFunc("GET /positions/{id}", logCorrelationIDTimer(positionsController.GetPosition, config, cache))
-	mux.HandleFunc("POST /positions", logCorrelationIDTimer(positionsController.CreatePosition, config, cache))
-	mux.HandleFunc("DELETE /positions/{id}", logCorrelationIDTimer(positionsController.DeletePosition, config, cache))
-	mux.HandleFunc("PUT /positions/{id}", logCorrelationIDTimer(positionsController.UpdatePosition, config, cache))
-	mux.HandleFunc("GET /positions", logCorrelationIDTimer(positionsController.GetAllPositions, config, cache))
+	v1("GET /events", eventsController.StreamEvents)
 
-	mux.HandleFunc("GET /employees/{id}", logCorrelationIDTimer(employeesController.GetEmployee, config, cache))
-	mux.HandleFunc("POST /employees", logCorrelationIDTimer(employeesController.CreateEmployee, config, cache))
-	mux.HandleFunc("DELETE /employees/{id}", logCorrelationIDTimer(employeesController.DeleteEmployee, config, cache))
-	mux.HandleFunc("PUT /employees/{id}", logCorrelationIDTimer(employeesController.UpdateEmployee, config, cache))
-	mux.HandleFunc("GET /employees", logCorrelationIDTimer(employeesController.GetAllEmployees, config, cache))
+	v1("POST /webhooks", webhooksController.CreateWebhook)
+	v1("GET /webhooks", webhooksController.GetAllWebhooks)
+	v1("GET /webhooks/{id}", webhooksController.GetWebhook)
+	v1("PUT /webhooks/{id}", webhooksController.UpdateWebhook)
+	v1("DELETE /webhooks/{id}", webhooksController.DeleteWebhook)
+	v1("GET /webhooks/{id}/deliveries", webhooksController.GetDeliveries)
+	v1("GET /webhooks/dead-letters", webhooksController.GetDeadLetters)
+	v1("POST /webhooks/dead-letters/{id}/retry", webhooksController.RetryDeadLetter)
+
+	// API keys can't manage API keys, managing them takes a JWT with the apikey.RoleAdmin role
+	for _, route := range []struct {
+		pattern  string
+		endpoint http.HandlerFunc
+	}{
+		{pattern: "POST /api-keys", endpoint: apiKeysController.CreateAPIKey},
+		{pattern: "GET /api-keys", endpoint: apiKeysController.GetAllAPIKeys},
+		{pattern: "GET /api-keys/{id}", endpoint: apiKeysController.GetAPIKey},
+		{pattern: "POST /api-keys/{id}/revoke", endpoint: apiKeysController.RevokeAPIKey},
+	} {
+		for _, pattern := range []string{route.pattern, versioned("v1", route.pattern)} {
+			mux.HandleFunc(pattern, logCorrelationIDTimer(pattern, route.endpoint, config, jwtAuth.Auth(), cache, limiter, spec))
+		}
+	}
+
+	// the documentation is public
+	public := []middleware.Middleware{middleware.Logger(), middleware.Timer(), middleware.CorrelationIDMiddleware()}
+	mux.HandleFunc("GET /openapi.yaml", middleware.Chain(docsController.GetSpec, public...))
+	mux.HandleFunc("GET /docs", middleware.Chain(docsController.GetDocs, public...))
+	mux.HandleFunc("GET /docs/{file}", middleware.Chain(docsController.GetDocsAsset, public...))
+
+	// obtaining a token doesn't require one, the rate limit slows down password guessing
+	for pattern, endpoint := range map[string]http.HandlerFunc{
+		"POST /auth/token":  authController.Token,
+		"POST /auth/revoke": authController.Revoke,
+	} {
+		middlewares := make([]middleware.Middleware, 0)
+		if spec != nil {
+			middlewares = append(middlewares, middleware.Validate(spec, pattern))
+		}
+		middlewares = append(middlewares, middleware.RateLimit(limiter, pattern, config.RateLimit))
+		mux.HandleFunc(pattern, middleware.Chain(endpoint, append(middlewares, public...)...))
+	}
+}
+
+// documented returns the route pattern as documented in the OpenAPI spec, which lists the v1 routes without prefix.
+func documented(pattern string) string {
+	method, path, _ := strings.Cut(pattern, " ")
+	return method + " " + strings.TrimPrefix(path, "/v1")
 }
 
-func logCorrelationIDTimer(endpoint http.HandlerFunc, config conf.Config, cache *redis.Client) http.HandlerFunc {
-	auth := middleware.NewJWTAuth(config.JWTTokenSecret)
-	middlewares := []middleware.Middleware{
+// unversioned returns the route pattern without the version prefix, e.g. "GET /employees" for "GET /v2/employees".
+// Rate limits are shared by the aliases of a route, so alternating the prefixes doesn't multiply the quota.
+func unversioned(pattern string) string {
+	method, path, _ := strings.Cut(pattern, " ")
+	if version, rest, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/"); ok && (version == "v1" || version == "v2") {
+		path = "/" + rest
+	}
+	return method + " " + path
+}
+
+// scope returns the API key scope required by the route pattern, e.g. "employees:read" for "GET /v1/employees/{id}"
+// and "employees:write" for "POST /employees:batch".
+func scope(pattern string) string {
+	method, path, _ := strings.Cut(unversioned(pattern), " ")
+
+	resource, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	resource, _, _ = strings.Cut(resource, ":")
+	return apikey.Scope(resource, method != http.MethodGet)
+}
+
+// versioned prefixes the path of the route pattern with the version, e.g. "GET /v2/employees".
+func versioned(version, pattern string) string {
+	method, path, _ := strings.Cut(pattern, " ")
+	return method + " /" + version + path
+}
+
+func logCorrelationIDTimer(pattern string, endpoint http.HandlerFunc, config conf.Config, authenticate middleware.Middleware, cache *redis.Client, limiter ratelimit.Limiter, spec *openapi3.T) http.HandlerFunc {
+	middlewares := make([]middleware.Middleware, 0)
+	if spec != nil {
+		middlewares = append(middlewares, middleware.Validate(spec, documented(pattern)))
+	}
+	middlewares = append(middlewares,
+		middleware.Idempotency(idempotency.NewRedisStore(cache), config.IdempotencyTTL),
 		middleware.Cache(cache, config.RedisConfig.Ttl),
-		auth.Auth(),
+		middleware.RateLimit(limiter, unversioned(pattern), config.RateLimit),
+		authenticate,
 		middleware.Logger(),
 		middleware.Timer(),
 		middleware.CorrelationIDMiddleware(),
+	)
+
+	// a deprecation of the unversioned route applies to its aliases unless the versioned route has its own
+	deprecation, ok := config.Deprecations[pattern]
+	if !ok {
+		deprecation, ok = config.Deprecations[unversioned(pattern)]
+	}
+	if ok {
+		middlewares = append(middlewares, middleware.Deprecate(deprecation))
 	}
 
 	return middleware.Chain(endpoint, middlewares...)
 }
+
+// SetUpGateway serves the gRPC services transcoded to JSON/HTTP by gateway under the prefix of the config,
+// e.g. "GET /gateway/v1/employees". Rate limits, idempotency keys and the scopes of API keys are applied by the
+// gRPC interceptors.
+func SetUpGateway(gateway http.Handler, config conf.Config, mux Mux, jwtAuth *middleware.JWTAuth, apiKeys middleware.APIKeys) {
+	handler := http.StripPrefix(config.GatewayPrefix, gateway)
+
+	mux.HandleFunc(config.GatewayPrefix+"/", middleware.Chain(handler.ServeHTTP,
+		middleware.Authenticate(apiKeys, "", jwtAuth),
+		middleware.Logger(),
+		middleware.Timer(),
+		middleware.CorrelationIDMiddleware(),
+	))
+}
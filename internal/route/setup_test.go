@@ -0,0 +1,610 @@
+package route
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dilyara4949/employees-api/docs/openapi"
+	"github.com/dilyara4949/employees-api/internal/apikey"
+	"github.com/dilyara4949/employees-api/internal/auth"
+	"github.com/dilyara4949/employees-api/internal/bulk"
+	conf "github.com/dilyara4949/employees-api/internal/config"
+	"github.com/dilyara4949/employees-api/internal/controller"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/dilyara4949/employees-api/internal/middleware"
+	"github.com/dilyara4949/employees-api/internal/ratelimit"
+	"github.com/dilyara4949/employees-api/internal/repository"
+	"github.com/dilyara4949/employees-api/internal/repository/employee"
+	"github.com/dilyara4949/employees-api/internal/repository/position"
+	"github.com/dilyara4949/employees-api/internal/webhook"
+	"github.com/getkin/kin-openapi/openapi3"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+const secret = "secret"
+
+// recordingMux records the registered route patterns.
+type recordingMux struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (m *recordingMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.HandleFunc(pattern, handler)
+}
+
+func setUp(t *testing.T, spec *openapi3.T, options ...func(*conf.Config)) *recordingMux {
+	t.Helper()
+
+	bus := events.NewBus(100)
+	store := repository.NewStore(bus)
+	positions := position.NewPositionsRepository(store)
+	employees := employee.NewEmployeesRepository(store, positions)
+	uow := repository.NewUnitOfWork(store, employees, positions)
+
+	webhooks := webhook.NewStore()
+	mux := &recordingMux{ServeMux: http.NewServeMux()}
+
+	cache := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { cache.Close() })
+
+	config := conf.Config{
+		JWTTokenSecret: secret,
+		IdempotencyTTL: time.Hour,
+		RateLimit:      ratelimit.Policy{Default: ratelimit.Limit{Requests: 1000, Period: time.Minute}},
+		Tokens:         auth.TokenConfig{AccessTTL: time.Minute, RefreshTTL: time.Hour},
+		RedisConfig:    conf.RedisConfig{Ttl: time.Hour},
+	}
+	for _, option := range options {
+		option(&config)
+	}
+
+	hash, err := auth.HashBcrypt("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := auth.NewMemoryUserStore(auth.User{ID: "1", Username: "ann", PasswordHash: hash})
+	issuer := auth.NewIssuer(secret, config.Tokens, users, auth.NewRefreshStore(cache), auth.NewRevocationList(cache))
+
+	SetUpRouter(
+		controller.NewEmployeesController(employees),
+		controller.NewPositionsController(positions, uow, nil),
+		controller.NewBulkController(bulk.NewImporter(employees, positions, uow), bulk.NewExporter(employees, positions), bulk.NewBatcher(uow)),
+		controller.NewEventsController(bus),
+		controller.NewWebhooksController(webhooks, webhook.NewDispatcher(webhooks, bus)),
+		controller.NewDocsController(openapi.Spec, openapi.SwaggerUI, openapi.SwaggerUIAssets()),
+		controller.NewAuthController(issuer),
+		controller.NewAPIKeysController(apikey.NewStore()),
+		config, mux, middleware.NewJWTAuth(middleware.JWTConfig{Secret: secret, TenantClaim: "tenant_id", DefaultTenantFallback: true}, auth.NewRevocationList(cache)), cache, ratelimit.NewMemoryLimiter(), spec,
+	)
+	return mux
+}
+
+func loadSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+func TestSetUpRouter_RoutesDocumented(t *testing.T) {
+	spec := loadSpec(t)
+	mux := setUp(t, nil)
+
+	registered := make(map[string]bool)
+	for _, pattern := range mux.patterns {
+		route := documented(pattern)
+		registered[route] = true
+
+		method, path, _ := strings.Cut(route, " ")
+		item := spec.Paths.Find(path)
+		if item == nil || item.GetOperation(method) == nil {
+			t.Errorf("route %s is missing from the openapi spec", pattern)
+		}
+	}
+
+	for path, item := range spec.Paths.Map() {
+		for method := range item.Operations() {
+			if !registered[method+" "+path] {
+				t.Errorf("operation %s %s of the openapi spec is not served", method, path)
+			}
+		}
+	}
+}
+
+func TestSetUpRouter_ResponsesMatchSpec(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	mux := setUp(t, loadSpec(t))
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "ann", "roles": []string{webhook.RoleAdmin}}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(method, target, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+token)
+		if body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, r)
+		return rr
+	}
+
+	rr := request(http.MethodPost, "/positions", `{"name":"Engineer","salary":{"amount":15000,"currency":"USD"},"band":{"min":{"amount":10000,"currency":"USD"},"mid":{"amount":15000,"currency":"USD"},"max":{"amount":20000,"currency":"USD"}}}`)
+	positionID := createdID(t, rr)
+
+	rr = request(http.MethodPost, "/v2/employees", `{"first_name":"Ann","last_name":"Lee","position_id":"`+positionID+`","salary":{"amount":16000,"currency":"USD"}}`)
+	employeeID := createdID(t, rr)
+
+	tests := []struct {
+		method, target, body string
+		expectedStatus       int
+	}{
+		{method: http.MethodPost, target: "/employees", body: `{"firstname":"Bob","lastname":"Lee","position_id":"` + positionID + `"}`, expectedStatus: http.StatusCreated},
+		{method: http.MethodGet, target: "/employees/" + employeeID, expectedStatus: http.StatusOK},
+		{method: http.MethodGet, target: "/v1/employees", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, target: "/v2/employees/" + employeeID, expectedStatus: http.StatusOK},
+		{method: http.MethodGet, target: "/v2/employees/search?q=ann", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, target: "/employees/search?q=ann&limit=5", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, target: "/v2/positions", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, target: "/positions/" + positionID + "/as-of?date=2100-01-01", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, target: "/v2/positions/" + positionID + "/history", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, target: "/v2/employees/" + employeeID + "/history", expectedStatus: http.StatusOK},
+		{method: http.MethodPost, target: "/v2/positions/" + positionID + "/history", body: `{"salary":{"amount":17000,"currency":"USD"},"effective_from":"2100-01-01T00:00:00Z"}`, expectedStatus: http.StatusCreated},
+		{method: http.MethodPost, target: "/employees:batch", body: `{"operations":[{"op":"delete","id":"missing"}]}`, expectedStatus: http.StatusUnprocessableEntity},
+		{method: http.MethodPost, target: "/webhooks", body: `{"url":"https://example.com/hook","events":["employee.created"]}`, expectedStatus: http.StatusCreated},
+		{method: http.MethodGet, target: "/webhooks/dead-letters", expectedStatus: http.StatusOK},
+
+		{method: http.MethodGet, target: "/employees/search", expectedStatus: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/employees/search?q=ann&limit=many", expectedStatus: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/positions", body: `{"name":"Manager","salary":{"amount":"a lot","currency":"USD"}}`, expectedStatus: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/webhooks", body: `{"url":"https://example.com/hook","events":["employee.hired"]}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		if rr := request(tc.method, tc.target, tc.body); rr.Code != tc.expectedStatus {
+			t.Errorf("%s %s: expected %d, got %d %s", tc.method, tc.target, tc.expectedStatus, rr.Code, rr.Body.String())
+		}
+	}
+
+	mismatches := make([]string, 0)
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, "does not match the openapi spec") {
+			mismatches = append(mismatches, line)
+		}
+	}
+	if len(mismatches) > 0 {
+		t.Errorf("expected responses to match the spec, got:\n%s", strings.Join(mismatches, "\n"))
+	}
+}
+
+func createdID(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	return created.ID
+}
+
+func TestSetUpRouter_Docs(t *testing.T) {
+	mux := setUp(t, nil)
+
+	for target, contentType := range map[string]string{
+		"/openapi.yaml":                "application/yaml",
+		"/docs":                        "text/html; charset=utf-8",
+		"/docs/swagger-initializer.js": "text/javascript; charset=utf-8",
+	} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != contentType || rr.Body.Len() == 0 {
+			t.Errorf("%s: expected 200 %s without authorization, got %d %s", target, contentType, rr.Code, rr.Header().Get("Content-Type"))
+		}
+	}
+
+	// the page loads its assets from /docs, not from a CDN
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	for _, external := range []string{"http://", "https://", "//unpkg.com"} {
+		if strings.Contains(rr.Body.String(), external) {
+			t.Errorf("expected only local assets, got %s", rr.Body.String())
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/missing.js", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing asset, got %d", rr.Code)
+	}
+}
+
+func TestSetUpRouter_Auth(t *testing.T) {
+	mux := setUp(t, loadSpec(t))
+
+	request := func(method, target, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		if body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, r)
+		return rr
+	}
+	issue := func(body string) auth.TokenPair {
+		t.Helper()
+
+		rr := request(http.MethodPost, "/auth/token", "", body)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d %s", rr.Code, rr.Body.String())
+		}
+
+		var tokens auth.TokenPair
+		if err := json.Unmarshal(rr.Body.Bytes(), &tokens); err != nil {
+			t.Fatal(err)
+		}
+		return tokens
+	}
+
+	login := issue(`{"grant_type":"password","username":"ann","password":"password"}`)
+	if rr := request(http.MethodGet, "/employees", login.AccessToken, ""); rr.Code != http.StatusOK {
+		t.Errorf("expected the issued token to be accepted, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	refreshed := issue(`{"grant_type":"refresh_token","refresh_token":"` + login.RefreshToken + `"}`)
+	if rr := request(http.MethodGet, "/employees", refreshed.AccessToken, ""); rr.Code != http.StatusOK {
+		t.Errorf("expected the refreshed token to be accepted, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := request(http.MethodPost, "/auth/revoke", "", `{"token":"`+login.AccessToken+`"}`); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodGet, "/employees", login.AccessToken, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected the revoked token to be rejected, got %d", rr.Code)
+	}
+
+	tests := map[string]struct {
+		body           string
+		expectedStatus int
+	}{
+		"wrong password": {
+			body:           `{"grant_type":"password","username":"ann","password":"Password"}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"reused refresh token": {
+			body:           `{"grant_type":"refresh_token","refresh_token":"` + login.RefreshToken + `"}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"missing password": {
+			body:           `{"grant_type":"password","username":"ann"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"unsupported grant": {
+			body:           `{"grant_type":"client_credentials"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rr := request(http.MethodPost, "/auth/token", "", tc.body); rr.Code != tc.expectedStatus {
+				t.Errorf("expected %d, got %d %s", tc.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetUpRouter_APIKeys(t *testing.T) {
+	mux := setUp(t, loadSpec(t))
+
+	sign := func(claims jwt.MapClaims) string {
+		t.Helper()
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	admin := sign(jwt.MapClaims{"sub": "ann", "roles": []string{apikey.RoleAdmin}})
+
+	request := func(method, target, token, key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		if key != "" {
+			r.Header.Set(middleware.APIKeyHeader, key)
+		}
+		if body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, r)
+		return rr
+	}
+
+	rr := request(http.MethodPost, "/api-keys", admin, "", `{"name":"nightly export","owner":"payroll","scopes":["employees:read"]}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rr.Code, rr.Body.String())
+	}
+	var created struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		method, target, token, key, body string
+		expectedStatus                   int
+	}{
+		"key in scope": {
+			method: http.MethodGet, target: "/employees", key: created.Key,
+			expectedStatus: http.StatusOK,
+		},
+		"key in scope of v1": {
+			method: http.MethodGet, target: "/v1/employees", key: created.Key,
+			expectedStatus: http.StatusOK,
+		},
+		"key out of scope": {
+			method: http.MethodPost, target: "/employees", key: created.Key, body: `{"firstname":"Ann","lastname":"Lee","position_id":"1"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		"key of another resource": {
+			method: http.MethodGet, target: "/positions", key: created.Key,
+			expectedStatus: http.StatusForbidden,
+		},
+		"invalid key": {
+			method: http.MethodGet, target: "/employees", key: created.Key + "x",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"key managing keys": {
+			method: http.MethodGet, target: "/api-keys", key: created.Key,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"token without admin role": {
+			method: http.MethodGet, target: "/api-keys", token: sign(jwt.MapClaims{"sub": "bob"}),
+			expectedStatus: http.StatusForbidden,
+		},
+		"unknown scope": {
+			method: http.MethodPost, target: "/api-keys", token: admin, body: `{"owner":"payroll","scopes":["employees:delete"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rr := request(tc.method, tc.target, tc.token, tc.key, tc.body); rr.Code != tc.expectedStatus {
+				t.Errorf("expected %d, got %d %s", tc.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	rr = request(http.MethodGet, "/api-keys/"+created.ID, admin, "", "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"last_used_at"`) || strings.Contains(rr.Body.String(), created.Key) {
+		t.Errorf("expected the key with its last use and without secret, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := request(http.MethodPost, "/api-keys/"+created.ID+"/revoke", admin, "", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := request(http.MethodGet, "/employees", "", created.Key, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected the revoked key to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestSetUpRouter_Tenants(t *testing.T) {
+	mux := setUp(t, loadSpec(t))
+
+	sign := func(claims jwt.MapClaims) string {
+		t.Helper()
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	acme := sign(jwt.MapClaims{"sub": "ann", "tenant_id": "acme", "roles": []string{apikey.RoleAdmin, webhook.RoleAdmin}})
+	globex := sign(jwt.MapClaims{"sub": "ann", "tenant_id": "globex", "roles": []string{apikey.RoleAdmin}})
+	defaultTenant := sign(jwt.MapClaims{"sub": "ann", "roles": []string{apikey.RoleAdmin}})
+
+	request := func(method, target, token, key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		if key != "" {
+			r.Header.Set(middleware.APIKeyHeader, key)
+		}
+		if body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, r)
+		return rr
+	}
+
+	positionID := createdID(t, request(http.MethodPost, "/positions", acme, "", `{"name":"Engineer","salary":{"amount":15000,"currency":"USD"}}`))
+	employeeID := createdID(t, request(http.MethodPost, "/employees", acme, "", `{"firstname":"Ann","lastname":"Lee","position_id":"`+positionID+`"}`))
+	webhookID := createdID(t, request(http.MethodPost, "/webhooks", acme, "", `{"url":"https://example.com/hook","events":["employee.created"]}`))
+	apiKeyID := createdID(t, request(http.MethodPost, "/api-keys", acme, "", `{"owner":"payroll","scopes":["employees:read"]}`))
+
+	rr := request(http.MethodPost, "/api-keys", acme, "", `{"owner":"payroll","scopes":["employees:read"]}`)
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first read caches the employee, other tenants must not be served from the cache
+	if rr := request(http.MethodGet, "/employees/"+employeeID, acme, "", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected the employee of the tenant, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	tests := map[string]struct {
+		method, target, token, key, body string
+		expectedStatus                   int
+	}{
+		"get employee":               {method: http.MethodGet, target: "/employees/" + employeeID, token: globex, expectedStatus: http.StatusNotFound},
+		"get employee of v2":         {method: http.MethodGet, target: "/v2/employees/" + employeeID, token: globex, expectedStatus: http.StatusNotFound},
+		"get employee as default":    {method: http.MethodGet, target: "/employees/" + employeeID, token: defaultTenant, expectedStatus: http.StatusNotFound},
+		"get position":               {method: http.MethodGet, target: "/positions/" + positionID, token: globex, expectedStatus: http.StatusNotFound},
+		"update employee":            {method: http.MethodPut, target: "/employees/" + employeeID, token: globex, body: `{"firstname":"Bob","lastname":"Lee","position_id":"` + positionID + `"}`, expectedStatus: http.StatusConflict},
+		"delete employee":            {method: http.MethodDelete, target: "/employees/" + employeeID, token: globex, expectedStatus: http.StatusNotFound},
+		"delete position":            {method: http.MethodDelete, target: "/positions/" + positionID, token: globex, expectedStatus: http.StatusNotFound},
+		"create in other position":   {method: http.MethodPost, target: "/employees", token: globex, body: `{"firstname":"Bob","lastname":"Lee","position_id":"` + positionID + `"}`, expectedStatus: http.StatusConflict},
+		"get webhook":                {method: http.MethodGet, target: "/webhooks/" + webhookID, token: globex, expectedStatus: http.StatusNotFound},
+		"delete webhook":             {method: http.MethodDelete, target: "/webhooks/" + webhookID, token: globex, expectedStatus: http.StatusNotFound},
+		"get api key":                {method: http.MethodGet, target: "/api-keys/" + apiKeyID, token: globex, expectedStatus: http.StatusNotFound},
+		"revoke api key":             {method: http.MethodPost, target: "/api-keys/" + apiKeyID + "/revoke", token: globex, expectedStatus: http.StatusNotFound},
+		"get employee with api key":  {method: http.MethodGet, target: "/employees/" + employeeID, key: created.Key, expectedStatus: http.StatusOK},
+		"get employee of own tenant": {method: http.MethodGet, target: "/employees/" + employeeID, token: acme, expectedStatus: http.StatusOK},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rr := request(tc.method, tc.target, tc.token, tc.key, tc.body); rr.Code != tc.expectedStatus {
+				t.Errorf("expected %d, got %d %s", tc.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	for _, target := range []string{"/employees", "/positions", "/employees/search?q=ann", "/webhooks", "/api-keys"} {
+		if rr := request(http.MethodGet, target, globex, "", ""); rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+			t.Errorf("GET %s: expected no entities of the other tenant, got %d %s", target, rr.Code, rr.Body.String())
+		}
+		if rr := request(http.MethodGet, target, acme, "", ""); rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) == "[]" {
+			t.Errorf("GET %s: expected the entities of the tenant, got %d %s", target, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestSetUpRouter_VersionAliases(t *testing.T) {
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	mux := setUp(t, nil, func(config *conf.Config) {
+		config.RateLimit.Routes = map[string]ratelimit.Limit{"GET /employees": {Requests: 2, Period: time.Minute}}
+		config.Deprecations = map[string]middleware.Deprecation{
+			"GET /positions":    {Since: sunset.AddDate(-1, 0, 0), Sunset: sunset},
+			"GET /v2/positions": {Since: sunset.AddDate(-1, 0, 0), Sunset: sunset.AddDate(1, 0, 0)},
+		}
+	})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "ann"}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+		r.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, r)
+		return rr
+	}
+
+	// the override of the unversioned route limits its aliases, which share the bucket
+	for _, tc := range []struct {
+		target         string
+		expectedStatus int
+	}{
+		{target: "/employees", expectedStatus: http.StatusOK},
+		{target: "/v1/employees", expectedStatus: http.StatusOK},
+		{target: "/v2/employees", expectedStatus: http.StatusTooManyRequests},
+	} {
+		rr := request(tc.target)
+		if rr.Code != tc.expectedStatus {
+			t.Errorf("GET %s: expected %d, got %d", tc.target, tc.expectedStatus, rr.Code)
+		}
+		if limit := rr.Header().Get("RateLimit-Limit"); limit != "2" {
+			t.Errorf("GET %s: expected the limit of the route override, got %q", tc.target, limit)
+		}
+	}
+
+	tests := map[string]struct {
+		target string
+		sunset string
+	}{
+		"unversioned": {target: "/positions", sunset: sunset.Format(http.TimeFormat)},
+		"v1 alias":    {target: "/v1/positions", sunset: sunset.Format(http.TimeFormat)},
+		"v2 route":    {target: "/v2/positions", sunset: sunset.AddDate(1, 0, 0).Format(http.TimeFormat)},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := request(tc.target).Header().Get("Sunset"); got != tc.sunset {
+				t.Errorf("expected sunset %q, got %q", tc.sunset, got)
+			}
+		})
+	}
+}
+
+func TestSetUpGateway(t *testing.T) {
+	mux := &recordingMux{ServeMux: http.NewServeMux()}
+	gateway := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+	SetUpGateway(gateway, conf.Config{GatewayPrefix: "/gateway"}, mux, middleware.NewJWTAuth(middleware.JWTConfig{Secret: secret}, nil), apikey.NewStore())
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "ann"}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "authorized", authorization: "Bearer " + token, expectedStatus: http.StatusOK, expectedBody: "/v1/employees"},
+		{name: "unauthorized", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/gateway/v1/employees", nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, r)
+
+			if rr.Code != tc.expectedStatus {
+				t.Fatalf("expected %d, got %d %s", tc.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tc.expectedBody != "" && rr.Body.String() != tc.expectedBody {
+				t.Errorf("expected path %s, got %s", tc.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
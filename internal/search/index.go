@@ -0,0 +1,165 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	exactScore  = 1.0
+	prefixScore = 0.8
+	fuzzyScore  = 0.5
+)
+
+// Index is an inverted index of normalized tokens to document IDs.
+// It is not safe for concurrent use, callers are expected to hold their own lock.
+type Index struct {
+	postings map[string]map[string]struct{}
+	docs     map[string][]string
+	vocab    []string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]struct{}),
+		docs:     make(map[string][]string),
+	}
+}
+
+// Put indexes the document under the tokens of the given fields, replacing its previous tokens.
+func (idx *Index) Put(id string, fields ...string) {
+	idx.Remove(id)
+
+	tokens := make([]string, 0)
+	for _, field := range fields {
+		tokens = append(tokens, Tokenize(field)...)
+	}
+
+	for _, token := range tokens {
+		docs, ok := idx.postings[token]
+		if !ok {
+			docs = make(map[string]struct{})
+			idx.postings[token] = docs
+			idx.insertVocab(token)
+		}
+		docs[id] = struct{}{}
+	}
+	idx.docs[id] = tokens
+}
+
+func (idx *Index) Remove(id string) {
+	for _, token := range idx.docs[id] {
+		docs := idx.postings[token]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, token)
+			idx.removeVocab(token)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Match returns the documents matching a single normalized query token with the score of their best match:
+// exact tokens score highest, then tokens starting with the query, then tokens within a small edit distance.
+func (idx *Index) Match(query string) map[string]float64 {
+	scores := make(map[string]float64)
+	add := func(token string, score float64) {
+		for id := range idx.postings[token] {
+			if scores[id] < score {
+				scores[id] = score
+			}
+		}
+	}
+
+	add(query, exactScore)
+
+	for i := sort.SearchStrings(idx.vocab, query); i < len(idx.vocab) && strings.HasPrefix(idx.vocab[i], query); i++ {
+		add(idx.vocab[i], prefixScore)
+	}
+
+	if maxEdits := maxEdits(query); maxEdits > 0 {
+		for _, token := range idx.vocab {
+			if abs(len(token)-len(query)) <= maxEdits && levenshtein(token, query) <= maxEdits {
+				add(token, fuzzyScore)
+			}
+		}
+	}
+	return scores
+}
+
+// Tokenize splits text into lowercase tokens without diacritics.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Normalize lowercases the text and strips diacritics, so "Zoë" and "zoe" are equal.
+func Normalize(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, err := transform.String(t, text)
+	if err != nil {
+		normalized = text
+	}
+	return strings.ToLower(normalized)
+}
+
+func (idx *Index) insertVocab(token string) {
+	i := sort.SearchStrings(idx.vocab, token)
+	idx.vocab = append(idx.vocab, "")
+	copy(idx.vocab[i+1:], idx.vocab[i:])
+	idx.vocab[i] = token
+}
+
+func (idx *Index) removeVocab(token string) {
+	i := sort.SearchStrings(idx.vocab, token)
+	if i < len(idx.vocab) && idx.vocab[i] == token {
+		idx.vocab = append(idx.vocab[:i], idx.vocab[i+1:]...)
+	}
+}
+
+// maxEdits is the allowed edit distance for fuzzy matches, short tokens must match exactly or by prefix.
+func maxEdits(token string) int {
+	switch n := len([]rune(token)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIndex_Match(t *testing.T) {
+	idx := NewIndex()
+	idx.Put("1", "Zoë", "Müller")
+	idx.Put("2", "Zoey", "Miller")
+	idx.Put("3", "Alexander", "Smith")
+
+	tests := map[string]struct {
+		query    string
+		expected map[string]float64
+	}{
+		"exact and diacritics": {
+			query:    "zoe",
+			expected: map[string]float64{"1": exactScore, "2": prefixScore},
+		},
+		"prefix": {
+			query:    "alex",
+			expected: map[string]float64{"3": prefixScore},
+		},
+		"fuzzy": {
+			query:    "muller",
+			expected: map[string]float64{"1": exactScore, "2": fuzzyScore},
+		},
+		"no match": {
+			query:    "john",
+			expected: map[string]float64{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := idx.Match(tt.query); !reflect.DeepEqual(got, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIndex_PutRemove(t *testing.T) {
+	idx := NewIndex()
+	idx.Put("1", "John", "Smith")
+	idx.Put("1", "Jane", "Smith")
+
+	if got := idx.Match("john"); len(got) != 0 {
+		t.Fatalf("expected replaced tokens to be removed, got %v", got)
+	}
+	if got := idx.Match("jane"); got["1"] != exactScore {
+		t.Fatalf("expected exact match, got %v", got)
+	}
+
+	idx.Remove("1")
+	if got := idx.Match("smith"); len(got) != 0 {
+		t.Fatalf("expected removed document to be gone, got %v", got)
+	}
+	if len(idx.vocab) != 0 {
+		t.Fatalf("expected empty vocabulary, got %v", idx.vocab)
+	}
+}
@@ -0,0 +1,51 @@
+// Package tenant scopes the data of the API to the tenant of the caller, e.g. a subsidiary, so several tenants
+// can share one deployment.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// Default is the tenant of callers without tenant, such as tokens without the tenant claim. It keeps the data
+// and the keys of single tenant deployments as they were.
+const Default = ""
+
+// Metadata is the gRPC metadata key the in-process gateway passes the tenant of the HTTP request in.
+const Metadata = "x-tenant-id"
+
+var ErrInvalidTenant = errors.New("invalid tenant ID")
+
+// validID restricts tenant IDs to characters that can't break out of the key prefixes.
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type contextKey struct{}
+
+// Validate checks that id can be used as a tenant ID.
+func Validate(id string) error {
+	if !validID.MatchString(id) {
+		return ErrInvalidTenant
+	}
+	return nil
+}
+
+// With returns a copy of ctx carrying the tenant ID.
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// From returns the tenant ID of the context, or Default.
+func From(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Key prefixes a key of a shared store, such as Redis, with the tenant of the context, e.g. "tenant:acme:employee-1".
+// Keys of the default tenant are not prefixed.
+func Key(ctx context.Context, key string) string {
+	if id := From(ctx); id != Default {
+		return "tenant:" + id + ":" + key
+	}
+	return key
+}
@@ -0,0 +1,51 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	tests := map[string]struct {
+		ctx      context.Context
+		expected string
+	}{
+		"default tenant": {
+			ctx:      context.Background(),
+			expected: "employee-1",
+		},
+		"tenant": {
+			ctx:      With(context.Background(), "acme"),
+			expected: "tenant:acme:employee-1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Key(tc.ctx, "employee-1"); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		id       string
+		expected error
+	}{
+		"valid":     {id: "acme_eu-1"},
+		"empty":     {id: "", expected: ErrInvalidTenant},
+		"separator": {id: "acme:employee-1", expected: ErrInvalidTenant},
+		"too long":  {id: string(make([]byte, 65)), expected: ErrInvalidTenant},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := Validate(tc.id); !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
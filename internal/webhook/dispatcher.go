@@ -0,0 +1,306 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
+	"github.com/google/uuid"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	defaultMaxAttempts = 6
+	defaultBackoff     = time.Second
+	defaultMaxBackoff  = 5 * time.Minute
+	defaultTimeout     = 10 * time.Second
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrPrivateTarget fails deliveries to URLs resolving to private, loopback or link-local addresses.
+	ErrPrivateTarget = errors.New("webhook target is not a public address")
+)
+
+// Payload is the body of webhook requests.
+type Payload struct {
+	ID        string       `json:"id"`
+	Event     string       `json:"event"`
+	CreatedAt time.Time    `json:"created_at"`
+	Data      domain.Event `json:"data"`
+}
+
+// Dispatcher delivers the events of the bus to the matching subscriptions. Failed deliveries are retried
+// with exponential backoff and moved to the dead letter list after MaxAttempts attempts.
+type Dispatcher struct {
+	Store       *Store
+	Events      *events.Bus
+	Client      *http.Client
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it doubles for every further retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	mu sync.Mutex
+	// ctx is the context of Run, retries of deliveries stop when it is done.
+	ctx context.Context
+	wg  sync.WaitGroup
+}
+
+func NewDispatcher(store *Store, bus *events.Bus) *Dispatcher {
+	return &Dispatcher{
+		Store:       store,
+		Events:      bus,
+		Client:      &http.Client{Timeout: defaultTimeout, Transport: publicTransport()},
+		MaxAttempts: defaultMaxAttempts,
+		Backoff:     defaultBackoff,
+		MaxBackoff:  defaultMaxBackoff,
+	}
+}
+
+// publicTransport connects to public addresses only, it checks the resolved address of every connection, so
+// host names resolving to internal addresses and redirects to them are rejected too.
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: defaultTimeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateTarget, address)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// Run dispatches the events published from now on until ctx is done, then waits for pending deliveries to stop.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	d.mu.Lock()
+	d.ctx = ctx
+	d.mu.Unlock()
+	defer d.wg.Wait()
+
+	token := ""
+	for {
+		sub, err := d.subscribe(token)
+		if err != nil {
+			return fmt.Errorf("error to subscribe to events: %w", err)
+		}
+
+		token, err = d.dispatchAll(ctx, sub, token)
+		sub.Close()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !errors.Is(err, events.ErrLagged) {
+			return fmt.Errorf("error to receive events: %w", err)
+		}
+		log.Printf("webhook dispatcher fell behind, resuming after %s", token)
+	}
+}
+
+// subscribe subscribes to the events after the token. If they are no longer in the log, the gap is logged and the
+// subscription starts with new events, so the dispatcher keeps running.
+func (d *Dispatcher) subscribe(token string) (*events.Subscription, error) {
+	sub, err := d.Events.Subscribe(token, nil)
+	if errors.Is(err, events.ErrTokenExpired) {
+		log.Printf("webhook dispatcher missed the events after %s, resuming with new events", token)
+		return d.Events.Subscribe("", nil)
+	}
+	return sub, err
+}
+
+// dispatchAll dispatches the events of sub until it ends and returns the token of the last event.
+func (d *Dispatcher) dispatchAll(ctx context.Context, sub *events.Subscription, token string) (string, error) {
+	for {
+		event, err := sub.Next(ctx)
+		if err != nil {
+			return token, err
+		}
+		d.Dispatch(ctx, event)
+		token = event.Token
+	}
+}
+
+// Dispatch starts the deliveries of the event to every subscription of its tenant to one of its names.
+func (d *Dispatcher) Dispatch(ctx context.Context, event domain.Event) {
+	for _, name := range event.Names() {
+		for _, sub := range d.Store.Matching(event.TenantID, name) {
+			delivery := &Delivery{
+				ID:             uuid.New().String(),
+				SubscriptionID: sub.ID,
+				TenantID:       sub.TenantID,
+				Event:          name,
+				Status:         DeliveryPending,
+				CreatedAt:      time.Now().UTC(),
+			}
+			delivery.UpdatedAt = delivery.CreatedAt
+
+			payload, err := json.Marshal(Payload{ID: delivery.ID, Event: name, CreatedAt: event.Time, Data: event})
+			if err != nil {
+				log.Printf("error to marshal webhook payload of %s: %v", event.Token, err)
+				continue
+			}
+			delivery.Payload = payload
+
+			d.Store.addDelivery(delivery)
+			d.start(ctx, delivery, sub)
+		}
+	}
+}
+
+// Redeliver moves a delivery out of the dead letter list and delivers it again with fresh attempts.
+func (d *Dispatcher) Redeliver(id string) (Delivery, error) {
+	delivery, sub, err := d.Store.takeDeadLetter(id)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	d.mu.Lock()
+	ctx := d.ctx
+	d.mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	queued := *delivery
+	d.start(ctx, delivery, sub)
+	return queued, nil
+}
+
+func (d *Dispatcher) start(ctx context.Context, delivery *Delivery, sub Subscription) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(ctx, delivery, sub)
+	}()
+}
+
+// deliver attempts the delivery until it succeeds, runs out of attempts, the subscription is removed
+// or deactivated, or ctx is done.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *Delivery, sub Subscription) {
+	for attempt := 1; ; attempt++ {
+		if !d.Store.active(sub.ID) {
+			return
+		}
+
+		responseStatus, err := d.send(ctx, delivery, sub)
+		switch {
+		case err == nil:
+			d.Store.recordAttempt(delivery, DeliverySucceeded, responseStatus, nil)
+			return
+		case attempt >= d.MaxAttempts:
+			d.Store.recordAttempt(delivery, DeliveryFailed, responseStatus, err)
+			log.Printf("webhook delivery %s to %s failed after %d attempts: %v", delivery.ID, sub.URL, attempt, err)
+			return
+		}
+		d.Store.recordAttempt(delivery, DeliveryPending, responseStatus, err)
+
+		timer := time.NewTimer(d.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// backoff returns the delay after the given attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.Backoff
+	for i := 1; i < attempt && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.MaxBackoff)
+}
+
+// send posts the payload of the delivery, responses other than 2xx are errors.
+func (d *Dispatcher) send(ctx context.Context, delivery *Delivery, sub Subscription) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, time.Now(), delivery.Payload))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header of the payload, t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<payload>">.
+func Sign(secret string, t time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + signature(secret, timestamp, payload)
+}
+
+// Verify checks the signature header of a payload, signatures older than tolerance are rejected.
+// Receivers written in Go can use it to authenticate webhook requests.
+func Verify(secret, header string, payload []byte, tolerance time.Duration) error {
+	var timestamp, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			sig = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+		return fmt.Errorf("%w: timestamp too old", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, timestamp, payload))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,348 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/dilyara4949/employees-api/internal/events"
+)
+
+// receiver is a local webhook endpoint answering with the given statuses in turn, the last one repeating.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+
+	status := rc.statuses[0]
+	if len(rc.statuses) > 1 {
+		rc.statuses = rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+func newTestDispatcher(t *testing.T, statuses ...int) (*Dispatcher, *receiver, Subscription) {
+	t.Helper()
+
+	rc := &receiver{statuses: statuses}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	// loopback targets are rejected, the subscription gets a public URL and the client connects to srv instead
+	store := NewStore()
+	sub := Subscription{URL: "http://payroll.example.com/hook", Events: []string{"employee.created", domain.EventEmployeePositionChanged}, Active: true}
+	if err := store.Create(&sub); err != nil {
+		t.Fatalf("error creating subscription: %v", err)
+	}
+
+	d := NewDispatcher(store, events.NewBus(10))
+	d.Client = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}}
+	d.MaxAttempts = 3
+	d.Backoff = time.Millisecond
+	d.MaxBackoff = 4 * time.Millisecond
+	return d, rc, sub
+}
+
+func employeeEvent(eventType domain.EventType, positionID, previousPositionID string) domain.Event {
+	event := domain.Event{
+		Token:    "epoch.1",
+		Type:     eventType,
+		Entity:   domain.EntityEmployee,
+		EntityID: "1",
+		Employee: &domain.Employee{ID: "1", FirstName: "Ann", LastName: "Lee", PositionID: positionID},
+		Time:     time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if previousPositionID != "" {
+		event.PreviousEmployee = &domain.Employee{ID: "1", FirstName: "Ann", LastName: "Lee", PositionID: previousPositionID}
+	}
+	return event
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	d, rc, sub := newTestDispatcher(t, http.StatusOK)
+
+	d.Dispatch(context.Background(), employeeEvent(domain.EventCreated, "p1", ""))
+	d.wg.Wait()
+
+	if rc.count() != 1 {
+		t.Fatalf("expected 1 request, got %d", rc.count())
+	}
+
+	req, body := rc.requests[0], rc.bodies[0]
+	if err := Verify(sub.Secret, req.Header.Get(SignatureHeader), body, time.Minute); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+	if err := Verify("other secret", req.Header.Get(SignatureHeader), body, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected invalid signature for other secret, got %v", err)
+	}
+	if got := req.Header.Get(EventHeader); got != "employee.created" {
+		t.Errorf("expected event header employee.created, got %q", got)
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("error unmarshal payload: %v", err)
+	}
+	if payload.Event != "employee.created" || payload.Data.Employee == nil || payload.Data.Employee.ID != "1" {
+		t.Errorf("unexpected payload %s", body)
+	}
+
+	deliveries, err := d.Store.Deliveries(sub.ID)
+	if err != nil {
+		t.Fatalf("error getting deliveries: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Status != DeliverySucceeded || deliveries[0].Attempts != 1 || deliveries[0].ID != payload.ID {
+		t.Errorf("unexpected deliveries %+v", deliveries)
+	}
+}
+
+func TestDispatcher_SubscribeAfterExpiredToken(t *testing.T) {
+	bus := events.NewBus(1)
+	d := NewDispatcher(NewStore(), bus)
+
+	lagging, err := bus.Subscribe("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lagging.Close()
+
+	bus.Publish(employeeEvent(domain.EventCreated, "p1", ""))
+	last, err := lagging.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish(employeeEvent(domain.EventUpdated, "p1", ""))
+	bus.Publish(employeeEvent(domain.EventUpdated, "p2", "p1"))
+
+	if _, err := bus.Subscribe(last.Token, nil); !errors.Is(err, events.ErrTokenExpired) {
+		t.Fatalf("expected the token to expire, got %v", err)
+	}
+
+	sub, err := d.subscribe(last.Token)
+	if err != nil {
+		t.Fatalf("expected a subscription to new events, got %v", err)
+	}
+	defer sub.Close()
+
+	bus.Publish(employeeEvent(domain.EventDeleted, "p2", ""))
+	event, err := sub.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != domain.EventDeleted {
+		t.Errorf("expected the subscription to start with new events, got %s", event.Type)
+	}
+}
+
+func TestDispatcher_DispatchTenant(t *testing.T) {
+	d, rc, _ := newTestDispatcher(t, http.StatusOK)
+
+	event := employeeEvent(domain.EventCreated, "p1", "")
+	event.TenantID = "acme"
+	d.Dispatch(context.Background(), event)
+	d.wg.Wait()
+
+	if rc.count() != 0 {
+		t.Errorf("expected no delivery of the events of another tenant, got %d", rc.count())
+	}
+}
+
+func TestDispatcher_DispatchMatchesEventNames(t *testing.T) {
+	tests := map[string]struct {
+		event domain.Event
+		want  []string
+	}{
+		"position changed": {
+			event: employeeEvent(domain.EventUpdated, "p2", "p1"),
+			want:  []string{domain.EventEmployeePositionChanged},
+		},
+		"same position": {
+			event: employeeEvent(domain.EventUpdated, "p1", "p1"),
+		},
+		"deleted": {
+			event: employeeEvent(domain.EventDeleted, "p1", ""),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, rc, _ := newTestDispatcher(t, http.StatusOK)
+
+			d.Dispatch(context.Background(), tc.event)
+			d.wg.Wait()
+
+			if rc.count() != len(tc.want) {
+				t.Fatalf("expected %d requests, got %d", len(tc.want), rc.count())
+			}
+			for i, want := range tc.want {
+				if got := rc.requests[i].Header.Get(EventHeader); got != want {
+					t.Errorf("expected event %q, got %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	d, rc, sub := newTestDispatcher(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusNoContent)
+
+	d.Dispatch(context.Background(), employeeEvent(domain.EventCreated, "p1", ""))
+	d.wg.Wait()
+
+	if rc.count() != 3 {
+		t.Fatalf("expected 3 requests, got %d", rc.count())
+	}
+	if rc.requests[0].Header.Get(DeliveryHeader) != rc.requests[2].Header.Get(DeliveryHeader) {
+		t.Errorf("expected retries to keep the delivery ID")
+	}
+
+	deliveries, _ := d.Store.Deliveries(sub.ID)
+	if len(deliveries) != 1 || deliveries[0].Status != DeliverySucceeded || deliveries[0].Attempts != 3 || deliveries[0].LastError != "" {
+		t.Errorf("unexpected deliveries %+v", deliveries)
+	}
+	if dead := d.Store.DeadLetters(); len(dead) != 0 {
+		t.Errorf("expected no dead letters, got %+v", dead)
+	}
+}
+
+func TestDispatcher_DeadLetter(t *testing.T) {
+	d, rc, sub := newTestDispatcher(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK)
+
+	d.Dispatch(context.Background(), employeeEvent(domain.EventCreated, "p1", ""))
+	d.wg.Wait()
+
+	dead := d.Store.DeadLetters()
+	if len(dead) != 1 || dead[0].Status != DeliveryFailed || dead[0].Attempts != 3 || dead[0].ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("unexpected dead letters %+v", dead)
+	}
+
+	if _, err := d.Redeliver("unknown"); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Errorf("expected ErrDeliveryNotFound, got %v", err)
+	}
+	if _, err := d.Redeliver(dead[0].ID); err != nil {
+		t.Fatalf("error redelivering: %v", err)
+	}
+	d.wg.Wait()
+
+	if rc.count() != 4 {
+		t.Errorf("expected 4 requests, got %d", rc.count())
+	}
+	if dead := d.Store.DeadLetters(); len(dead) != 0 {
+		t.Errorf("expected no dead letters, got %+v", dead)
+	}
+	deliveries, _ := d.Store.Deliveries(sub.ID)
+	if len(deliveries) != 1 || deliveries[0].Status != DeliverySucceeded || deliveries[0].Attempts != 4 {
+		t.Errorf("unexpected deliveries %+v", deliveries)
+	}
+}
+
+func TestDispatcher_StopsForInactiveSubscription(t *testing.T) {
+	d, rc, sub := newTestDispatcher(t, http.StatusOK)
+
+	sub.Active = false
+	if err := d.Store.Update(&sub); err != nil {
+		t.Fatalf("error updating subscription: %v", err)
+	}
+
+	d.Dispatch(context.Background(), employeeEvent(domain.EventCreated, "p1", ""))
+	d.wg.Wait()
+
+	if rc.count() != 0 {
+		t.Errorf("expected no requests, got %d", rc.count())
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := &Dispatcher{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := d.backoff(attempt); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+}
+
+func TestDispatcher_RejectsPrivateTarget(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusOK}}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	d := NewDispatcher(NewStore(), events.NewBus(10))
+
+	// the subscriptions bypass validation, as host names are only resolved when connecting
+	targets := map[string]string{
+		"loopback address":        srv.URL,
+		"host name of a loopback": strings.Replace(srv.URL, "127.0.0.1", "localhost", 1),
+	}
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			_, err := d.send(context.Background(), &Delivery{ID: "1", Event: "employee.created"}, Subscription{URL: target})
+			if !errors.Is(err, ErrPrivateTarget) {
+				t.Errorf("expected ErrPrivateTarget, got %v", err)
+			}
+		})
+	}
+
+	if rc.count() != 0 {
+		t.Errorf("expected no request to reach the private target, got %d", rc.count())
+	}
+}
+
+func TestSubscription_Validate(t *testing.T) {
+	tests := map[string]struct {
+		sub     Subscription
+		wantErr bool
+	}{
+		"valid":         {sub: Subscription{URL: "https://payroll.example.com/hook", Events: []string{"employee.created"}}},
+		"relative url":  {sub: Subscription{URL: "/hook", Events: []string{"employee.created"}}, wantErr: true},
+		"ftp url":       {sub: Subscription{URL: "ftp://example.com", Events: []string{"employee.created"}}, wantErr: true},
+		"no events":     {sub: Subscription{URL: "https://example.com"}, wantErr: true},
+		"unknown event": {sub: Subscription{URL: "https://example.com", Events: []string{"employee.hired"}}, wantErr: true},
+		"loopback":      {sub: Subscription{URL: "http://127.0.0.1:8080/hook", Events: []string{"employee.created"}}, wantErr: true},
+		"localhost":     {sub: Subscription{URL: "http://localhost/hook", Events: []string{"employee.created"}}, wantErr: true},
+		"private":       {sub: Subscription{URL: "https://10.0.0.5/hook", Events: []string{"employee.created"}}, wantErr: true},
+		"link-local":    {sub: Subscription{URL: "http://169.254.169.254/latest/meta-data", Events: []string{"employee.created"}}, wantErr: true},
+		"ipv6 loopback": {sub: Subscription{URL: "http://[::1]/hook", Events: []string{"employee.created"}}, wantErr: true},
+		"mapped ipv6":   {sub: Subscription{URL: "http://[::ffff:192.168.1.1]/hook", Events: []string{"employee.created"}}, wantErr: true},
+		"public ip":     {sub: Subscription{URL: "https://203.0.113.10/hook", Events: []string{"employee.created"}}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.sub.Validate()
+			if tc.wantErr != errors.Is(err, ErrInvalidSubscription) {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
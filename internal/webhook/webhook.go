@@ -0,0 +1,344 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dilyara4949/employees-api/internal/domain"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidSubscription  = errors.New("invalid webhook subscription")
+)
+
+// Events are the event names subscriptions can receive.
+var Events = []string{
+	"employee.created",
+	"employee.updated",
+	domain.EventEmployeePositionChanged,
+	"employee.deleted",
+	"position.created",
+	"position.updated",
+	domain.EventPositionSalaryChanged,
+	"position.deleted",
+}
+
+// RoleAdmin is the JWT role allowed to register webhooks, API keys need the webhooks:write scope.
+const RoleAdmin = "webhook_admin"
+
+const (
+	// historySize is the number of deliveries kept per subscription.
+	historySize = 100
+	// deadLetterSize is the number of failed deliveries kept in the dead letter list.
+	deadLetterSize = 1000
+)
+
+// Subscription receives the events it subscribed to as POST requests to URL, signed with Secret.
+type Subscription struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret signs the payloads, it is generated if empty and only returned when the subscription is created.
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	// TenantID is the tenant whose events the subscription receives.
+	TenantID string `json:"-"`
+}
+
+// Validate checks the URL and the event names of the subscription. URLs of private, loopback and link-local
+// addresses are rejected, host names resolving to them are rejected by the dispatcher when it connects.
+func (s Subscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidSubscription)
+	}
+	if !publicHost(u.Hostname()) {
+		return fmt.Errorf("%w: url must not target a private, loopback or link-local address", ErrInvalidSubscription)
+	}
+	if len(s.Events) == 0 {
+		return fmt.Errorf("%w: events are required", ErrInvalidSubscription)
+	}
+	for _, event := range s.Events {
+		if !slices.Contains(Events, event) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidSubscription, event)
+		}
+	}
+	return nil
+}
+
+// publicHost reports whether the host isn't localhost or an IP address outside of the public internet.
+func publicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	return err != nil || publicAddr(addr)
+}
+
+// publicAddr reports whether webhooks may be delivered to the address.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
+
+// Subscribed reports whether the subscription receives events named name of the tenant.
+func (s Subscription) Subscribed(tenantID, name string) bool {
+	return s.Active && s.TenantID == tenantID && slices.Contains(s.Events, name)
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed deliveries used up their attempts and are in the dead letter list.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is the delivery of an event to a subscription.
+type Delivery struct {
+	ID             string         `json:"id"`
+	SubscriptionID string         `json:"subscription_id"`
+	Event          string         `json:"event"`
+	Payload        []byte         `json:"-"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	ResponseStatus int            `json:"response_status,omitempty"`
+	LastError      string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	// TenantID is the tenant of the subscription, dead letters are kept after their subscription is deleted.
+	TenantID string `json:"-"`
+}
+
+// Store keeps the subscriptions, their delivery history and the dead letter list in memory.
+type Store struct {
+	mu            sync.RWMutex
+	subscriptions map[string]Subscription
+	// deliveries holds the last deliveries of every subscription, oldest first.
+	deliveries  map[string][]*Delivery
+	deadLetters []*Delivery
+}
+
+func NewStore() *Store {
+	return &Store{
+		subscriptions: make(map[string]Subscription),
+		deliveries:    make(map[string][]*Delivery),
+	}
+}
+
+// Create validates and stores the subscription, generating its ID and, if empty, its secret.
+func (s *Store) Create(sub *Subscription) error {
+	if err := sub.Validate(); err != nil {
+		return err
+	}
+	if sub.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return fmt.Errorf("error to create webhook subscription: %w", err)
+		}
+		sub.Secret = secret
+	}
+	sub.ID = uuid.New().String()
+	sub.CreatedAt = time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions[sub.ID] = *sub
+	return nil
+}
+
+// Get returns the subscription including its secret.
+func (s *Store) Get(id string) (Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+// Update replaces the URL, events and active flag of the subscription, the secret is kept if sub has none
+// and the tenant is kept.
+func (s *Store) Update(sub *Subscription) error {
+	if err := sub.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.subscriptions[sub.ID]
+	if !ok {
+		return ErrSubscriptionNotFound
+	}
+	if sub.Secret == "" {
+		sub.Secret = current.Secret
+	}
+	sub.CreatedAt = current.CreatedAt
+	sub.TenantID = current.TenantID
+
+	s.subscriptions[sub.ID] = *sub
+	return nil
+}
+
+// Delete removes the subscription and its history, its pending deliveries are not retried.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscriptions[id]; !ok {
+		return ErrSubscriptionNotFound
+	}
+	delete(s.subscriptions, id)
+	delete(s.deliveries, id)
+	return nil
+}
+
+// GetAll returns the subscriptions ordered by creation time.
+func (s *Store) GetAll() []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subs := make([]Subscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+	})
+	return subs
+}
+
+// Matching returns the subscriptions receiving events named name of the tenant.
+func (s *Store) Matching(tenantID, name string) []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subs := make([]Subscription, 0)
+	for _, sub := range s.subscriptions {
+		if sub.Subscribed(tenantID, name) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// Deliveries returns the delivery history of the subscription, newest first.
+func (s *Store) Deliveries(subscriptionID string) ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.subscriptions[subscriptionID]; !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	history := s.deliveries[subscriptionID]
+	deliveries := make([]Delivery, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, *history[i])
+	}
+	return deliveries, nil
+}
+
+// DeadLetters returns the deliveries that failed all their attempts, newest first.
+func (s *Store) DeadLetters() []Delivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := make([]Delivery, 0, len(s.deadLetters))
+	for i := len(s.deadLetters) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, *s.deadLetters[i])
+	}
+	return deliveries
+}
+
+// addDelivery adds a pending delivery to the history of its subscription.
+func (s *Store) addDelivery(delivery *Delivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := append(s.deliveries[delivery.SubscriptionID], delivery)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	s.deliveries[delivery.SubscriptionID] = history
+}
+
+// recordAttempt updates the delivery after an attempt, a failed delivery is moved to the dead letter list.
+func (s *Store) recordAttempt(delivery *Delivery, status DeliveryStatus, responseStatus int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery.Attempts++
+	delivery.Status = status
+	delivery.ResponseStatus = responseStatus
+	delivery.LastError = ""
+	if err != nil {
+		delivery.LastError = err.Error()
+	}
+	delivery.UpdatedAt = time.Now().UTC()
+
+	if status == DeliveryFailed {
+		s.deadLetters = append(s.deadLetters, delivery)
+		if len(s.deadLetters) > deadLetterSize {
+			s.deadLetters = s.deadLetters[len(s.deadLetters)-deadLetterSize:]
+		}
+	}
+}
+
+// takeDeadLetter removes the delivery from the dead letter list and marks it pending again.
+func (s *Store) takeDeadLetter(id string) (*Delivery, Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, delivery := range s.deadLetters {
+		if delivery.ID != id {
+			continue
+		}
+
+		sub, ok := s.subscriptions[delivery.SubscriptionID]
+		if !ok {
+			return nil, Subscription{}, ErrSubscriptionNotFound
+		}
+
+		s.deadLetters = slices.Delete(s.deadLetters, i, i+1)
+		delivery.Status = DeliveryPending
+		delivery.UpdatedAt = time.Now().UTC()
+		return delivery, sub, nil
+	}
+	return nil, Subscription{}, ErrDeliveryNotFound
+}
+
+// active reports whether the subscription still exists and is active, deliveries stop retrying otherwise.
+func (s *Store) active(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.subscriptions[id].Active
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
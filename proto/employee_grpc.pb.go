@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.4.0
+// - protoc-gen-go-grpc v1.5.1
 // - protoc             v5.27.0
 // source: employee.proto
 
@@ -15,15 +15,22 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.62.0 or later.
-const _ = grpc.SupportPackageIsVersion8
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
 
 const (
-	EmployeeService_Get_FullMethodName    = "/employees_api.proto.EmployeeService/Get"
-	EmployeeService_GetAll_FullMethodName = "/employees_api.proto.EmployeeService/GetAll"
-	EmployeeService_Create_FullMethodName = "/employees_api.proto.EmployeeService/Create"
-	EmployeeService_Update_FullMethodName = "/employees_api.proto.EmployeeService/Update"
-	EmployeeService_Delete_FullMethodName = "/employees_api.proto.EmployeeService/Delete"
+	EmployeeService_Get_FullMethodName             = "/employees_api.proto.EmployeeService/Get"
+	EmployeeService_GetAll_FullMethodName          = "/employees_api.proto.EmployeeService/GetAll"
+	EmployeeService_Create_FullMethodName          = "/employees_api.proto.EmployeeService/Create"
+	EmployeeService_Update_FullMethodName          = "/employees_api.proto.EmployeeService/Update"
+	EmployeeService_Delete_FullMethodName          = "/employees_api.proto.EmployeeService/Delete"
+	EmployeeService_Search_FullMethodName          = "/employees_api.proto.EmployeeService/Search"
+	EmployeeService_ImportEmployees_FullMethodName = "/employees_api.proto.EmployeeService/ImportEmployees"
+	EmployeeService_Export_FullMethodName          = "/employees_api.proto.EmployeeService/Export"
+	EmployeeService_BatchCreate_FullMethodName     = "/employees_api.proto.EmployeeService/BatchCreate"
+	EmployeeService_BatchUpdate_FullMethodName     = "/employees_api.proto.EmployeeService/BatchUpdate"
+	EmployeeService_BatchDelete_FullMethodName     = "/employees_api.proto.EmployeeService/BatchDelete"
+	EmployeeService_Watch_FullMethodName           = "/employees_api.proto.EmployeeService/Watch"
 )
 
 // EmployeeServiceClient is the client API for EmployeeService service.
@@ -35,6 +42,17 @@ type EmployeeServiceClient interface {
 	Create(ctx context.Context, in *Employee, opts ...grpc.CallOption) (*Employee, error)
 	Update(ctx context.Context, in *Employee, opts ...grpc.CallOption) (*Employee, error)
 	Delete(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Status, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// ImportEmployees expects the options in the first message followed by the rows.
+	// Over HTTP the messages are sent as newline delimited JSON.
+	ImportEmployees(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportEmployeesRequest, ImportResponse], error)
+	// Export streams the employees encoded in the requested format in chunks.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error)
+	BatchCreate(ctx context.Context, in *BatchEmployeesRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchUpdate(ctx context.Context, in *BatchEmployeesRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// Watch streams employee changes, starting after resume_token if it is set.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[EmployeeEvent], error)
 }
 
 type employeeServiceClient struct {
@@ -95,21 +113,126 @@ func (c *employeeServiceClient) Delete(ctx context.Context, in *Id, opts ...grpc
 	return out, nil
 }
 
+func (c *employeeServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, EmployeeService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *employeeServiceClient) ImportEmployees(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportEmployeesRequest, ImportResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EmployeeService_ServiceDesc.Streams[0], EmployeeService_ImportEmployees_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportEmployeesRequest, ImportResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EmployeeService_ImportEmployeesClient = grpc.ClientStreamingClient[ImportEmployeesRequest, ImportResponse]
+
+func (c *employeeServiceClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EmployeeService_ServiceDesc.Streams[1], EmployeeService_Export_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportRequest, ExportChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EmployeeService_ExportClient = grpc.ServerStreamingClient[ExportChunk]
+
+func (c *employeeServiceClient) BatchCreate(ctx context.Context, in *BatchEmployeesRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, EmployeeService_BatchCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *employeeServiceClient) BatchUpdate(ctx context.Context, in *BatchEmployeesRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, EmployeeService_BatchUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *employeeServiceClient) BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, EmployeeService_BatchDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *employeeServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[EmployeeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EmployeeService_ServiceDesc.Streams[2], EmployeeService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, EmployeeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EmployeeService_WatchClient = grpc.ServerStreamingClient[EmployeeEvent]
+
 // EmployeeServiceServer is the server API for EmployeeService service.
 // All implementations must embed UnimplementedEmployeeServiceServer
-// for forward compatibility
+// for forward compatibility.
 type EmployeeServiceServer interface {
 	Get(context.Context, *Id) (*Employee, error)
 	GetAll(context.Context, *Empty) (*EmployeesList, error)
 	Create(context.Context, *Employee) (*Employee, error)
 	Update(context.Context, *Employee) (*Employee, error)
 	Delete(context.Context, *Id) (*Status, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// ImportEmployees expects the options in the first message followed by the rows.
+	// Over HTTP the messages are sent as newline delimited JSON.
+	ImportEmployees(grpc.ClientStreamingServer[ImportEmployeesRequest, ImportResponse]) error
+	// Export streams the employees encoded in the requested format in chunks.
+	Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error
+	BatchCreate(context.Context, *BatchEmployeesRequest) (*BatchResponse, error)
+	BatchUpdate(context.Context, *BatchEmployeesRequest) (*BatchResponse, error)
+	BatchDelete(context.Context, *BatchDeleteRequest) (*BatchResponse, error)
+	// Watch streams employee changes, starting after resume_token if it is set.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[EmployeeEvent]) error
 	mustEmbedUnimplementedEmployeeServiceServer()
 }
 
-// UnimplementedEmployeeServiceServer must be embedded to have forward compatible implementations.
-type UnimplementedEmployeeServiceServer struct {
-}
+// UnimplementedEmployeeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEmployeeServiceServer struct{}
 
 func (UnimplementedEmployeeServiceServer) Get(context.Context, *Id) (*Employee, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
@@ -126,7 +249,29 @@ func (UnimplementedEmployeeServiceServer) Update(context.Context, *Employee) (*E
 func (UnimplementedEmployeeServiceServer) Delete(context.Context, *Id) (*Status, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedEmployeeServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedEmployeeServiceServer) ImportEmployees(grpc.ClientStreamingServer[ImportEmployeesRequest, ImportResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportEmployees not implemented")
+}
+func (UnimplementedEmployeeServiceServer) Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedEmployeeServiceServer) BatchCreate(context.Context, *BatchEmployeesRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreate not implemented")
+}
+func (UnimplementedEmployeeServiceServer) BatchUpdate(context.Context, *BatchEmployeesRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchUpdate not implemented")
+}
+func (UnimplementedEmployeeServiceServer) BatchDelete(context.Context, *BatchDeleteRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDelete not implemented")
+}
+func (UnimplementedEmployeeServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[EmployeeEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
 func (UnimplementedEmployeeServiceServer) mustEmbedUnimplementedEmployeeServiceServer() {}
+func (UnimplementedEmployeeServiceServer) testEmbeddedByValue()                         {}
 
 // UnsafeEmployeeServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to EmployeeServiceServer will
@@ -136,6 +281,13 @@ type UnsafeEmployeeServiceServer interface {
 }
 
 func RegisterEmployeeServiceServer(s grpc.ServiceRegistrar, srv EmployeeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedEmployeeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&EmployeeService_ServiceDesc, srv)
 }
 
@@ -229,6 +381,107 @@ func _EmployeeService_Delete_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EmployeeService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmployeeServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmployeeService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmployeeServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmployeeService_ImportEmployees_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EmployeeServiceServer).ImportEmployees(&grpc.GenericServerStream[ImportEmployeesRequest, ImportResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EmployeeService_ImportEmployeesServer = grpc.ClientStreamingServer[ImportEmployeesRequest, ImportResponse]
+
+func _EmployeeService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EmployeeServiceServer).Export(m, &grpc.GenericServerStream[ExportRequest, ExportChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EmployeeService_ExportServer = grpc.ServerStreamingServer[ExportChunk]
+
+func _EmployeeService_BatchCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchEmployeesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmployeeServiceServer).BatchCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmployeeService_BatchCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmployeeServiceServer).BatchCreate(ctx, req.(*BatchEmployeesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmployeeService_BatchUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchEmployeesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmployeeServiceServer).BatchUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmployeeService_BatchUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmployeeServiceServer).BatchUpdate(ctx, req.(*BatchEmployeesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmployeeService_BatchDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmployeeServiceServer).BatchDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmployeeService_BatchDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmployeeServiceServer).BatchDelete(ctx, req.(*BatchDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmployeeService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EmployeeServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, EmployeeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EmployeeService_WatchServer = grpc.ServerStreamingServer[EmployeeEvent]
+
 // EmployeeService_ServiceDesc is the grpc.ServiceDesc for EmployeeService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -256,7 +509,39 @@ var EmployeeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _EmployeeService_Delete_Handler,
 		},
+		{
+			MethodName: "Search",
+			Handler:    _EmployeeService_Search_Handler,
+		},
+		{
+			MethodName: "BatchCreate",
+			Handler:    _EmployeeService_BatchCreate_Handler,
+		},
+		{
+			MethodName: "BatchUpdate",
+			Handler:    _EmployeeService_BatchUpdate_Handler,
+		},
+		{
+			MethodName: "BatchDelete",
+			Handler:    _EmployeeService_BatchDelete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportEmployees",
+			Handler:       _EmployeeService_ImportEmployees_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _EmployeeService_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _EmployeeService_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "employee.proto",
 }
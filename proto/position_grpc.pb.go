@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.4.0
+// - protoc-gen-go-grpc v1.5.1
 // - protoc             v5.27.0
 // source: position.proto
 
@@ -15,15 +15,20 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.62.0 or later.
-const _ = grpc.SupportPackageIsVersion8
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PositionService_Get_FullMethodName    = "/employees_api.proto.PositionService/Get"
-	PositionService_GetAll_FullMethodName = "/employees_api.proto.PositionService/GetAll"
-	PositionService_Create_FullMethodName = "/employees_api.proto.PositionService/Create"
-	PositionService_Update_FullMethodName = "/employees_api.proto.PositionService/Update"
-	PositionService_Delete_FullMethodName = "/employees_api.proto.PositionService/Delete"
+	PositionService_Get_FullMethodName         = "/employees_api.proto.PositionService/Get"
+	PositionService_GetAll_FullMethodName      = "/employees_api.proto.PositionService/GetAll"
+	PositionService_Create_FullMethodName      = "/employees_api.proto.PositionService/Create"
+	PositionService_Update_FullMethodName      = "/employees_api.proto.PositionService/Update"
+	PositionService_Delete_FullMethodName      = "/employees_api.proto.PositionService/Delete"
+	PositionService_Export_FullMethodName      = "/employees_api.proto.PositionService/Export"
+	PositionService_BatchCreate_FullMethodName = "/employees_api.proto.PositionService/BatchCreate"
+	PositionService_BatchUpdate_FullMethodName = "/employees_api.proto.PositionService/BatchUpdate"
+	PositionService_BatchDelete_FullMethodName = "/employees_api.proto.PositionService/BatchDelete"
+	PositionService_Watch_FullMethodName       = "/employees_api.proto.PositionService/Watch"
 )
 
 // PositionServiceClient is the client API for PositionService service.
@@ -35,6 +40,13 @@ type PositionServiceClient interface {
 	Create(ctx context.Context, in *Position, opts ...grpc.CallOption) (*Position, error)
 	Update(ctx context.Context, in *Position, opts ...grpc.CallOption) (*Position, error)
 	Delete(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Status, error)
+	// Export streams the positions encoded in the requested format in chunks.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error)
+	BatchCreate(ctx context.Context, in *BatchPositionsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchUpdate(ctx context.Context, in *BatchPositionsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// Watch streams position changes, starting after resume_token if it is set.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PositionEvent], error)
 }
 
 type positionServiceClient struct {
@@ -95,21 +107,99 @@ func (c *positionServiceClient) Delete(ctx context.Context, in *Id, opts ...grpc
 	return out, nil
 }
 
+func (c *positionServiceClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PositionService_ServiceDesc.Streams[0], PositionService_Export_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportRequest, ExportChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PositionService_ExportClient = grpc.ServerStreamingClient[ExportChunk]
+
+func (c *positionServiceClient) BatchCreate(ctx context.Context, in *BatchPositionsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, PositionService_BatchCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *positionServiceClient) BatchUpdate(ctx context.Context, in *BatchPositionsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, PositionService_BatchUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *positionServiceClient) BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, PositionService_BatchDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *positionServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PositionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PositionService_ServiceDesc.Streams[1], PositionService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, PositionEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PositionService_WatchClient = grpc.ServerStreamingClient[PositionEvent]
+
 // PositionServiceServer is the server API for PositionService service.
 // All implementations must embed UnimplementedPositionServiceServer
-// for forward compatibility
+// for forward compatibility.
 type PositionServiceServer interface {
 	Get(context.Context, *Id) (*Position, error)
 	GetAll(context.Context, *Empty) (*PositionsList, error)
 	Create(context.Context, *Position) (*Position, error)
 	Update(context.Context, *Position) (*Position, error)
 	Delete(context.Context, *Id) (*Status, error)
+	// Export streams the positions encoded in the requested format in chunks.
+	Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error
+	BatchCreate(context.Context, *BatchPositionsRequest) (*BatchResponse, error)
+	BatchUpdate(context.Context, *BatchPositionsRequest) (*BatchResponse, error)
+	BatchDelete(context.Context, *BatchDeleteRequest) (*BatchResponse, error)
+	// Watch streams position changes, starting after resume_token if it is set.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[PositionEvent]) error
 	mustEmbedUnimplementedPositionServiceServer()
 }
 
-// UnimplementedPositionServiceServer must be embedded to have forward compatible implementations.
-type UnimplementedPositionServiceServer struct {
-}
+// UnimplementedPositionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPositionServiceServer struct{}
 
 func (UnimplementedPositionServiceServer) Get(context.Context, *Id) (*Position, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
@@ -126,7 +216,23 @@ func (UnimplementedPositionServiceServer) Update(context.Context, *Position) (*P
 func (UnimplementedPositionServiceServer) Delete(context.Context, *Id) (*Status, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedPositionServiceServer) Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedPositionServiceServer) BatchCreate(context.Context, *BatchPositionsRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreate not implemented")
+}
+func (UnimplementedPositionServiceServer) BatchUpdate(context.Context, *BatchPositionsRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchUpdate not implemented")
+}
+func (UnimplementedPositionServiceServer) BatchDelete(context.Context, *BatchDeleteRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDelete not implemented")
+}
+func (UnimplementedPositionServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[PositionEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
 func (UnimplementedPositionServiceServer) mustEmbedUnimplementedPositionServiceServer() {}
+func (UnimplementedPositionServiceServer) testEmbeddedByValue()                         {}
 
 // UnsafePositionServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to PositionServiceServer will
@@ -136,6 +242,13 @@ type UnsafePositionServiceServer interface {
 }
 
 func RegisterPositionServiceServer(s grpc.ServiceRegistrar, srv PositionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPositionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&PositionService_ServiceDesc, srv)
 }
 
@@ -229,6 +342,82 @@ func _PositionService_Delete_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PositionService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PositionServiceServer).Export(m, &grpc.GenericServerStream[ExportRequest, ExportChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PositionService_ExportServer = grpc.ServerStreamingServer[ExportChunk]
+
+func _PositionService_BatchCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPositionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PositionServiceServer).BatchCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PositionService_BatchCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PositionServiceServer).BatchCreate(ctx, req.(*BatchPositionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PositionService_BatchUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPositionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PositionServiceServer).BatchUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PositionService_BatchUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PositionServiceServer).BatchUpdate(ctx, req.(*BatchPositionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PositionService_BatchDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PositionServiceServer).BatchDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PositionService_BatchDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PositionServiceServer).BatchDelete(ctx, req.(*BatchDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PositionService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PositionServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, PositionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PositionService_WatchServer = grpc.ServerStreamingServer[PositionEvent]
+
 // PositionService_ServiceDesc is the grpc.ServiceDesc for PositionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -256,7 +445,30 @@ var PositionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _PositionService_Delete_Handler,
 		},
+		{
+			MethodName: "BatchCreate",
+			Handler:    _PositionService_BatchCreate_Handler,
+		},
+		{
+			MethodName: "BatchUpdate",
+			Handler:    _PositionService_BatchUpdate_Handler,
+		},
+		{
+			MethodName: "BatchDelete",
+			Handler:    _PositionService_BatchDelete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _PositionService_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _PositionService_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "position.proto",
 }